@@ -0,0 +1,171 @@
+package passwap
+
+import (
+	"crypto/md5"
+	"crypto/sha512"
+	"strings"
+
+	"github.com/zitadel/passwap/argon2"
+	"github.com/zitadel/passwap/bcrypt"
+	"github.com/zitadel/passwap/descrypt"
+	"github.com/zitadel/passwap/django"
+	"github.com/zitadel/passwap/dovecot"
+	"github.com/zitadel/passwap/iteratedhash"
+	md5crypt "github.com/zitadel/passwap/md5"
+	"github.com/zitadel/passwap/md5plain"
+	"github.com/zitadel/passwap/pbkdf2"
+	"github.com/zitadel/passwap/saltedhex"
+	"github.com/zitadel/passwap/scrypt"
+	"github.com/zitadel/passwap/sha1crypt"
+	"github.com/zitadel/passwap/tomcat"
+	"github.com/zitadel/passwap/verifier"
+	"github.com/zitadel/passwap/wordpress"
+)
+
+// algorithm describes an algorithm built into passwap.
+// Optional functions are nil when the algorithm does not
+// provide them.
+type algorithm struct {
+	name string
+	// prefixes of encoded hashes, nil for formats without a prefix.
+	prefixes []string
+	// verifier of the algorithm.
+	verifier verifier.Verifier
+	// migrate registers verifier with [NewMigrationSwapper].
+	migrate bool
+	// configured indicates the algorithm needs configuration
+	// and verifier is only configured for the selfTest vector.
+	// Such algorithms have no prefixes and are only used by [SelfTest].
+	configured bool
+
+	// identify is used by [SameParameters].
+	identify func(encoded string) (map[string]any, error)
+	// canonicalize is used by [Canonicalize]. For formats without
+	// a prefix, it must return an error for other formats.
+	canonicalize func(encoded string) (string, error)
+	// selfTest returns the golden vector of verifier, see [SelfTest].
+	selfTest func() (password, encoded string)
+}
+
+// algorithms is the registry from which [NewMigrationSwapper],
+// [SelfTest], [SameParameters] and [Canonicalize] are built.
+// Formats without a prefix come last, so they don't claim
+// hashes of other formats.
+var algorithms = []algorithm{
+	{
+		name:         argon2.Algorithm,
+		prefixes:     argon2.Prefixes(),
+		verifier:     argon2.Verifier,
+		migrate:      true,
+		identify:     argon2.Identify,
+		canonicalize: argon2.Canonicalize,
+		selfTest:     argon2.SelfTestVector,
+	},
+	{
+		name:     bcrypt.Algorithm,
+		prefixes: bcrypt.Prefixes(),
+		verifier: bcrypt.Verifier,
+		migrate:  true,
+		identify: bcrypt.Identify,
+		selfTest: bcrypt.SelfTestVector,
+	},
+	{
+		name:         scrypt.Algorithm,
+		prefixes:     scrypt.Prefixes(),
+		verifier:     scrypt.Verifier,
+		migrate:      true,
+		identify:     scrypt.Identify,
+		canonicalize: scrypt.Canonicalize,
+		selfTest:     scrypt.SelfTestVector,
+	},
+	{
+		name:         pbkdf2.Algorithm,
+		prefixes:     pbkdf2.Prefixes(),
+		verifier:     pbkdf2.Verifier,
+		migrate:      true,
+		identify:     pbkdf2.Identify,
+		canonicalize: pbkdf2.Canonicalize,
+		selfTest:     pbkdf2.SelfTestVector,
+	},
+	{
+		name:     sha1crypt.Algorithm,
+		prefixes: sha1crypt.Prefixes(),
+		verifier: sha1crypt.Verifier,
+		migrate:  true,
+		identify: sha1crypt.Identify,
+		selfTest: sha1crypt.SelfTestVector,
+	},
+	{
+		name:     md5crypt.Algorithm,
+		prefixes: md5crypt.Prefixes(),
+		verifier: md5crypt.Verifier,
+		migrate:  true,
+		identify: md5crypt.Identify,
+		selfTest: md5crypt.SelfTestVector,
+	},
+	{
+		name:     dovecot.Algorithm,
+		prefixes: dovecot.Prefixes(),
+		verifier: dovecot.Verifier,
+		migrate:  true,
+		selfTest: dovecot.SelfTestVector,
+	},
+	{
+		name:     django.Algorithm,
+		prefixes: django.Prefixes(),
+		verifier: django.Verifier,
+		migrate:  true,
+	},
+	{
+		name:     wordpress.Algorithm,
+		prefixes: wordpress.Prefixes(),
+		verifier: wordpress.Verifier,
+		selfTest: wordpress.SelfTestVector,
+	},
+	{
+		name:     descrypt.Algorithm,
+		verifier: descrypt.Verifier,
+		migrate:  true,
+		selfTest: descrypt.SelfTestVector,
+	},
+	{
+		name:     md5plain.Algorithm,
+		verifier: md5plain.Verifier,
+		migrate:  true,
+		canonicalize: func(encoded string) (string, error) {
+			return md5plain.Normalize(encoded, md5plain.LowerCase)
+		},
+		selfTest: md5plain.SelfTestVector,
+	},
+	{
+		name:       tomcat.Algorithm,
+		verifier:   tomcat.NewMessageDigest(sha512.New),
+		configured: true,
+		selfTest:   tomcat.SelfTestVector,
+	},
+	{
+		name:       saltedhex.Algorithm,
+		verifier:   saltedhex.New(saltedhex.Format{Hash: md5.New}),
+		configured: true,
+		selfTest:   saltedhex.SelfTestVector,
+	},
+	{
+		name:       iteratedhash.Algorithm,
+		verifier:   iteratedhash.New(iteratedhash.SHA256Rounds),
+		configured: true,
+		selfTest:   iteratedhash.SelfTestVector,
+	},
+}
+
+// algorithmFor returns the first algorithm with a prefix of encoded,
+// or nil when there is none.
+func algorithmFor(encoded string) *algorithm {
+	for i, a := range algorithms {
+		for _, p := range a.prefixes {
+			if strings.HasPrefix(encoded, p) {
+				return &algorithms[i]
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package passwap
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zitadel/passwap/verifier"
+)
+
+func Test_algorithms(t *testing.T) {
+	names := make(map[string]bool)
+	prefixed := true
+	for _, a := range algorithms {
+		t.Run(a.name, func(t *testing.T) {
+			if names[a.name] {
+				t.Errorf("algorithm registered twice")
+			}
+			names[a.name] = true
+
+			if named, ok := a.verifier.(verifier.Named); !ok || named.Algorithm() != a.name {
+				t.Errorf("verifier is not named %s", a.name)
+			}
+			if a.configured {
+				if a.prefixes != nil || a.migrate || a.identify != nil || a.canonicalize != nil {
+					t.Error("configured algorithm used outside of SelfTest")
+				}
+			} else if p, ok := a.verifier.(verifier.Prefixed); ok && !reflect.DeepEqual(p.HashPrefixes(), a.prefixes) {
+				t.Errorf("prefixes = %v, verifier has %v", a.prefixes, p.HashPrefixes())
+			}
+			if len(a.prefixes) > 0 && !prefixed {
+				t.Error("registered after a format without prefix")
+			}
+			prefixed = len(a.prefixes) > 0
+		})
+	}
+}
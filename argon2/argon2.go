@@ -3,15 +3,24 @@
 package argon2
 
 import (
+	"context"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/zitadel/passwap/internal/encoding"
 	"github.com/zitadel/passwap/internal/salt"
+	"github.com/zitadel/passwap/internal/semaphore"
 	"github.com/zitadel/passwap/verifier"
 	"golang.org/x/crypto/argon2"
 )
@@ -52,22 +61,156 @@ var (
 	}
 )
 
+// memoryHardness weights argon2 and scrypt work,
+// as their memory use makes each block more costly to attack
+// than a hash compression.
+const memoryHardness = 32
+
+// Strength implements verifier.Parameters.
+// The work is the number of 1 KiB blocks filled,
+// memory times time, weighted for memory hardness.
+func (p Params) Strength() int {
+	return verifier.WorkStrength(float64(p.Memory) * float64(p.Time) * memoryHardness)
+}
+
+// Encode salt and hash with the parameters, using the PHC string Format.
+// The identifier is taken from Params obtained through [ParseParams]
+// or a Hasher constructor. Otherwise argon2id is used.
+// KeyLen and SaltLen are ignored, as they are implied by
+// the length of hash and salt.
+func (p Params) Encode(salt, hash []byte) string {
+	return p.encode(salt, hash, extraParams{})
+}
+
+// identifier of the argon2 variant, defaults to argon2id.
+func (p Params) identifier() string {
+	if p.id == "" {
+		return Identifier_id
+	}
+	return p.id
+}
+
+// extraParams are optional parameters which
+// are not used as argon2 cost parameters.
+type extraParams struct {
+	// keyID identifies the secret used for derivation.
+	keyID string
+	// data is the associated data of the PHC string format.
+	// It is kept for round-tripping only, as upstream
+	// does not support deriving keys with associated data.
+	data []byte
+	// created is the creation time of the hash.
+	created time.Time
+}
+
+// encode salt and hash with the parameters.
+// Non-zero extra parameters are appended after
+// the cost parameters, as keyid, data and ts.
+func (p Params) encode(salt, hash []byte, x extraParams) string {
+	params := fmt.Sprintf("m=%d,t=%d,p=%d", p.Memory, p.Time, p.Threads)
+	if x.keyID != "" {
+		params += ",keyid=" + x.keyID
+	}
+	if len(x.data) > 0 {
+		params += ",data=" + base64.RawStdEncoding.EncodeToString(x.data)
+	}
+	if !x.created.IsZero() {
+		params += ",ts=" + strconv.FormatInt(x.created.Unix(), 10)
+	}
+
+	return fmt.Sprintf("$%s$v=%d$%s$%s$%s",
+		p.identifier(), argon2.Version, params,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash),
+	)
+}
+
+// ParseParams parses an encoded argon2 hash string
+// into its parameters, salt and hash.
+// The returned Params retain the identifier for [Params.Encode].
+func ParseParams(encoded string) (p Params, salt, hash []byte, err error) {
+	c, err := parse(encoded)
+	if err != nil {
+		return Params{}, nil, nil, err
+	}
+	if c == nil {
+		return Params{}, nil, nil, fmt.Errorf("argon2 parse: missing %s prefix", Prefix)
+	}
+	return c.Params, c.salt, c.hash, nil
+}
+
 // Format of the PHC string format for argon2.
 // See https://github.com/P-H-C/phc-string-format/blob/master/phc-sf-spec.md.
 const Format = "$%s$v=%d$m=%d,t=%d,p=%d$%s$%s"
 
-// scanning needs a space separated string, instead of dollar signs.
-var scanFormat = strings.ReplaceAll(Format, "$", " ")
+// versionDeprecated is the argon2 version 16 (0x10).
+const versionDeprecated = 0x10
 
 var (
 	ErrArgon2d       = errors.New("argon2d is not supported")
 	ErrArgon2Version = fmt.Errorf("argon2: version required %x", argon2.Version)
+	ErrArgon2Threads = errors.New("argon2: threads (p) must be between 1 and 255")
+
+	// ErrArgon2VersionUnsupported is returned for hashes of the
+	// deprecated version 16 (0x10). The upstream package only
+	// implements version 19 (0x13), so such hashes can't be verified
+	// and their passwords need to be reset.
+	ErrArgon2VersionUnsupported = errors.New("argon2: version 16 (0x10) is not supported, password reset required")
+
+	// ErrUnknownSecret is returned with a Fail result when
+	// an encoded hash has a keyid of a secret that is not
+	// known to the Hasher, or when verifying such hashes
+	// without a Hasher.
+	ErrUnknownSecret = errors.New("argon2: unknown secret keyid")
+
+	// ErrArgon2Data is returned with a Fail result when
+	// an encoded hash has a data parameter.
+	// Upstream does not support associated data,
+	// so such hashes can't be verified.
+	ErrArgon2Data = errors.New("argon2: associated data (data) is not supported")
 )
 
 type hashFunc func(password, salt []byte, time, memory uint32, threads uint8, keyLen uint32) []byte
 
+var memoryBudget atomic.Pointer[semaphore.Weighted]
+
+// SetMemoryBudget bounds the total memory in bytes used by
+// concurrent argon2 derivations in this package, for both hashing
+// and verification. Derivations that would exceed the budget block
+// until enough memory is released by other derivations.
+// Waiting derivations are run in order of arrival.
+// A single derivation that requires more memory than the budget
+// is only run when no other derivations are in progress.
+// A budget of 0 removes the bound, which is the default.
+// The wait can be cancelled with the context passed to
+// [Hasher.HashContext] or [Hasher.VerifyContext].
+//
+// This protects against excessive memory use under a high rate
+// of concurrent logins, at the cost of latency.
+func SetMemoryBudget(bytes uint64) {
+	if bytes == 0 {
+		memoryBudget.Store(nil)
+		return
+	}
+	memoryBudget.Store(semaphore.NewWeighted(bytes))
+}
+
+// derive calls hf, within the memory budget if one is set.
+// An error is returned when ctx is done while waiting for the budget.
+func (hf hashFunc) derive(ctx context.Context, password, salt []byte, time, memory uint32, threads uint8, keyLen uint32) ([]byte, error) {
+	if budget := memoryBudget.Load(); budget != nil {
+		n := uint64(memory) * 1024
+		if err := budget.Acquire(ctx, n); err != nil {
+			return nil, fmt.Errorf("argon2 memory budget: %w", err)
+		}
+		defer budget.Release(n)
+	}
+	return hf(password, salt, time, memory, threads, keyLen), nil
+}
+
 type checker struct {
 	Params
+	extraParams
 
 	hash []byte
 	salt []byte
@@ -75,25 +218,157 @@ type checker struct {
 	hf hashFunc
 }
 
+// parseMemory parses the memory parameter in KiB.
+// Human edited hashes may carry a K, M or G suffix,
+// which are normalized to KiB. Hash always encodes bare KiB.
+func parseMemory(m string) (uint32, error) {
+	value, unit := m, uint64(1)
+	if n := len(m) - 1; n > 0 {
+		switch m[n] {
+		case 'K':
+			value = m[:n]
+		case 'M':
+			value, unit = m[:n], 1024
+		case 'G':
+			value, unit = m[:n], 1024*1024
+		}
+	}
+
+	memory, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("argon2 parse memory: %w", err)
+	}
+	if memory > math.MaxUint32/unit {
+		return 0, fmt.Errorf("argon2 parse memory: %s exceeds %d KiB", m, uint32(math.MaxUint32))
+	}
+	return uint32(memory * unit), nil
+}
+
+// parseParams parses the comma separated key=value parameters
+// of an argon2 hash. Keys may appear in any order,
+// but each of m, t and p is required exactly once.
+// The optional keyid parameter identifies the secret
+// used for key derivation, see [WithSecret].
+// The optional data parameter holds base64 encoded
+// associated data, see [ErrArgon2Data].
+// The optional ts parameter holds the creation time
+// in unix seconds, and is not used for key derivation.
+func (c *checker) parseParams(params string) error {
+	var m, t, p, keyID, data, ts *string
+
+	for _, kv := range strings.Split(params, ",") {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			return fmt.Errorf("argon2 parse: malformed parameter %q", kv)
+		}
+
+		var dst **string
+		switch key {
+		case "m":
+			dst = &m
+		case "t":
+			dst = &t
+		case "p":
+			dst = &p
+		case "keyid":
+			dst = &keyID
+		case "data":
+			dst = &data
+		case "ts":
+			dst = &ts
+		default:
+			return fmt.Errorf("argon2 parse: unknown parameter %q", key)
+		}
+		if *dst != nil {
+			return fmt.Errorf("argon2 parse: duplicate parameter %q", key)
+		}
+		*dst = &value
+	}
+	if m == nil || t == nil || p == nil {
+		return fmt.Errorf("argon2 parse: m, t and p parameters required in %q", params)
+	}
+
+	var err error
+	c.Memory, err = parseMemory(*m)
+	if err != nil {
+		return err
+	}
+
+	passes, err := strconv.ParseUint(*t, 10, 32)
+	if err != nil {
+		return fmt.Errorf("argon2 parse time: %w", err)
+	}
+	if passes < 1 {
+		return errors.New("argon2 parse: time (t) must be at least 1")
+	}
+	c.Time = uint32(passes)
+
+	// Threads are the lanes of argon2, which x/crypto/argon2 takes as uint8.
+	threads, err := strconv.ParseUint(*p, 10, 64)
+	if err != nil || threads < 1 || threads > math.MaxUint8 {
+		return fmt.Errorf("%w: got %q", ErrArgon2Threads, *p)
+	}
+	c.Threads = uint8(threads)
+
+	if keyID != nil {
+		if *keyID == "" {
+			return errors.New("argon2 parse: empty keyid")
+		}
+		c.keyID = *keyID
+	}
+
+	if data != nil {
+		c.data, err = base64.RawStdEncoding.Strict().DecodeString(*data)
+		if err != nil {
+			return fmt.Errorf("argon2 parse data: %w", err)
+		}
+		if len(c.data) == 0 {
+			return errors.New("argon2 parse: empty data")
+		}
+	}
+
+	if ts != nil {
+		unix, err := strconv.ParseInt(*ts, 10, 64)
+		if err != nil {
+			return fmt.Errorf("argon2 parse timestamp: %w", err)
+		}
+		c.created = time.Unix(unix, 0)
+	}
+
+	return nil
+}
+
 func parse(encoded string) (*checker, error) {
 	if !strings.HasPrefix(encoded, Prefix) {
 		return nil, nil
 	}
 
-	var (
-		version int
-		salt    string
-		hash    string
-		c       checker
-	)
+	// $id$v=version$params$salt$hash
+	fields := strings.Split(encoded, "$")
+	if len(fields) == 5 && !strings.HasPrefix(fields[2], "v=") {
+		// Version 16 hashes were encoded without version.
+		return nil, ErrArgon2VersionUnsupported
+	}
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("argon2 parse: expected 6 fields, got %d", len(fields))
+	}
 
-	// scanning needs a space separated string, instead of dollar signs.
-	encoded = strings.ReplaceAll(encoded, "$", " ")
+	var c checker
+	c.id = fields[1]
 
-	_, err := fmt.Sscanf(encoded, scanFormat, &c.id, &version, &c.Memory, &c.Time, &c.Threads, &salt, &hash)
+	v, ok := strings.CutPrefix(fields[2], "v=")
+	if !ok {
+		return nil, fmt.Errorf("argon2 parse: missing version in %q", fields[2])
+	}
+	version, err := strconv.Atoi(v)
 	if err != nil {
-		return nil, fmt.Errorf("argon2 parse: %w", err)
+		return nil, fmt.Errorf("argon2 parse version: %w", err)
+	}
+
+	if err = c.parseParams(fields[3]); err != nil {
+		return nil, err
 	}
+	salt, hash := fields[4], fields[5]
 
 	switch c.id {
 	case Identifier_i:
@@ -106,6 +381,9 @@ func parse(encoded string) (*checker, error) {
 		return nil, fmt.Errorf("argon2: unknown identifier %s", c.id)
 	}
 
+	if version == versionDeprecated {
+		return nil, ErrArgon2VersionUnsupported
+	}
 	if version != argon2.Version {
 		return nil, fmt.Errorf("%w, %x received", ErrArgon2Version, version)
 	}
@@ -119,6 +397,9 @@ func parse(encoded string) (*checker, error) {
 	if err != nil {
 		return nil, fmt.Errorf("argon2 parse hash: %w", err)
 	}
+	if len(c.hash) == 0 {
+		return nil, errors.New("argon2 parse: empty hash")
+	}
 
 	c.KeyLen = uint32(len(c.hash))
 	c.SaltLen = uint32(len(c.salt))
@@ -126,72 +407,314 @@ func parse(encoded string) (*checker, error) {
 	return &c, nil
 }
 
-func (c *checker) verify(pw string) verifier.Result {
-	hash := c.hf([]byte(pw), c.salt, c.Time, c.Memory, c.Threads, c.KeyLen)
+// Describe implements verifier.Parameters.
+func (c *checker) Describe() map[string]any {
+	params := map[string]any{
+		"identifier": c.id,
+		"version":    argon2.Version,
+		"memory":     c.Memory,
+		"time":       c.Time,
+		"threads":    c.Threads,
+		"key_len":    c.KeyLen,
+		"salt_len":   c.SaltLen,
+	}
+	if c.keyID != "" {
+		params["keyid"] = c.keyID
+	}
+	if len(c.data) > 0 {
+		params["data"] = base64.RawStdEncoding.EncodeToString(c.data)
+	}
+	if !c.created.IsZero() {
+		params["created"] = c.created
+	}
+	return params
+}
+
+func (c *checker) verify(ctx context.Context, pw []byte) (verifier.Result, error) {
+	hash, err := c.hf.derive(ctx, pw, c.salt, c.Time, c.Memory, c.Threads, c.KeyLen)
+	if err != nil {
+		return verifier.Fail, err
+	}
 	res := subtle.ConstantTimeCompare(hash, c.hash)
 
-	return verifier.Result(res)
+	return verifier.Result(res), nil
 }
 
 type Hasher struct {
-	p    Params
+	p Params
+	// rand is shared by concurrent calls to Hash and must be
+	// safe for concurrent use, such as crypto/rand.Reader.
 	rand io.Reader
 	hf   hashFunc
+	now  func() time.Time
+
+	// secrets by keyid and the keyid
+	// of the secret used for hashing.
+	secrets map[string][]byte
+	keyID   string
+
+	vopts ValidationOpts
+
+	observeDowngrade func(Downgrade)
+}
+
+// Option configures optional behavior of a Hasher.
+type Option func(*Hasher)
+
+// WithInsecureRand sets the source of salts to r, instead of
+// crypto/rand.Reader, so that the Hasher produces reproducible
+// hashes for known-answer test vectors, see package vectors.
+// Hashes with predictable salts must never be stored,
+// hence the name. This is the only way to replace the
+// source of salts, Hashers otherwise always use crypto/rand.Reader.
+// r is shared by concurrent calls to Hash.
+func WithInsecureRand(r io.Reader) Option {
+	return func(h *Hasher) {
+		h.rand = r
+	}
+}
+
+// Downgrade is reported to the observer set [WithDowngradeObserver],
+// when a password is verified against a hash which uses more memory
+// than the Hasher. Updating such a hash weakens it.
+type Downgrade struct {
+	// Stored are the parameters of the verified hash.
+	Stored Params
+	// Configured are the parameters of the Hasher.
+	Configured Params
+}
+
+// WithDowngradeObserver sets a function which is called by
+// [Hasher.Verify] for every Downgrade, so that operators are
+// notified when memory was reduced by accident, for example
+// after moving to smaller machines.
+// Hashes are still verified with NeedUpdate and rehashed
+// with the parameters of the Hasher.
+//
+// observe is called synchronously and must be safe
+// for concurrent use.
+func WithDowngradeObserver(observe func(Downgrade)) Option {
+	return func(h *Hasher) {
+		h.observeDowngrade = observe
+	}
+}
+
+// checkDowngrade reports a Downgrade when stored
+// uses more memory than the Hasher.
+func (h *Hasher) checkDowngrade(stored Params) {
+	if h.observeDowngrade != nil && stored.Memory > h.p.Memory {
+		h.observeDowngrade(Downgrade{
+			Stored:     stored,
+			Configured: h.p,
+		})
+	}
+}
+
+// WithTimestamp appends the creation time of each hash
+// as a `ts=<unix seconds>` parameter, for example
+// `$argon2id$v=19$m=65536,t=1,p=4,ts=1700000000$...`.
+// The timestamp is not used for key derivation
+// and the Verifier accepts hashes with and without it.
+// Other argon2 implementations may not accept the parameter.
+func WithTimestamp() Option {
+	return func(h *Hasher) {
+		h.now = time.Now
+	}
+}
+
+// WithSecret keys password hashing with a secret,
+// sometimes called a pepper, which is kept outside
+// of the database holding the hashes.
+// The password is keyed as HMAC-SHA256(secret, password)
+// before derivation, so that hashes are interoperable
+// with other argon2 implementations given the same input.
+//
+// Each hash is tagged with a `keyid=<id>` parameter,
+// for example `$argon2id$v=19$m=65536,t=1,p=4,keyid=2024-01$...`.
+// The id is chosen by the caller and stored as is, so that the
+// database reveals nothing about the secret. It must not be empty and
+// may only contain letters, digits and the characters `-`, `_` and `.`;
+// WithSecret panics otherwise.
+//
+// The option may be passed multiple times to rotate secrets:
+// the last secret is used for hashing, while all secrets
+// are used for verification. Hashes with a keyid of an
+// older secret are verified with NeedUpdate.
+//
+// Hashes with an unknown keyid fail with [ErrUnknownSecret].
+func WithSecret(id string, secret []byte) Option {
+	if !validKeyID(id) {
+		panic(fmt.Sprintf("argon2: invalid secret keyid %q", id))
+	}
+	secret = append([]byte(nil), secret...)
+
+	return func(h *Hasher) {
+		if h.secrets == nil {
+			h.secrets = make(map[string][]byte)
+		}
+		h.secrets[id] = secret
+		h.keyID = id
+	}
+}
+
+// validKeyID reports whether id can be encoded
+// as keyid parameter, see [WithSecret].
+func validKeyID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9',
+			r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// keyPassword returns HMAC-SHA256(secret, password).
+func keyPassword(secret []byte, password string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(password))
+	return mac.Sum(nil)
 }
 
 // Hash implements passwap.Hasher.
 func (h *Hasher) Hash(password string) (string, error) {
-	salt, err := salt.New(h.rand, h.p.SaltLen)
+	return h.HashContext(context.Background(), password)
+}
+
+// HashContext operates like [Hasher.Hash]. When a memory budget
+// is set, see [SetMemoryBudget], an error wrapping ctx.Err() is
+// returned when ctx is done before the derivation could start.
+func (h *Hasher) HashContext(ctx context.Context, password string) (string, error) {
+	pw := []byte(password)
+	if h.keyID != "" {
+		pw = keyPassword(h.secrets[h.keyID], password)
+	}
+	salt, hash, err := h.derive(ctx, pw)
 	if err != nil {
-		return "", fmt.Errorf("argon2: %w", err)
+		return "", err
 	}
 
-	hash := h.hf([]byte(password), salt, h.p.Time, h.p.Memory, h.p.Threads, h.p.KeyLen)
+	x := extraParams{keyID: h.keyID}
+	if h.now != nil {
+		x.created = h.now()
+	}
+	return h.p.encode(salt, hash, x), nil
+}
 
-	return fmt.Sprintf(Format,
-		h.p.id, argon2.Version, h.p.Memory, h.p.Time, h.p.Threads,
-		base64.RawStdEncoding.EncodeToString(salt),
-		base64.RawStdEncoding.EncodeToString(hash),
-	), nil
+// derive a hash of pw with a new salt,
+// returning salt and hash.
+func (h *Hasher) derive(ctx context.Context, pw []byte) ([]byte, []byte, error) {
+	s, err := salt.New(h.rand, h.p.SaltLen)
+	if err != nil {
+		return nil, nil, fmt.Errorf("argon2: %w", err)
+	}
+	hash, err := h.hf.derive(ctx, pw, s, h.p.Time, h.p.Memory, h.p.Threads, h.p.KeyLen)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, hash, nil
 }
 
-// Verify implements passwap.Verifier
+// Verify implements passwap.Verifier.
+// Fail and a BoundsError are returned without derivation when
+// the key length of encoded is below the MinKeyLen set
+// [WithValidation], as short keys are easy to collide.
+// Verified hashes with more memory than the Hasher are reported
+// to the observer set [WithDowngradeObserver].
 func (h *Hasher) Verify(encoded, password string) (verifier.Result, error) {
+	return h.VerifyContext(context.Background(), encoded, password)
+}
+
+// VerifyContext operates like [Hasher.Verify]. When a memory budget
+// is set, see [SetMemoryBudget], Fail and an error wrapping ctx.Err()
+// are returned when ctx is done before the derivation could start.
+func (h *Hasher) VerifyContext(ctx context.Context, encoded, password string) (verifier.Result, error) {
 	c, err := parse(encoded)
 	if err != nil || c == nil {
 		return verifier.Skip, err
 	}
 
-	res := c.verify(password)
-	if res == 0 {
-		return verifier.Fail, nil
+	if len(c.data) > 0 {
+		return verifier.Fail, ErrArgon2Data
+	}
+	if err = h.vopts.checkKeyLen(c.Params); err != nil {
+		return verifier.Fail, err
+	}
+
+	pw := []byte(password)
+	if c.keyID != "" {
+		secret, ok := h.secrets[c.keyID]
+		if !ok {
+			return verifier.Fail, fmt.Errorf("%w %q", ErrUnknownSecret, c.keyID)
+		}
+		pw = keyPassword(secret, password)
+	}
+
+	res, err := c.verify(ctx, pw)
+	if err != nil || res == verifier.Fail {
+		return verifier.Fail, err
 	}
 
-	if h.p != c.Params {
+	if h.p != c.Params || h.keyID != c.keyID {
+		h.checkDowngrade(c.Params)
 		return verifier.NeedUpdate, nil
 	}
 
 	return verifier.OK, nil
 }
 
-func NewArgon2i(p Params) *Hasher {
-	p.id = Identifier_i
+// Identify implements verifier.Identifier.
+func (h *Hasher) Identify(encoded string) (map[string]any, error) {
+	return Identify(encoded)
+}
 
-	return &Hasher{
-		p:    p,
-		rand: rand.Reader,
-		hf:   argon2.Key,
+// HashParameters implements verifier.Configured.
+func (h *Hasher) HashParameters() verifier.Parameters {
+	return &checker{
+		Params:      h.p,
+		extraParams: extraParams{keyID: h.keyID},
 	}
 }
 
-func NewArgon2id(p Params) *Hasher {
-	p.id = Identifier_id
+// Parse implements verifier.Parser.
+func (h *Hasher) Parse(encoded string) (verifier.Parameters, error) {
+	return Parse(encoded)
+}
+
+// Algorithm implements verifier.Named.
+func (h *Hasher) Algorithm() string {
+	return Algorithm
+}
+
+// HashPrefixes implements verifier.Prefixed.
+func (h *Hasher) HashPrefixes() []string {
+	return prefixes
+}
 
-	return &Hasher{
+func newHasher(p Params, id string, hf hashFunc, opts []Option) *Hasher {
+	p.id = id
+	h := &Hasher{
 		p:    p,
 		rand: rand.Reader,
-		hf:   argon2.IDKey,
+		hf:   hf,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func NewArgon2i(p Params, opts ...Option) *Hasher {
+	return newHasher(p, Identifier_i, argon2.Key, opts)
+}
+
+func NewArgon2id(p Params, opts ...Option) *Hasher {
+	return newHasher(p, Identifier_id, argon2.IDKey, opts)
 }
 
 // Verify parses encoded and uses its argon2 parameters
@@ -203,13 +726,83 @@ func NewArgon2id(p Params) *Hasher {
 // and therefore not by this package.
 // ErrArgon2d is returned when an argon2d identifier is in
 // the encoded string.
+// ErrArgon2VersionUnsupported is returned for version 16 hashes,
+// which can't be verified.
+// Hashes keyed with a secret can only be verified by
+// a Hasher with that secret, see [WithSecret].
+// For such hashes Fail and [ErrUnknownSecret] are returned.
+// Hashes with associated data fail with [ErrArgon2Data].
 func Verify(encoded, password string) (verifier.Result, error) {
 	c, err := parse(encoded)
 	if err != nil || c == nil {
 		return verifier.Skip, err
 	}
+	if len(c.data) > 0 {
+		return verifier.Fail, ErrArgon2Data
+	}
+	if c.keyID != "" {
+		return verifier.Fail, fmt.Errorf("%w %q", ErrUnknownSecret, c.keyID)
+	}
 
-	return c.verify(password), nil
+	return c.verify(context.Background(), []byte(password))
 }
 
-var Verifier = verifier.VerifyFunc(Verify)
+// Identify parses encoded and returns its identifier
+// and argon2 parameters.
+func Identify(encoded string) (map[string]any, error) {
+	p, err := Parse(encoded)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	return p.Describe(), nil
+}
+
+// Parse parses encoded and returns its argon2 parameters.
+// Nil Parameters and a nil error are returned when
+// encoded is not a argon2 hash.
+func Parse(encoded string) (verifier.Parameters, error) {
+	c, err := parse(encoded)
+	if err != nil || c == nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Canonicalize parses encoded and encodes it again in the form
+// produced by the Hasher: parameters in m, t, p order,
+// followed by the optional keyid and ts parameters, and salt and hash
+// in base64 without padding. Padded salt and hash are accepted.
+// Hashes without version field use the deprecated version 16
+// and can't be canonicalized, see [ErrArgon2VersionUnsupported].
+func Canonicalize(encoded string) (string, error) {
+	c, err := parse(encoding.TrimPadding(encoded, 2))
+	if err != nil {
+		return "", err
+	}
+	if c == nil {
+		return "", fmt.Errorf("argon2 parse: missing %s prefix", Prefix)
+	}
+	return c.encode(c.salt, c.hash, c.extraParams), nil
+}
+
+// Algorithm is the name reported by the Verifier and Hasher,
+// see verifier.Named.
+const Algorithm = "argon2"
+
+// prefixes recognized by the Verifier and Hasher.
+var prefixes = []string{Prefix}
+
+var Verifier = &verifier.NamedFunc{Name: Algorithm, VerifyFunc: Verify, Prefixes: prefixes}
+
+// Prefixes returns the prefixes of the hashes handled by this package,
+// as recognized by the Verifier, see verifier.Prefixed.
+func Prefixes() []string {
+	return append([]string(nil), prefixes...)
+}
+
+// SelfTestVector returns a fixed password and its encoded hash,
+// which [Verify] must accept. It guards against regressions
+// of the encoding format, see passwap.SelfTest.
+func SelfTestVector() (password, encoded string) {
+	return "password", `$argon2id$v=19$m=4096,t=3,p=1$cmFuZG9tc2FsdGlzaGFyZA$DYojYpnUWSMmTtrkVXyaNWVGxLmGe1n8VJBPDdFkbjU`
+}
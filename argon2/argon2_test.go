@@ -2,11 +2,17 @@ package argon2
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/zitadel/passwap/internal/salt"
 	tv "github.com/zitadel/passwap/internal/testvalues"
@@ -66,6 +72,100 @@ func Test_parse(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"reordered params",
+			strings.Replace(tv.Argon2idEncoded, "m=4096,t=3,p=1", "t=3,m=4096,p=1", 1),
+			&checker{
+				Params: Params{
+					Time:    3,
+					Memory:  4096,
+					Threads: 1,
+					KeyLen:  32,
+					SaltLen: 16,
+					id:      Identifier_id,
+				},
+				hash: tv.Argon2idHash,
+				salt: []byte(tv.Salt),
+			},
+			false,
+		},
+		{
+			"reversed params",
+			strings.Replace(tv.Argon2idEncoded, "m=4096,t=3,p=1", "p=1,t=3,m=4096", 1),
+			&checker{
+				Params: Params{
+					Time:    3,
+					Memory:  4096,
+					Threads: 1,
+					KeyLen:  32,
+					SaltLen: 16,
+					id:      Identifier_id,
+				},
+				hash: tv.Argon2idHash,
+				salt: []byte(tv.Salt),
+			},
+			false,
+		},
+		{
+			"duplicate param",
+			strings.Replace(tv.Argon2idEncoded, "m=4096,t=3,p=1", "m=4096,t=3,p=1,t=4", 1),
+			nil,
+			true,
+		},
+		{
+			"missing param",
+			strings.Replace(tv.Argon2idEncoded, "m=4096,t=3,p=1", "m=4096,p=1", 1),
+			nil,
+			true,
+		},
+		{
+			"zero time",
+			strings.Replace(tv.Argon2idEncoded, "m=4096,t=3,p=1", "m=4096,t=0,p=1", 1),
+			nil,
+			true,
+		},
+		{
+			"empty hash",
+			"$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$",
+			nil,
+			true,
+		},
+		{
+			"unknown param",
+			strings.Replace(tv.Argon2idEncoded, "m=4096,t=3,p=1", "m=4096,t=3,p=1,x=1", 1),
+			nil,
+			true,
+		},
+		{
+			"malformed param",
+			strings.Replace(tv.Argon2idEncoded, "m=4096,t=3,p=1", "m4096,t=3,p=1", 1),
+			nil,
+			true,
+		},
+		{
+			"timestamp error",
+			strings.Replace(tv.Argon2idEncoded, "p=1", "p=1,ts=foo", 1),
+			nil,
+			true,
+		},
+		{
+			"memory error",
+			strings.Replace(tv.Argon2idEncoded, "m=4096", "m=foo", 1),
+			nil,
+			true,
+		},
+		{
+			"time error",
+			strings.Replace(tv.Argon2idEncoded, "t=3", "t=foo", 1),
+			nil,
+			true,
+		},
+		{
+			"missing version",
+			strings.Replace(tv.Argon2idEncoded, "v=19", "19", 1),
+			nil,
+			true,
+		},
 		{
 			"skip",
 			"foobar",
@@ -90,9 +190,27 @@ func Test_parse(t *testing.T) {
 			nil,
 			true,
 		},
+		{
+			"threads zero",
+			strings.Replace(tv.Argon2iEncoded, "p=1", "p=0", 1),
+			nil,
+			true,
+		},
+		{
+			"threads 256",
+			strings.Replace(tv.Argon2iEncoded, "p=1", "p=256", 1),
+			nil,
+			true,
+		},
+		{
+			"threads 300",
+			strings.Replace(tv.Argon2iEncoded, "p=1", "p=300", 1),
+			nil,
+			true,
+		},
 		{
 			"version error",
-			`$argon2i$v=16$m=4096,t=3,p=1$c2FsdHNhbHQ$MA1lJTML3jy8LJyr9lIP/68/omuHWSRxKjeWC0d0a5k`,
+			`$argon2i$v=17$m=4096,t=3,p=1$c2FsdHNhbHQ$MA1lJTML3jy8LJyr9lIP/68/omuHWSRxKjeWC0d0a5k`,
 			nil,
 			true,
 		},
@@ -138,6 +256,38 @@ func Test_parse(t *testing.T) {
 	}
 }
 
+func Test_parseMemory(t *testing.T) {
+	tests := []struct {
+		m       string
+		want    uint32
+		wantErr bool
+	}{
+		{"65536", 65536, false},
+		{"64K", 64, false},
+		{"64M", 65536, false},
+		{"1G", 1048576, false},
+		{"4095G", 4095 * 1024 * 1024, false},
+		{"4096G", 0, true},
+		{"4294967296", 0, true},
+		{"M", 0, true},
+		{"", 0, true},
+		{"64T", 0, true},
+		{"-1M", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.m, func(t *testing.T) {
+			got, err := parseMemory(tt.m)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseMemory() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseMemory() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_checker_verify(t *testing.T) {
 	c := checker{
 		Params: testParams,
@@ -161,7 +311,11 @@ func Test_checker_verify(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.want.String(), func(t *testing.T) {
-			if got := c.verify(tt.pw); !reflect.DeepEqual(got, tt.want) {
+			got, err := c.verify(context.Background(), []byte(tt.pw))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
 				t.Errorf("checker.verify() = %v, want %v", got, tt.want)
 			}
 		})
@@ -208,6 +362,25 @@ func TestHasher_Hash(t *testing.T) {
 	}
 }
 
+func TestHasher_Hash_entropy(t *testing.T) {
+	readers := map[string]func() io.Reader{
+		"error":      func() io.Reader { return salt.ErrReader{} },
+		"short read": func() io.Reader { return strings.NewReader("short") },
+	}
+	for name, r := range readers {
+		t.Run(name, func(t *testing.T) {
+			h := Hasher{p: testParams, rand: r(), hf: argon2.IDKey}
+			got, err := h.Hash(tv.Password)
+			if !errors.Is(err, salt.ErrEntropy) {
+				t.Errorf("Hasher.Hash() error = %v, want %v", err, salt.ErrEntropy)
+			}
+			if got != "" {
+				t.Errorf("Hasher.Hash() = %s, want empty", got)
+			}
+		})
+	}
+}
+
 func TestHasher_Verify(t *testing.T) {
 	type args struct {
 		encoded  string
@@ -298,7 +471,7 @@ func TestHasher_Verify(t *testing.T) {
 }
 
 func TestHasher(t *testing.T) {
-	tests := [...]func(Params) *Hasher{
+	tests := [...]func(Params, ...Option) *Hasher{
 		NewArgon2i, NewArgon2id,
 	}
 
@@ -338,6 +511,18 @@ func TestVerify(t *testing.T) {
 			verifier.Skip,
 			true,
 		},
+		{
+			"empty hash",
+			args{"$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$", tv.Password},
+			verifier.Skip,
+			true,
+		},
+		{
+			"zero time",
+			args{"$argon2id$v=19$m=1024,t=0,p=1$c2FsdHNhbHQ$YMvo8AUoNtnKYGqeODruCjHdiEbl1pKL2MsYy9VgU/E", tv.Password},
+			verifier.Skip,
+			true,
+		},
 		{
 			"success",
 			args{tv.Argon2idEncoded, tv.Password},
@@ -350,6 +535,12 @@ func TestVerify(t *testing.T) {
 			verifier.Fail,
 			false,
 		},
+		{
+			"memory suffix",
+			args{strings.Replace(tv.Argon2idEncoded, "m=4096", "m=4M", 1), tv.Password},
+			verifier.OK,
+			false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -364,3 +555,508 @@ func TestVerify(t *testing.T) {
 		})
 	}
 }
+
+func TestWithTimestamp(t *testing.T) {
+	created := time.Unix(1700000000, 0)
+	withTS := NewArgon2id(testParams, WithTimestamp())
+	withTS.now = func() time.Time { return created }
+	withoutTS := NewArgon2id(testParams)
+
+	encodedTS, err := withTS.Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(encodedTS, ",ts=1700000000$") {
+		t.Errorf("Hasher.Hash() = %s, want ts parameter", encodedTS)
+	}
+	encoded, err := withoutTS.Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(encoded, "ts=") {
+		t.Errorf("Hasher.Hash() = %s, want no ts parameter", encoded)
+	}
+
+	for _, h := range []*Hasher{withTS, withoutTS} {
+		for _, e := range []string{encodedTS, encoded} {
+			res, err := h.Verify(e, tv.Password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res != verifier.OK {
+				t.Errorf("Hasher.Verify(%s) = %s, want %s", e, res, verifier.OK)
+			}
+		}
+	}
+
+	params, err := Identify(encodedTS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := params["created"]; got != created {
+		t.Errorf("Identify() created = %v, want %v", got, created)
+	}
+	params, err = Identify(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := params["created"]; ok {
+		t.Errorf("Identify() created = %v, want none", got)
+	}
+}
+
+func TestWithSecret(t *testing.T) {
+	oldSecret, newSecret := []byte("old secret"), []byte("new secret")
+	old := NewArgon2id(testParams, WithSecret("old", oldSecret))
+	rotated := NewArgon2id(testParams, WithSecret("old", oldSecret), WithSecret("new", newSecret))
+	plain := NewArgon2id(testParams)
+
+	encodedOld, err := old.Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := ",keyid=old$"; !strings.Contains(encodedOld, want) {
+		t.Errorf("Hasher.Hash() = %s, want %s", encodedOld, want)
+	}
+	encodedNew, err := rotated.Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := ",keyid=new$"; !strings.Contains(encodedNew, want) {
+		t.Errorf("Hasher.Hash() = %s, want %s", encodedNew, want)
+	}
+
+	tests := []struct {
+		name     string
+		h        *Hasher
+		encoded  string
+		password string
+		want     verifier.Result
+		wantErr  error
+	}{
+		{"same secret", old, encodedOld, tv.Password, verifier.OK, nil},
+		{"wrong password", old, encodedOld, "spanac", verifier.Fail, nil},
+		{"rotated old", rotated, encodedOld, tv.Password, verifier.NeedUpdate, nil},
+		{"rotated new", rotated, encodedNew, tv.Password, verifier.OK, nil},
+		{"unknown secret", old, encodedNew, tv.Password, verifier.Fail, ErrUnknownSecret},
+		{"no secret", plain, encodedOld, tv.Password, verifier.Fail, ErrUnknownSecret},
+		{"update to secret", old, tv.Argon2idEncoded, tv.Password, verifier.NeedUpdate, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.h.Verify(tt.encoded, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Hasher.Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Hasher.Verify() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	res, err := Verify(encodedOld, tv.Password)
+	if !errors.Is(err, ErrUnknownSecret) || res != verifier.Fail {
+		t.Errorf("Verify() = %s, %v, want %s, %v", res, err, verifier.Fail, ErrUnknownSecret)
+	}
+
+	params, err := Identify(encodedOld)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := params["keyid"]; got != "old" {
+		t.Errorf("Identify() keyid = %v, want %v", got, "old")
+	}
+	if strings.Contains(encodedOld, string(oldSecret)) {
+		t.Errorf("Hasher.Hash() = %s contains the secret", encodedOld)
+	}
+}
+
+func TestWithSecret_invalidKeyID(t *testing.T) {
+	for _, id := range []string{"", "a,b", "a$b", "a=b", "ä"} {
+		t.Run(id, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("WithSecret(%q) did not panic", id)
+				}
+			}()
+			WithSecret(id, []byte("secret"))
+		})
+	}
+}
+
+func TestWithDowngradeObserver(t *testing.T) {
+	high := testParams
+	high.Memory = 2 * tv.Argon2Memory
+	encodedHigh, err := NewArgon2id(high).Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []Downgrade
+	h := NewArgon2id(testParams, WithDowngradeObserver(func(d Downgrade) {
+		got = append(got, d)
+	}))
+
+	tests := []struct {
+		name     string
+		encoded  string
+		password string
+		want     verifier.Result
+		wantN    int
+	}{
+		{"downgrade", encodedHigh, tv.Password, verifier.NeedUpdate, 1},
+		{"wrong password", encodedHigh, "spanac", verifier.Fail, 0},
+		{"same params", tv.Argon2idEncoded, tv.Password, verifier.OK, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			res, err := h.Verify(tt.encoded, tt.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res != tt.want {
+				t.Errorf("Hasher.Verify() = %s, want %s", res, tt.want)
+			}
+			if len(got) != tt.wantN {
+				t.Fatalf("Downgrade reported %d times, want %d", len(got), tt.wantN)
+			}
+			if tt.wantN > 0 && (got[0].Stored.Memory != high.Memory || got[0].Configured != h.p) {
+				t.Errorf("Downgrade = %+v", got[0])
+			}
+		})
+	}
+
+	low := testParams
+	low.Memory = tv.Argon2Memory / 2
+	encodedLow, err := NewArgon2id(low).Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	if res, _ := h.Verify(encodedLow, tv.Password); res != verifier.NeedUpdate || len(got) != 0 {
+		t.Errorf("Hasher.Verify() = %s with %d downgrades, want %s without", res, len(got), verifier.NeedUpdate)
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		want    map[string]any
+		wantErr bool
+	}{
+		{
+			name:    "skip",
+			encoded: "foobar",
+		},
+		{
+			name:    "parse error",
+			encoded: "$argon2!!!",
+			wantErr: true,
+		},
+		{
+			name:    "success",
+			encoded: tv.Argon2idEncoded,
+			want: map[string]any{
+				"identifier": Identifier_id,
+				"version":    argon2.Version,
+				"memory":     uint32(tv.Argon2Memory),
+				"time":       uint32(tv.Argon2Time),
+				"threads":    uint8(tv.Argon2Threads),
+				"key_len":    uint32(tv.KeyLen),
+				"salt_len":   tv.SaltLen,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewArgon2id(testParams).Identify(tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Identify() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Identify() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetMemoryBudget(t *testing.T) {
+	const (
+		maxConcurrent = 2
+		calls         = 10
+	)
+	SetMemoryBudget(maxConcurrent * tv.Argon2Memory * 1024)
+	defer SetMemoryBudget(0)
+
+	var current, max atomic.Int32
+	h := &Hasher{
+		p:    testParams,
+		rand: rand.Reader,
+		hf: func(password, salt []byte, t, m uint32, p uint8, keyLen uint32) []byte {
+			c := current.Add(1)
+			for old := max.Load(); c > old && !max.CompareAndSwap(old, c); old = max.Load() {
+			}
+			time.Sleep(5 * time.Millisecond)
+			current.Add(-1)
+			return argon2.IDKey(password, salt, t, m, p, keyLen)
+		},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := h.Hash(tv.Password); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := max.Load(); got < 1 || got > maxConcurrent {
+		t.Errorf("concurrent derivations = %d, want between 1 and %d", got, maxConcurrent)
+	}
+}
+
+func TestSetMemoryBudget_context(t *testing.T) {
+	SetMemoryBudget(tv.Argon2Memory * 1024)
+	defer SetMemoryBudget(0)
+
+	// Exhaust the budget, so that derivations have to wait.
+	budget := memoryBudget.Load()
+	if err := budget.Acquire(context.Background(), tv.Argon2Memory*1024); err != nil {
+		t.Fatal(err)
+	}
+	defer budget.Release(tv.Argon2Memory * 1024)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	h := NewArgon2i(testParams)
+	if _, err := h.HashContext(ctx, tv.Password); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Hasher.HashContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	res, err := h.VerifyContext(ctx, tv.Argon2iEncoded, tv.Password)
+	if res != verifier.Fail || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Hasher.VerifyContext() = %s, %v, want %s, %v", res, err, verifier.Fail, context.DeadlineExceeded)
+	}
+}
+
+func TestParseParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		wantErr bool
+	}{
+		{
+			name:    "missing prefix",
+			encoded: "foobar",
+			wantErr: true,
+		},
+		{
+			name:    "parse error",
+			encoded: "$argon2!!!",
+			wantErr: true,
+		},
+		{
+			name:    "argon2i",
+			encoded: tv.Argon2iEncoded,
+		},
+		{
+			name:    "argon2id",
+			encoded: tv.Argon2idEncoded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, salt, hash, err := ParseParams(tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseParams() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := p.Encode(salt, hash); got != tt.encoded {
+				t.Errorf("Params.Encode() =\n%s\nwant\n%s", got, tt.encoded)
+			}
+		})
+	}
+}
+
+func TestParams_Encode(t *testing.T) {
+	p := testParams
+	p.id = ""
+	if got := p.Encode([]byte(tv.Salt), tv.Argon2idHash); got != tv.Argon2idEncoded {
+		t.Errorf("Params.Encode() =\n%s\nwant\n%s", got, tv.Argon2idEncoded)
+	}
+}
+
+func TestErrArgon2Threads(t *testing.T) {
+	for _, p := range []string{"p=0", "p=256", "p=300"} {
+		t.Run(p, func(t *testing.T) {
+			_, err := parse(strings.Replace(tv.Argon2iEncoded, "p=1", p, 1))
+			if !errors.Is(err, ErrArgon2Threads) {
+				t.Errorf("parse() error = %v, want %v", err, ErrArgon2Threads)
+			}
+		})
+	}
+}
+
+func TestData(t *testing.T) {
+	tests := []struct {
+		name   string
+		params string
+	}{
+		{"none", "m=4096,t=3,p=1"},
+		{"keyid", "m=4096,t=3,p=1,keyid=a2V5aWQ"},
+		{"data", "m=4096,t=3,p=1,data=ZGF0YQ"},
+		{"keyid and data", "m=4096,t=3,p=1,keyid=a2V5aWQ,data=ZGF0YQ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := strings.Replace(tv.Argon2idEncoded, "m=4096,t=3,p=1", tt.params, 1)
+			got, err := Canonicalize(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != encoded {
+				t.Errorf("Canonicalize() =\n%s\nwant\n%s", got, encoded)
+			}
+
+			params, err := Identify(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantData := strings.Contains(tt.params, "data=")
+			if _, ok := params["data"]; ok != wantData {
+				t.Errorf("Identify() data = %v, want %t", params["data"], wantData)
+			}
+
+			var wantErr error
+			switch {
+			case wantData:
+				wantErr = ErrArgon2Data
+			case strings.Contains(tt.params, "keyid="):
+				wantErr = ErrUnknownSecret
+			}
+			res, err := NewArgon2id(testParams).Verify(encoded, tv.Password)
+			if !errors.Is(err, wantErr) {
+				t.Errorf("Hasher.Verify() error = %v, want %v", err, wantErr)
+			}
+			if wantErr != nil && res != verifier.Fail {
+				t.Errorf("Hasher.Verify() = %s, want %s", res, verifier.Fail)
+			}
+			if _, err = Verify(encoded, tv.Password); !errors.Is(err, wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, wantErr)
+			}
+		})
+	}
+
+	for _, data := range []string{"data=", "data=!!", "data=ZGF0YQ,data=ZGF0YQ"} {
+		t.Run(data, func(t *testing.T) {
+			encoded := strings.Replace(tv.Argon2idEncoded, "p=1", "p=1,"+data, 1)
+			if _, err := parse(encoded); err == nil {
+				t.Errorf("parse(%q) error = nil", encoded)
+			}
+		})
+	}
+}
+
+func TestErrArgon2VersionUnsupported(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		wantErr error
+	}{
+		{
+			name:    "v=16",
+			encoded: strings.Replace(tv.Argon2iEncoded, "v=19", "v=16", 1),
+			wantErr: ErrArgon2VersionUnsupported,
+		},
+		{
+			name:    "without version",
+			encoded: strings.Replace(tv.Argon2iEncoded, "v=19$", "", 1),
+			wantErr: ErrArgon2VersionUnsupported,
+		},
+		{
+			name:    "v=17",
+			encoded: strings.Replace(tv.Argon2iEncoded, "v=19", "v=17", 1),
+			wantErr: ErrArgon2Version,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Verify(tt.encoded, tv.Password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if res != verifier.Skip {
+				t.Errorf("Verify() = %s, want %s", res, verifier.Skip)
+			}
+		})
+	}
+	if _, err := parse(strings.Replace(tv.Argon2iEncoded, "v=19", "v=17", 1)); errors.Is(err, ErrArgon2VersionUnsupported) {
+		t.Errorf("parse() error = %v, want not %v", err, ErrArgon2VersionUnsupported)
+	}
+}
+
+func TestWithInsecureRand(t *testing.T) {
+	newTestHasher := func(opts ...Option) *Hasher { return NewArgon2id(testParams, opts...) }
+
+	if err := salt.AssertCryptoRand(newTestHasher().rand); err != nil {
+		t.Errorf("default Hasher: %v", err)
+	}
+
+	newHasher := func() *Hasher {
+		return newTestHasher(WithInsecureRand(strings.NewReader(strings.Repeat(tv.Salt, 4))))
+	}
+	h := newHasher()
+	if err := salt.AssertCryptoRand(h.rand); !errors.Is(err, salt.ErrInsecureRand) {
+		t.Errorf("AssertCryptoRand() = %v, want %v", err, salt.ErrInsecureRand)
+	}
+	first, err := h.Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := newHasher().Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Errorf("Hasher.Hash() = %s, then %s, want identical", first, second)
+	}
+}
+
+func TestHasher_HashParameters(t *testing.T) {
+	hashers := map[string]*Hasher{
+		"argon2i":  NewArgon2i(testParams),
+		"argon2id": NewArgon2id(testParams, WithTimestamp()),
+		"secret":   NewArgon2id(testParams, WithSecret("key-1", []byte("secret"))),
+	}
+	for name, h := range hashers {
+		t.Run(name, func(t *testing.T) {
+			encoded, err := h.Hash(tv.Password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := Parse(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantParams := want.Describe()
+			delete(wantParams, "created")
+
+			got := h.HashParameters()
+			if !reflect.DeepEqual(got.Describe(), wantParams) {
+				t.Errorf("Hasher.HashParameters() = %v, want %v", got.Describe(), wantParams)
+			}
+			if got.Strength() != want.Strength() {
+				t.Errorf("Hasher.HashParameters() strength = %d, want %d", got.Strength(), want.Strength())
+			}
+		})
+	}
+}
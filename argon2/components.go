@@ -0,0 +1,105 @@
+package argon2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zitadel/passwap/verifier"
+	"golang.org/x/crypto/argon2"
+)
+
+// ErrComponentsSecret is returned by [Hasher.HashComponents]
+// for Hashers with a secret, see [WithSecret].
+// The keyid of the secret is not part of the components,
+// so such hashes could not be verified later.
+var ErrComponentsSecret = errors.New("argon2: hash components can't be used with a secret")
+
+// HashComponents operates like [Hasher.Hash], but returns the
+// parameters, salt and hash instead of an encoded string.
+// This allows databases to store them in separate fields.
+// The returned Params retain the identifier, so that
+// [Params.Encode] produces the same string as Hash.
+// A creation time, see [WithTimestamp], is not returned.
+func (h *Hasher) HashComponents(password string) (p Params, salt, hash []byte, err error) {
+	if h.keyID != "" {
+		return Params{}, nil, nil, ErrComponentsSecret
+	}
+	salt, hash, err = h.derive(context.Background(), []byte(password))
+	if err != nil {
+		return Params{}, nil, nil, err
+	}
+	return h.p, salt, hash, nil
+}
+
+// VerifyComponents operates like [VerifyComponents], and returns
+// NeedUpdate when p differ from the parameters of the Hasher.
+// Hashes shorter than the MinKeyLen set [WithValidation]
+// fail like in [Hasher.Verify].
+func (h *Hasher) VerifyComponents(p Params, salt, hash []byte, password string) (verifier.Result, error) {
+	c, err := components(p, salt, hash)
+	if err != nil {
+		return verifier.Fail, err
+	}
+	if err = h.vopts.checkKeyLen(c.Params); err != nil {
+		return verifier.Fail, err
+	}
+	res, err := c.verify(context.Background(), []byte(password))
+	if err != nil || res == verifier.Fail {
+		return verifier.Fail, err
+	}
+	if h.p != c.Params {
+		h.checkDowngrade(c.Params)
+		return verifier.NeedUpdate, nil
+	}
+	return verifier.OK, nil
+}
+
+// VerifyComponents verifies password against hash and salt,
+// using the parameters p, as returned by [Hasher.HashComponents]
+// or [ParseParams]. The identifier of p defaults to argon2id.
+// KeyLen and SaltLen are ignored, as they are implied by
+// the length of hash and salt.
+// Either the result of Fail or OK is returned, or Fail
+// and an error when p can't be used for verification.
+func VerifyComponents(p Params, salt, hash []byte, password string) (verifier.Result, error) {
+	c, err := components(p, salt, hash)
+	if err != nil {
+		return verifier.Fail, err
+	}
+	return c.verify(context.Background(), []byte(password))
+}
+
+// components returns a checker for the parameters, salt and hash.
+func components(p Params, salt, hash []byte) (*checker, error) {
+	c := checker{
+		Params: p,
+		salt:   salt,
+		hash:   hash,
+	}
+	c.id = p.identifier()
+
+	switch c.id {
+	case Identifier_i:
+		c.hf = argon2.Key
+	case Identifier_id:
+		c.hf = argon2.IDKey
+	case Identifier_d:
+		return nil, ErrArgon2d
+	default:
+		return nil, fmt.Errorf("argon2: unknown identifier %s", c.id)
+	}
+	if c.Threads < 1 {
+		return nil, ErrArgon2Threads
+	}
+	if c.Time < 1 {
+		return nil, errors.New("argon2: time must be at least 1")
+	}
+	if len(hash) == 0 {
+		return nil, errors.New("argon2: empty hash")
+	}
+
+	c.KeyLen = uint32(len(hash))
+	c.SaltLen = uint32(len(salt))
+	return &c, nil
+}
@@ -0,0 +1,94 @@
+package argon2
+
+import (
+	"errors"
+	"testing"
+
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/verifier"
+)
+
+func TestHasher_HashComponents(t *testing.T) {
+	tests := [...]func(Params, ...Option) *Hasher{
+		NewArgon2i, NewArgon2id,
+	}
+
+	for _, tt := range tests {
+		h := tt(testParams)
+		t.Run(h.p.id, func(t *testing.T) {
+			p, salt, hash, err := h.HashComponents(tv.Password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p != h.p {
+				t.Errorf("Hasher.HashComponents() params = %v, want %v", p, h.p)
+			}
+
+			encoded := p.Encode(salt, hash)
+			if res, err := h.Verify(encoded, tv.Password); res != verifier.OK || err != nil {
+				t.Errorf("Hasher.Verify() = %s, %v, want %s", res, err, verifier.OK)
+			}
+			if res, err := h.VerifyComponents(p, salt, hash, tv.Password); res != verifier.OK || err != nil {
+				t.Errorf("Hasher.VerifyComponents() = %s, %v, want %s", res, err, verifier.OK)
+			}
+			if res, err := VerifyComponents(p, salt, hash, "spanac"); res != verifier.Fail || err != nil {
+				t.Errorf("VerifyComponents() = %s, %v, want %s", res, err, verifier.Fail)
+			}
+
+			encoded, err = h.Hash(tv.Password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, salt, hash, err = ParseParams(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res, err := VerifyComponents(p, salt, hash, tv.Password); res != verifier.OK || err != nil {
+				t.Errorf("VerifyComponents() = %s, %v, want %s", res, err, verifier.OK)
+			}
+		})
+	}
+
+	_, _, _, err := NewArgon2id(testParams, WithSecret("key", []byte("secret"))).HashComponents(tv.Password)
+	if !errors.Is(err, ErrComponentsSecret) {
+		t.Errorf("Hasher.HashComponents() error = %v, want %v", err, ErrComponentsSecret)
+	}
+}
+
+func TestVerifyComponents(t *testing.T) {
+	salt := []byte(tv.Salt)
+	outdated := testParams
+	outdated.Time++
+
+	tests := []struct {
+		name    string
+		p       Params
+		hash    []byte
+		want    verifier.Result
+		wantErr bool
+	}{
+		{"argon2id", testParams, tv.Argon2idHash, verifier.OK, false},
+		{"default identifier", Params{Time: tv.Argon2Time, Memory: tv.Argon2Memory, Threads: tv.Argon2Threads}, tv.Argon2idHash, verifier.OK, false},
+		{"outdated", outdated, tv.Argon2idHash, verifier.Fail, false},
+		{"argon2d", Params{Time: tv.Argon2Time, Memory: tv.Argon2Memory, Threads: tv.Argon2Threads, id: Identifier_d}, tv.Argon2idHash, verifier.Fail, true},
+		{"no threads", Params{Time: tv.Argon2Time, Memory: tv.Argon2Memory}, tv.Argon2idHash, verifier.Fail, true},
+		{"no time", Params{Memory: tv.Argon2Memory, Threads: tv.Argon2Threads}, tv.Argon2idHash, verifier.Fail, true},
+		{"empty hash", testParams, nil, verifier.Fail, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyComponents(tt.p, salt, tt.hash, tv.Password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyComponents() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("VerifyComponents() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	h := NewArgon2id(outdated)
+	if got, err := h.VerifyComponents(testParams, salt, tv.Argon2idHash, tv.Password); got != verifier.NeedUpdate || err != nil {
+		t.Errorf("Hasher.VerifyComponents() = %s, %v, want %s", got, err, verifier.NeedUpdate)
+	}
+}
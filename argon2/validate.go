@@ -0,0 +1,123 @@
+package argon2
+
+import (
+	"fmt"
+
+	"github.com/zitadel/passwap/verifier"
+	"golang.org/x/crypto/argon2"
+)
+
+// ValidationOpts bound the parameters of argon2 hashes,
+// as checked by [Validate] and [Hasher.Validate].
+// Zero values are not checked.
+type ValidationOpts struct {
+	MinMemory  uint32
+	MaxMemory  uint32
+	MinTime    uint32
+	MaxTime    uint32
+	MinThreads uint8
+	MaxThreads uint8
+	MinKeyLen  uint32
+	MaxKeyLen  uint32
+	MinSaltLen uint32
+}
+
+// RecommendedValidationOpts reject parameters below the
+// OWASP minimum of 19 MiB memory, and parameters
+// which could be abused to exhaust resources on verification.
+var RecommendedValidationOpts = ValidationOpts{
+	MinMemory:  19 * 1024,
+	MaxMemory:  4 * 1024 * 1024,
+	MinTime:    1,
+	MaxTime:    32,
+	MinThreads: 1,
+	MinKeyLen:  16,
+	MinSaltLen: 8,
+}
+
+// check returns a BoundsError for the first
+// parameter of p which is out of bounds.
+// The error reports the argon2 variant of p as Algorithm.
+func (o ValidationOpts) check(p Params) error {
+	bounds := []struct {
+		param    string
+		value    uint32
+		min, max uint32
+	}{
+		{"m", p.Memory, o.MinMemory, o.MaxMemory},
+		{"t", p.Time, o.MinTime, o.MaxTime},
+		{"p", uint32(p.Threads), uint32(o.MinThreads), uint32(o.MaxThreads)},
+		{"keylen", p.KeyLen, o.MinKeyLen, o.MaxKeyLen},
+		{"saltlen", p.SaltLen, o.MinSaltLen, 0},
+	}
+	for _, b := range bounds {
+		if err := verifier.CheckBounds(p.identifier(), b.param, int64(b.value), int64(b.min), int64(b.max)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkKeyLen returns a BoundsError when the
+// key length of p is below MinKeyLen.
+func (o ValidationOpts) checkKeyLen(p Params) error {
+	return verifier.CheckBounds(p.identifier(), "keylen", int64(p.KeyLen), int64(o.MinKeyLen), 0)
+}
+
+// Validate parses encoded and checks its parameters against opts.
+// A [verifier.BoundsError] is returned for the first parameter
+// which is out of bounds.
+func Validate(encoded string, opts ValidationOpts) error {
+	c, err := parse(encoded)
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		return fmt.Errorf("argon2 validate: missing %s prefix", Prefix)
+	}
+	return opts.check(c.Params)
+}
+
+// WithValidation sets the bounds used by [Hasher.Validate].
+// By default no bounds are checked, except by the
+// checked constructors like [NewArgon2idChecked].
+func WithValidation(opts ValidationOpts) Option {
+	return func(h *Hasher) {
+		h.vopts = opts
+	}
+}
+
+// Validate implements verifier.Validator.
+func (h *Hasher) Validate(encoded string) error {
+	return Validate(encoded, h.vopts)
+}
+
+// newHasherChecked returns a Hasher, after checking p
+// against the validation bounds of the Hasher.
+// The bounds default to RecommendedValidationOpts.
+func newHasherChecked(p Params, id string, hf hashFunc, opts []Option) (*Hasher, error) {
+	opts = append([]Option{WithValidation(RecommendedValidationOpts)}, opts...)
+	h := newHasher(p, id, hf, opts)
+	if err := h.vopts.check(h.p); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// NewArgon2iChecked is like [NewArgon2i], but returns an error
+// when p is out of the validation bounds of the Hasher,
+// so that weak hashes are never produced.
+// The bounds default to RecommendedValidationOpts
+// and can be set using [WithValidation].
+func NewArgon2iChecked(p Params, opts ...Option) (*Hasher, error) {
+	return newHasherChecked(p, Identifier_i, argon2.Key, opts)
+}
+
+// NewArgon2idChecked is like [NewArgon2id], but returns an error
+// when p is out of the validation bounds of the Hasher,
+// so that weak hashes are never produced.
+// The bounds default to RecommendedValidationOpts
+// and can be set using [WithValidation].
+func NewArgon2idChecked(p Params, opts ...Option) (*Hasher, error) {
+	return newHasherChecked(p, Identifier_id, argon2.IDKey, opts)
+}
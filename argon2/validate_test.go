@@ -0,0 +1,219 @@
+package argon2
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/verifier"
+	"golang.org/x/crypto/argon2"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		encoded   string
+		opts      ValidationOpts
+		wantParam string
+		wantErr   bool
+	}{
+		{
+			name:    "unbounded",
+			encoded: tv.Argon2idEncoded,
+		},
+		{
+			name:    "within bounds",
+			encoded: tv.Argon2idEncoded,
+			opts:    ValidationOpts{MinMemory: 4096, MaxMemory: 8192, MinTime: 3, MinThreads: 1, MinKeyLen: 32, MinSaltLen: 16},
+		},
+		{
+			name:      "memory below",
+			encoded:   tv.Argon2idEncoded,
+			opts:      RecommendedValidationOpts,
+			wantParam: "m",
+			wantErr:   true,
+		},
+		{
+			name:      "time above",
+			encoded:   tv.Argon2idEncoded,
+			opts:      ValidationOpts{MaxTime: 2},
+			wantParam: "t",
+			wantErr:   true,
+		},
+		{
+			name:      "threads below",
+			encoded:   tv.Argon2idEncoded,
+			opts:      ValidationOpts{MinThreads: 2},
+			wantParam: "p",
+			wantErr:   true,
+		},
+		{
+			name:      "key length below",
+			encoded:   tv.Argon2idEncoded,
+			opts:      ValidationOpts{MinKeyLen: 64},
+			wantParam: "keylen",
+			wantErr:   true,
+		},
+		{
+			name:      "salt length below",
+			encoded:   tv.Argon2idEncoded,
+			opts:      ValidationOpts{MinSaltLen: 32},
+			wantParam: "saltlen",
+			wantErr:   true,
+		},
+		{
+			name:    "parse error",
+			encoded: strings.Replace(tv.Argon2idEncoded, "m=", "x=", 1),
+			wantErr: true,
+		},
+		{
+			name:    "not argon2",
+			encoded: tv.ScryptEncoded,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.encoded, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var target *verifier.BoundsError
+			if errors.As(err, &target) != (tt.wantParam != "") {
+				t.Fatalf("Validate() error = %v, want BoundsError %t", err, tt.wantParam != "")
+			}
+			if target != nil && target.Param != tt.wantParam {
+				t.Errorf("Validate() param = %s, want %s", target.Param, tt.wantParam)
+			}
+		})
+	}
+}
+
+func TestValidate_variant(t *testing.T) {
+	tests := []struct {
+		encoded string
+		want    string
+	}{
+		{tv.Argon2iEncoded, Identifier_i},
+		{tv.Argon2idEncoded, Identifier_id},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			err := Validate(tt.encoded, RecommendedValidationOpts)
+			var target *verifier.BoundsError
+			if !errors.As(err, &target) {
+				t.Fatalf("Validate() error = %v, want BoundsError", err)
+			}
+			if target.Algorithm != tt.want {
+				t.Errorf("BoundsError.Algorithm = %s, want %s", target.Algorithm, tt.want)
+			}
+			if !strings.HasPrefix(err.Error(), tt.want+": ") {
+				t.Errorf("Validate() error = %q, want %s prefix", err, tt.want)
+			}
+		})
+	}
+
+	_, err := NewArgon2iChecked(Params{Time: 1, Memory: 1024, Threads: 1, KeyLen: 32, SaltLen: 16})
+	if err == nil || !strings.HasPrefix(err.Error(), Identifier_i+": ") {
+		t.Errorf("NewArgon2iChecked() error = %v, want %s prefix", err, Identifier_i)
+	}
+}
+
+func TestHasher_Validate(t *testing.T) {
+	encoded, err := NewArgon2id(testParams).Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = NewArgon2id(testParams).Validate(encoded); err != nil {
+		t.Errorf("Hasher.Validate() without bounds error = %v", err)
+	}
+	h := NewArgon2id(testParams, WithValidation(RecommendedValidationOpts))
+	var target *verifier.BoundsError
+	if err = h.Validate(encoded); !errors.As(err, &target) {
+		t.Errorf("Hasher.Validate() error = %v, want BoundsError", err)
+	}
+}
+
+func TestNewArgon2idChecked(t *testing.T) {
+	weak := Params{Time: 1, Memory: 1024, Threads: 1, KeyLen: 32, SaltLen: 16}
+
+	tests := []struct {
+		name    string
+		new     func(Params, ...Option) (*Hasher, error)
+		p       Params
+		opts    []Option
+		wantErr bool
+	}{
+		{"argon2id recommended", NewArgon2idChecked, RecommendedIDParams, nil, false},
+		{"argon2i recommended", NewArgon2iChecked, RecommendedIParams, nil, false},
+		{"argon2id weak", NewArgon2idChecked, weak, nil, true},
+		{"argon2i weak", NewArgon2iChecked, weak, nil, true},
+		{"weak with own bounds", NewArgon2idChecked, weak, []Option{WithValidation(ValidationOpts{MinMemory: 1024})}, false},
+		{"recommended above own bounds", NewArgon2idChecked, RecommendedIDParams, []Option{WithValidation(ValidationOpts{MaxMemory: 1024})}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := tt.new(tt.p, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				var target *verifier.BoundsError
+				if !errors.As(err, &target) {
+					t.Errorf("error = %v, want BoundsError", err)
+				}
+				if h != nil {
+					t.Error("Hasher returned with error")
+				}
+				return
+			}
+			encoded, err := h.Hash(tv.Password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = h.Validate(encoded); err != nil {
+				t.Errorf("Hasher.Validate() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestHasher_Verify_minKeyLen(t *testing.T) {
+	short := testParams
+	short.KeyLen = 4
+	salt := []byte(tv.Salt)
+	hash := argon2.IDKey([]byte(tv.Password), salt, short.Time, short.Memory, short.Threads, short.KeyLen)
+	encoded := short.Encode(salt, hash)
+
+	tests := []struct {
+		name      string
+		h         *Hasher
+		want      verifier.Result
+		wantBound bool
+	}{
+		{"unbounded", NewArgon2id(testParams), verifier.NeedUpdate, false},
+		{"below minimum", NewArgon2id(testParams, WithValidation(ValidationOpts{MinKeyLen: 16})), verifier.Fail, true},
+		{"at minimum", NewArgon2id(testParams, WithValidation(ValidationOpts{MinKeyLen: 4})), verifier.NeedUpdate, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.h.Verify(encoded, tv.Password)
+			var target *verifier.BoundsError
+			if errors.As(err, &target) != tt.wantBound {
+				t.Errorf("Hasher.Verify() error = %v, want BoundsError %t", err, tt.wantBound)
+			}
+			if tt.wantBound && target.Param != "keylen" {
+				t.Errorf("BoundsError.Param = %s, want keylen", target.Param)
+			}
+			if got != tt.want {
+				t.Errorf("Hasher.Verify() = %s, want %s", got, tt.want)
+			}
+
+			got, _ = tt.h.VerifyComponents(short, salt, hash, tv.Password)
+			if got != tt.want {
+				t.Errorf("Hasher.VerifyComponents() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package passwap
+
+import (
+	"reflect"
+
+	"github.com/zitadel/passwap/verifier"
+)
+
+// AuditCounts holds the number of audited hashes per verdict.
+type AuditCounts struct {
+	// OK hashes pass validation and use the
+	// current algorithm and parameters.
+	OK int
+	// NeedsUpdate hashes pass validation, but would be
+	// updated on the next successful verification.
+	NeedsUpdate int
+	// Invalid hashes are not recognized, malformed
+	// or don't pass validation.
+	Invalid int
+}
+
+func (c *AuditCounts) add(v auditVerdict) {
+	switch v {
+	case auditOK:
+		c.OK++
+	case auditNeedsUpdate:
+		c.NeedsUpdate++
+	default:
+		c.Invalid++
+	}
+}
+
+// AuditSummary is returned by [Swapper.AuditBatch].
+type AuditSummary struct {
+	// Total counts all audited hashes.
+	Total AuditCounts
+	// Algorithms counts the audited hashes per algorithm,
+	// as reported by [verifier.Named]. Hashes which are not
+	// recognized, or recognized by a Verifier that does not
+	// implement verifier.Named, are counted under the empty name.
+	Algorithms map[string]AuditCounts
+}
+
+type auditVerdict int
+
+const (
+	auditOK auditVerdict = iota
+	auditNeedsUpdate
+	auditInvalid
+)
+
+// auditPassword is hashed once by [Swapper.AuditBatch],
+// to obtain the parameters of the Hasher.
+const auditPassword = "passwap-audit"
+
+// AuditBatch counts the algorithms of encoded hashes and whether
+// they pass the current policy, for example to audit all hashes
+// stored in a table. No passwords are needed or verified.
+//
+// Each hash is recognized by the first Verifier which implements
+// [verifier.Identifier], and checked with [verifier.Validator]
+// when the Verifier implements it. Verifiers which don't implement
+// verifier.Identifier can't recognize hashes without a password,
+// so their hashes are counted as invalid.
+// Hashes recognized by the Hasher need an update when their
+// parameters differ from those of a new hash, ignoring the
+// creation time. Hashes recognized by any other Verifier
+// need an update, unless it is marked current,
+// see [WithCurrentVerifiers].
+//
+// This hashes a single password with the Hasher, unless
+// it implements [verifier.Configured].
+func (s *Swapper) AuditBatch(encoded []string) AuditSummary {
+	summary := AuditSummary{
+		Algorithms: make(map[string]AuditCounts),
+	}
+	target := s.auditTarget()
+
+	for _, e := range encoded {
+		name, verdict := s.audit(e, target)
+		summary.Total.add(verdict)
+
+		counts := summary.Algorithms[name]
+		counts.add(verdict)
+		summary.Algorithms[name] = counts
+	}
+	return summary
+}
+
+// auditTarget returns the parameters of a new hash,
+// or nil when they can't be obtained.
+// Hashers which implement verifier.Configured report them
+// directly, others have to hash a password to be identified.
+func (s *Swapper) auditTarget() map[string]any {
+	if params := s.hashParameters(); params != nil {
+		return auditParams(params.Describe())
+	}
+	identifier, ok := s.h.(verifier.Identifier)
+	if !ok {
+		return nil
+	}
+	encoded, err := s.h.Hash(auditPassword)
+	if err != nil {
+		return nil
+	}
+	params, err := identifier.Identify(encoded)
+	if err != nil {
+		return nil
+	}
+	return auditParams(params)
+}
+
+// auditParams returns a copy of params without the
+// creation time, which differs for every hash.
+func auditParams(params map[string]any) map[string]any {
+	out := make(map[string]any, len(params))
+	for k, v := range params {
+		if k != "created" {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func (s *Swapper) audit(encoded string, target map[string]any) (name string, verdict auditVerdict) {
+	encoded, err := s.decode(encoded)
+	if err != nil {
+		return "", auditInvalid
+	}
+	for i, v := range s.verifiers {
+		identifier, ok := v.(verifier.Identifier)
+		if !ok {
+			continue
+		}
+		params, err := identifier.Identify(encoded)
+		if err == nil && params == nil {
+			continue
+		}
+		if n, ok := v.(verifier.Named); ok {
+			name = n.Algorithm()
+		}
+		if err != nil {
+			return name, auditInvalid
+		}
+		if validator, ok := v.(verifier.Validator); ok {
+			if validator.Validate(encoded) != nil {
+				return name, auditInvalid
+			}
+		}
+		if !s.isCurrent(i) {
+			return name, auditNeedsUpdate
+		}
+		if i == 0 && target != nil && !reflect.DeepEqual(auditParams(params), target) {
+			return name, auditNeedsUpdate
+		}
+		return name, auditOK
+	}
+	return "", auditInvalid
+}
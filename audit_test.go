@@ -0,0 +1,63 @@
+package passwap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zitadel/passwap/argon2"
+	"github.com/zitadel/passwap/bcrypt"
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	md5crypt "github.com/zitadel/passwap/md5"
+)
+
+func TestSwapper_AuditBatch(t *testing.T) {
+	s := NewSwapper(
+		argon2.NewArgon2id(testArgon2Params,
+			argon2.WithTimestamp(),
+			argon2.WithValidation(argon2.ValidationOpts{MinMemory: tv.Argon2Memory}),
+		),
+		bcrypt.New(bcrypt.MinCost),
+		md5crypt.Verifier,
+	)
+	encoded := []string{
+		tv.Argon2idEncoded,
+		strings.Replace(tv.Argon2idEncoded, "p=1", "p=1,ts=1700000000", 1),
+		strings.Replace(tv.Argon2idEncoded, "t=3", "t=2", 1),
+		strings.Replace(tv.Argon2idEncoded, "m=4096", "m=2048", 1),
+		tv.Argon2iEncoded,
+		"$argon2id$foo",
+		tv.EncodedBcrypt2b,
+		tv.MD5Encoded,
+		"foobar",
+	}
+
+	got := s.AuditBatch(encoded)
+	want := AuditSummary{
+		Total: AuditCounts{OK: 2, NeedsUpdate: 3, Invalid: 4},
+		Algorithms: map[string]AuditCounts{
+			argon2.Algorithm: {OK: 2, NeedsUpdate: 2, Invalid: 2},
+			bcrypt.Algorithm: {NeedsUpdate: 1},
+			"":               {Invalid: 2},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Swapper.AuditBatch() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	got = s.AuditBatch(nil)
+	if want := (AuditSummary{Algorithms: map[string]AuditCounts{}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Swapper.AuditBatch() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSwapper_AuditBatch_current(t *testing.T) {
+	b := bcrypt.New(bcrypt.MinCost)
+	s := NewSwapper(testHasher, b).Apply(WithAcceptableVerifiers(b))
+
+	got := s.AuditBatch([]string{tv.EncodedBcrypt2b})
+	want := AuditCounts{OK: 1}
+	if got.Algorithms[bcrypt.Algorithm] != want {
+		t.Errorf("Swapper.AuditBatch() = %+v, want %s %+v", got, bcrypt.Algorithm, want)
+	}
+}
@@ -2,6 +2,11 @@ package bcrypt
 
 import (
 	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
 
 	"github.com/zitadel/passwap/verifier"
 	"golang.org/x/crypto/bcrypt"
@@ -18,6 +23,37 @@ var (
 	Versions = [...]byte{'a', 'b', 'y'}
 )
 
+// ErrBcrypt2x is returned for hashes with the `$2x$` prefix.
+// This prefix was introduced by PHP's crypt_blowfish to mark hashes
+// created by its buggy implementation, which sign-extended
+// bytes of passwords with 8-bit characters.
+// The bcrypt package only implements the correct algorithm,
+// so such hashes can't be safely verified. Users should be
+// asked to reset their password instead.
+var ErrBcrypt2x = errors.New("bcrypt: $2x$ hashes of the buggy crypt_blowfish implementation are not supported")
+
+// ErrMalformed is returned with a Skip result for encoded strings
+// which have a bcrypt prefix, but can't be a bcrypt hash.
+// For example when the salt or hash are truncated,
+// overlong or contain invalid characters.
+var ErrMalformed = errors.New("bcrypt: malformed hash")
+
+// Lengths of an encoded bcrypt hash with a two digit cost:
+// `$2b$10$` followed by 22 characters of salt
+// and 31 characters of hash.
+const (
+	encodedLen     = 60
+	encodedSaltLen = 22
+	encodedHashLen = 31
+)
+
+// alphabet of the bcrypt base64 encoding.
+const alphabet = "./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// prefix2x is the prefix of hashes created by the buggy
+// crypt_blowfish implementation.
+const prefix2x = Prefix + "x$"
+
 const (
 	MinCost     = bcrypt.MinCost
 	MaxCost     = bcrypt.MaxCost
@@ -28,7 +64,7 @@ const (
 // and all of the declared Versions or the
 // Prefix used for the first version of Bcrypt.
 func hasBcryptVersion(encoded []byte) bool {
-	if !bytes.HasPrefix(encoded, []byte(Prefix)) {
+	if !bytes.HasPrefix(encoded, []byte(Prefix)) || len(encoded) < 3 {
 		return false
 	}
 
@@ -41,6 +77,71 @@ func hasBcryptVersion(encoded []byte) bool {
 	return false
 }
 
+// parse checks encoded for a bcrypt prefix and version
+// and returns its cost parameter.
+// Legacy hashes which store the cost as a single digit,
+// like `$2b$5$...`, are normalized to the two digit form
+// expected by the bcrypt package and returned as normalized.
+// A nil normalized and nil error are returned when encoded
+// is not a bcrypt hash.
+// ErrBcrypt2x is returned for `$2x$` hashes.
+// ErrMalformed is returned for hashes of the wrong length
+// or with invalid characters in the salt or hash.
+func parse(encoded []byte) (normalized []byte, cost int, err error) {
+	if bytes.HasPrefix(encoded, []byte(prefix2x)) {
+		return nil, 0, ErrBcrypt2x
+	}
+	if !hasBcryptVersion(encoded) {
+		return nil, 0, nil
+	}
+	if len(encoded) > 5 && encoded[3] == '$' && isDigit(encoded[4]) && encoded[5] == '$' {
+		normalized = make([]byte, 0, len(encoded)+1)
+		normalized = append(normalized, encoded[:4]...)
+		normalized = append(normalized, '0')
+		normalized = append(normalized, encoded[4:]...)
+	} else {
+		normalized = encoded
+	}
+
+	if err = checkStructure(normalized); err != nil {
+		return nil, 0, err
+	}
+	cost, err = bcrypt.Cost(normalized)
+	if err != nil {
+		return nil, 0, fmt.Errorf("bcrypt parse: %w", err)
+	}
+	return normalized, cost, nil
+}
+
+// checkStructure checks the length of normalized
+// and the characters of its salt and hash.
+func checkStructure(normalized []byte) error {
+	if len(normalized) != encodedLen {
+		return fmt.Errorf("bcrypt parse: %w: length %d, want %d", ErrMalformed, len(normalized), encodedLen)
+	}
+	for _, b := range normalized[encodedLen-encodedSaltLen-encodedHashLen:] {
+		if strings.IndexByte(alphabet, b) < 0 {
+			return fmt.Errorf("bcrypt parse: %w: invalid character %q", ErrMalformed, b)
+		}
+	}
+	return nil
+}
+
+// parseErrorResult returns the Result for an error returned by parse.
+// `$2x$` hashes are recognized as bcrypt, but can't be verified
+// and result in Fail, so that no other Verifier is tried.
+// All other parse errors result in Skip.
+func parseErrorResult(err error) verifier.Result {
+	if errors.Is(err, ErrBcrypt2x) {
+		return verifier.Fail
+	}
+	return verifier.Skip
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
 // compareHashAndPassword wraps bcrypt.CompareHashAndPassword
 // in order to translate bcrypt package errors to Results and errors
 // compatible with this project.
@@ -53,11 +154,17 @@ func compareHashAndPassword(encoded, password []byte) (verifier.Result, error) {
 		return verifier.Fail, nil
 	}
 
+	if err == bcrypt.ErrHashTooShort {
+		return verifier.Skip, fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+
 	switch err.(type) {
 	case bcrypt.InvalidHashPrefixError:
 		return verifier.Skip, err
 	case bcrypt.HashVersionTooNewError:
 		return verifier.Skip, err
+	case base64.CorruptInputError:
+		return verifier.Skip, fmt.Errorf("%w: %w", ErrMalformed, err)
 	default:
 		return verifier.Fail, err
 	}
@@ -65,60 +172,264 @@ func compareHashAndPassword(encoded, password []byte) (verifier.Result, error) {
 
 // Hasher hashes and verifies bcrypt passwords.
 type Hasher struct {
-	cost int
+	cost    int
+	prehash bool
+	vopts   ValidationOpts
+
+	// rand is the source of salts. When nil, hashes are
+	// generated by golang.org/x/crypto/bcrypt with salts
+	// from crypto/rand.Reader. It must be safe for concurrent use.
+	rand io.Reader
+}
+
+// Option configures a Hasher.
+type Option func(*Hasher)
+
+// WithInsecureRand sets the source of salts to r, instead of
+// crypto/rand.Reader, so that the Hasher produces reproducible
+// hashes for known-answer test vectors, see package vectors.
+// Hashes with predictable salts must never be stored,
+// hence the name. This is the only way to replace the
+// source of salts, Hashers otherwise always use crypto/rand.Reader.
+// r is shared by concurrent calls to Hash.
+func WithInsecureRand(r io.Reader) Option {
+	return func(h *Hasher) {
+		h.rand = r
+	}
 }
 
 // Hash implements passwap.Hasher.
 func (h *Hasher) Hash(password string) (string, error) {
-	encoded, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	encoded, err := generateFromPassword(h.rand, keyFor(password, h.prehash), h.cost)
 	if err != nil {
 		return "", err
 	}
+	return h.encode(encoded), nil
+}
 
-	return string(encoded), nil
+// HashWithSalt operates like [Hasher.Hash], but uses salt instead
+// of a random salt. The same password, salt and cost always result
+// in the same hash, for example to generate golden test values or
+// to reproduce hashes of a migration.
+// The salt length is enforced by its type.
+//
+// The salt must be random for every password that is stored,
+// reusing a salt defeats its purpose.
+func (h *Hasher) HashWithSalt(password string, salt [16]byte) (string, error) {
+	encoded, err := generateWithSalt(keyFor(password, h.prehash), h.cost, salt[:])
+	if err != nil {
+		return "", err
+	}
+	return h.encode(encoded), nil
 }
 
-// Verify implements passwap.Verifier
-func (h *Hasher) Verify(encoded, password string) (verifier.Result, error) {
-	encodedB := []byte(encoded)
-	if !hasBcryptVersion(encodedB) {
-		return verifier.Skip, nil
+// encode returns the encoded string of a bcrypt hash,
+// in the bcrypt-sha256 format when the Hasher prehashes.
+func (h *Hasher) encode(encoded []byte) string {
+	if h.prehash {
+		return encodePrehashed(encoded)
 	}
+	return string(encoded)
+}
 
-	cost, err := bcrypt.Cost(encodedB)
-	if err != nil {
-		return verifier.Skip, err
+// Verify implements passwap.Verifier.
+// NeedUpdate is returned when the cost differs from the
+// Hasher, or when the hash is plain bcrypt and the Hasher
+// prehashes passwords, or the other way around.
+func (h *Hasher) Verify(encoded, password string) (verifier.Result, error) {
+	encodedB, cost, prehashed, err := decode(encoded)
+	if err != nil || encodedB == nil {
+		return parseErrorResult(err), err
 	}
 
-	result, err := compareHashAndPassword(encodedB, []byte(password))
+	result, err := compareHashAndPassword(encodedB, keyFor(password, prehashed))
 	if err != nil || result != verifier.OK {
 		return result, err
 	}
 
-	if cost != h.cost {
+	if cost != h.cost || prehashed != h.prehash {
 		result = verifier.NeedUpdate
 	}
 
 	return result, nil
 }
 
+// keyFor returns the key passed to bcrypt for password.
+func keyFor(password string, prehashed bool) []byte {
+	if prehashed {
+		return prehashPassword(password)
+	}
+	return []byte(password)
+}
+
+// Inspect parses encoded and returns its cost,
+// without verifying a password.
+// needsUpdate is true when the cost or prehashing differs from the Hasher,
+// which means Verify would return NeedUpdate on a successful match.
+// This allows auditing stored hashes without knowing the passwords.
+// ErrBcrypt2x is returned for `$2x$` hashes.
+func (h *Hasher) Inspect(encoded string) (cost int, needsUpdate bool, err error) {
+	encodedB, cost, prehashed, err := decode(encoded)
+	if err != nil {
+		return 0, false, err
+	}
+	if encodedB == nil {
+		return 0, false, errors.New("bcrypt inspect: not a bcrypt hash")
+	}
+	return cost, cost != h.cost || prehashed != h.prehash, nil
+}
+
+// Identify implements verifier.Identifier.
+func (h *Hasher) Identify(encoded string) (map[string]any, error) {
+	return Identify(encoded)
+}
+
+// HashParameters implements verifier.Configured.
+// Like Hash, a cost below MinCost is reported as DefaultCost.
+func (h *Hasher) HashParameters() verifier.Parameters {
+	cost := h.cost
+	if cost < MinCost {
+		cost = DefaultCost
+	}
+	return &params{
+		identifier: Identifier + "a",
+		cost:       cost,
+		prehashed:  h.prehash,
+	}
+}
+
+// Parse implements verifier.Parser.
+func (h *Hasher) Parse(encoded string) (verifier.Parameters, error) {
+	return Parse(encoded)
+}
+
+// Algorithm implements verifier.Named.
+func (h *Hasher) Algorithm() string {
+	return Algorithm
+}
+
+// HashPrefixes implements verifier.Prefixed.
+func (h *Hasher) HashPrefixes() []string {
+	return prefixes
+}
+
 // New will return a Hasher with cost as bcrypt parameter.
-func New(cost int) *Hasher {
-	return &Hasher{
+func New(cost int, opts ...Option) *Hasher {
+	h := &Hasher{
 		cost: cost,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // Verify parses encoded and uses its bcrypt parameters
 // to verify password against its hash.
+// Skip is returned when encoded is not a bcrypt hash
+// or its cost can't be parsed.
+// Fail and ErrBcrypt2x are returned for `$2x$` hashes,
+// as they can't be verified safely.
+// Hashes of prehashed passwords are verified as well,
+// see [WithPrehash].
 func Verify(encoded, password string) (verifier.Result, error) {
-	encodedB := []byte(encoded)
-	if !hasBcryptVersion(encodedB) {
-		return verifier.Skip, nil
+	encodedB, _, prehashed, err := decode(encoded)
+	if err != nil || encodedB == nil {
+		return parseErrorResult(err), err
 	}
 
-	return compareHashAndPassword(encodedB, []byte(password))
+	return compareHashAndPassword(encodedB, keyFor(password, prehashed))
 }
 
+// Identify parses encoded and returns its
+// identifier, including version, and cost.
+func Identify(encoded string) (map[string]any, error) {
+	p, err := Parse(encoded)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	return p.Describe(), nil
+}
+
+// params of a bcrypt hash.
+type params struct {
+	identifier string
+	cost       int
+	prehashed  bool
+}
+
+// Strength implements verifier.Parameters.
+// Each of the 2^cost rounds of the expensive key setup
+// encrypts about 2^10 Blowfish blocks.
+func (p *params) Strength() int {
+	return p.cost + 10
+}
+
+// Describe implements verifier.Parameters.
+func (p *params) Describe() map[string]any {
+	params := map[string]any{
+		"identifier": p.identifier,
+		"cost":       p.cost,
+	}
+	if p.prehashed {
+		params["prehash"] = "sha256"
+	}
+	return params
+}
+
+// Parse parses encoded and returns its bcrypt parameters.
+// Nil Parameters and a nil error are returned when
+// encoded is not a bcrypt hash.
+func Parse(encoded string) (verifier.Parameters, error) {
+	encodedB, cost, prehashed, err := decode(encoded)
+	if err != nil || encodedB == nil {
+		return nil, err
+	}
+	return &params{
+		identifier: string(encodedB[1:3]),
+		cost:       cost,
+		prehashed:  prehashed,
+	}, nil
+}
+
+// Algorithm is the name reported by the Verifier and Hasher,
+// see verifier.Named.
+const Algorithm = "bcrypt"
+
+// prefixes recognized by the Verifier and Hasher.
+var prefixes = []string{Prefix, PrefixSHA256}
+
 // Verifier for Bcrypt.
-var Verifier = verifier.VerifyFunc(Verify)
+var Verifier = &verifier.NamedFunc{Name: Algorithm, VerifyFunc: Verify, Prefixes: prefixes}
+
+// Prefixes returns the prefixes of the hashes handled by this package,
+// as recognized by the Verifier, see verifier.Prefixed.
+func Prefixes() []string {
+	return append([]string(nil), prefixes...)
+}
+
+// NewVerifierBase64Tolerant returns a Verifier for Bcrypt,
+// which also accepts hashes that are wrapped in standard base64
+// encoding, as stored by some auth libraries for transport.
+// When encoded does not have the bcrypt prefix, it is decoded
+// once and verified if the result has the prefix.
+// Otherwise Skip is returned.
+func NewVerifierBase64Tolerant() verifier.Verifier {
+	return &verifier.NamedFunc{Name: Algorithm, VerifyFunc: func(encoded, password string) (verifier.Result, error) {
+		if !strings.HasPrefix(encoded, Prefix) && !strings.HasPrefix(encoded, PrefixSHA256) {
+			decoded, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil || !bytes.HasPrefix(decoded, []byte(Prefix)) {
+				return verifier.Skip, nil
+			}
+			encoded = string(decoded)
+		}
+		return Verify(encoded, password)
+	}}
+}
+
+// SelfTestVector returns a fixed password and its encoded hash,
+// which [Verify] must accept. It guards against regressions
+// of the encoding format, see passwap.SelfTest.
+func SelfTestVector() (password, encoded string) {
+	return "password", `$2a$05$gwYVA3iVXaOHnrSTssht8.mpL3XXO0bP4FbnI6Ge23P4LF1TGEIbu`
+}
@@ -0,0 +1,31 @@
+package bcrypt
+
+import (
+	"testing"
+
+	"github.com/zitadel/passwap/internal/testvalues"
+)
+
+func BenchmarkHasher_Hash(b *testing.B) {
+	h := New(MinCost)
+	for i := 0; i < b.N; i++ {
+		if _, err := h.Hash(testvalues.Password); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHasher_Verify(b *testing.B) {
+	h := New(MinCost)
+	encoded, err := h.Hash(testvalues.Password)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := h.Verify(encoded, testvalues.Password); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package bcrypt
 
 import (
 	"crypto/rand"
+	"encoding/base64"
+	"errors"
 	"io"
 	"reflect"
 	"strings"
@@ -46,6 +48,10 @@ func Test_hasBcryptVersion(t *testing.T) {
 			name: "unsupported version",
 			args: args{strings.ReplaceAll(testvalues.EncodedBcrypt2b, "$2b$", "$2e$")},
 		},
+		{
+			name: "prefix only",
+			args: args{Prefix},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -56,6 +62,63 @@ func Test_hasBcryptVersion(t *testing.T) {
 	}
 }
 
+func Test_parse(t *testing.T) {
+	tests := []struct {
+		name           string
+		encoded        string
+		wantNormalized string
+		wantCost       int
+		wantErr        bool
+	}{
+		{
+			name:    "not bcrypt",
+			encoded: testvalues.ScryptEncoded,
+		},
+		{
+			name:    "cost error",
+			encoded: "$2b$foo",
+			wantErr: true,
+		},
+		{
+			name:    "2x",
+			encoded: strings.Replace(testvalues.EncodedBcrypt2a, "$2a$", "$2x$", 1),
+			wantErr: true,
+		},
+		{
+			name:    "cost below minimum",
+			encoded: strings.Replace(testvalues.EncodedBcryptCost5SingleDigit, "$5$", "$3$", 1),
+			wantErr: true,
+		},
+		{
+			name:           "two digit cost",
+			encoded:        testvalues.EncodedBcrypt2b,
+			wantNormalized: testvalues.EncodedBcrypt2b,
+			wantCost:       testvalues.BcryptCost,
+		},
+		{
+			name:           "single digit cost",
+			encoded:        testvalues.EncodedBcryptCost5SingleDigit,
+			wantNormalized: testvalues.EncodedBcryptCost5,
+			wantCost:       5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotNormalized, gotCost, err := parse([]byte(tt.encoded))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if string(gotNormalized) != tt.wantNormalized {
+				t.Errorf("parse() normalized = %s, want %s", gotNormalized, tt.wantNormalized)
+			}
+			if gotCost != tt.wantCost {
+				t.Errorf("parse() cost = %d, want %d", gotCost, tt.wantCost)
+			}
+		})
+	}
+}
+
 func Test_compareHashAndPassword(t *testing.T) {
 	type args struct {
 		encoded  string
@@ -102,9 +165,15 @@ func Test_compareHashAndPassword(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name:    "other error",
+			name:    "too short",
 			args:    args{"$2b$foo", testvalues.Password},
-			want:    verifier.Fail,
+			want:    verifier.Skip,
+			wantErr: true,
+		},
+		{
+			name:    "corrupt salt",
+			args:    args{testvalues.EncodedBcrypt2b[:7] + "!" + testvalues.EncodedBcrypt2b[8:], testvalues.Password},
+			want:    verifier.Skip,
 			wantErr: true,
 		},
 	}
@@ -168,6 +237,82 @@ func TestHasher_Hash(t *testing.T) {
 	}
 }
 
+func TestHasher_Hash_entropy(t *testing.T) {
+	readers := map[string]func() io.Reader{
+		"error":      func() io.Reader { return salt.ErrReader{} },
+		"short read": func() io.Reader { return strings.NewReader("short") },
+	}
+	for name, r := range readers {
+		t.Run(name, func(t *testing.T) {
+			h := New(MinCost)
+			h.rand = r()
+			got, err := h.Hash(testvalues.Password)
+			if !errors.Is(err, salt.ErrEntropy) {
+				t.Errorf("Hasher.Hash() error = %v, want %v", err, salt.ErrEntropy)
+			}
+			if got != "" {
+				t.Errorf("Hasher.Hash() = %s, want empty", got)
+			}
+		})
+	}
+}
+
+func TestHasher_HashWithSalt(t *testing.T) {
+	salt := [16]byte{0: 1, 7: 42, 15: 255}
+	tests := []struct {
+		name string
+		h    *Hasher
+	}{
+		{"plain", New(MinCost)},
+		{"prehash", New(MinCost, WithPrehash())},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, err := tt.h.HashWithSalt(testvalues.Password, salt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := tt.h.HashWithSalt(testvalues.Password, salt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if first != second {
+				t.Errorf("Hasher.HashWithSalt() = %s, then %s, want identical", first, second)
+			}
+			if res, err := tt.h.Verify(first, testvalues.Password); err != nil || res != verifier.OK {
+				t.Errorf("Hasher.Verify() = %s, %v, want %s", res, err, verifier.OK)
+			}
+
+			other, err := tt.h.HashWithSalt(testvalues.Password, [16]byte{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if other == first {
+				t.Errorf("Hasher.HashWithSalt() = %s for different salts", other)
+			}
+		})
+	}
+
+	t.Run("golden", func(t *testing.T) {
+		// vector of the OpenBSD bcrypt regression tests.
+		const want = "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW"
+		var golden [16]byte
+		if _, err := encoding.Decode(golden[:], []byte(want[7:29])); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := New(5).HashWithSalt("U*U", golden); err != nil || got != want {
+			t.Errorf("Hasher.HashWithSalt() = %s, %v, want %s", got, err, want)
+		}
+	})
+
+	t.Run("password too long", func(t *testing.T) {
+		_, err := New(MinCost).HashWithSalt(strings.Repeat("a", 73), salt)
+		if !errors.Is(err, bcrypt.ErrPasswordTooLong) {
+			t.Errorf("Hasher.HashWithSalt() error = %v, want %v", err, bcrypt.ErrPasswordTooLong)
+		}
+	})
+}
+
 func TestHasher_Verify(t *testing.T) {
 	type fields struct {
 		cost int
@@ -226,6 +371,12 @@ func TestHasher_Verify(t *testing.T) {
 			args:   args{testvalues.EncodedBcrypt2b, testvalues.Password},
 			want:   verifier.NeedUpdate,
 		},
+		{
+			name:   "single digit cost",
+			fields: fields{5},
+			args:   args{testvalues.EncodedBcryptCost5SingleDigit, testvalues.Password},
+			want:   verifier.OK,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -278,6 +429,28 @@ func TestVerify(t *testing.T) {
 			args: args{testvalues.EncodedBcrypt2b, "foobar"},
 			want: verifier.Fail,
 		},
+		{
+			name:    "cost error",
+			args:    args{"$2b$foo", testvalues.Password},
+			want:    verifier.Skip,
+			wantErr: true,
+		},
+		{
+			name: "single digit cost",
+			args: args{testvalues.EncodedBcryptCost5SingleDigit, testvalues.Password},
+			want: verifier.OK,
+		},
+		{
+			name:    "2x",
+			args:    args{strings.Replace(testvalues.EncodedBcrypt2a, "$2a$", "$2x$", 1), testvalues.Password},
+			want:    verifier.Fail,
+			wantErr: true,
+		},
+		{
+			name: "single digit cost, wrong password",
+			args: args{testvalues.EncodedBcryptCost5SingleDigit, "foobar"},
+			want: verifier.Fail,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -292,3 +465,247 @@ func TestVerify(t *testing.T) {
 		})
 	}
 }
+
+func TestIdentify(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		want    map[string]any
+		wantErr bool
+	}{
+		{
+			name:    "skip",
+			encoded: testvalues.ScryptEncoded,
+		},
+		{
+			name:    "parse error",
+			encoded: "$2b$foo",
+			wantErr: true,
+		},
+		{
+			name:    "success",
+			encoded: testvalues.EncodedBcrypt2y,
+			want: map[string]any{
+				"identifier": "2y",
+				"cost":       testvalues.BcryptCost,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(testvalues.BcryptCost).Identify(tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Identify() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Identify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasher_Inspect(t *testing.T) {
+	tests := []struct {
+		name            string
+		encoded         string
+		wantCost        int
+		wantNeedsUpdate bool
+		wantErr         bool
+	}{
+		{
+			name:    "not bcrypt",
+			encoded: testvalues.ScryptEncoded,
+			wantErr: true,
+		},
+		{
+			name:    "parse error",
+			encoded: "$2b$foo",
+			wantErr: true,
+		},
+		{
+			name:    "2x",
+			encoded: strings.Replace(testvalues.EncodedBcrypt2a, "$2a$", "$2x$", 1),
+			wantErr: true,
+		},
+		{
+			name:     "same cost",
+			encoded:  testvalues.EncodedBcrypt2b,
+			wantCost: testvalues.BcryptCost,
+		},
+		{
+			name:            "lower cost",
+			encoded:         testvalues.EncodedBcryptCost10,
+			wantCost:        10,
+			wantNeedsUpdate: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotCost, gotNeedsUpdate, err := New(testvalues.BcryptCost).Inspect(tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Hasher.Inspect() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotCost != tt.wantCost || gotNeedsUpdate != tt.wantNeedsUpdate {
+				t.Errorf("Hasher.Inspect() = %d, %v, want %d, %v", gotCost, gotNeedsUpdate, tt.wantCost, tt.wantNeedsUpdate)
+			}
+		})
+	}
+}
+
+func TestErrBcrypt2x(t *testing.T) {
+	encoded := strings.Replace(testvalues.EncodedBcrypt2a, "$2a$", "$2x$", 1)
+
+	res, err := Verify(encoded, testvalues.Password)
+	if res != verifier.Fail || !errors.Is(err, ErrBcrypt2x) {
+		t.Errorf("Verify() = %s, %v, want %s, %v", res, err, verifier.Fail, ErrBcrypt2x)
+	}
+	res, err = New(testvalues.BcryptCost).Verify(encoded, testvalues.Password)
+	if res != verifier.Fail || !errors.Is(err, ErrBcrypt2x) {
+		t.Errorf("Hasher.Verify() = %s, %v, want %s, %v", res, err, verifier.Fail, ErrBcrypt2x)
+	}
+}
+
+func TestNewVerifierBase64Tolerant(t *testing.T) {
+	v := NewVerifierBase64Tolerant()
+	wrapped := base64.StdEncoding.EncodeToString([]byte(testvalues.EncodedBcrypt2b))
+
+	tests := []struct {
+		name     string
+		encoded  string
+		password string
+		want     verifier.Result
+		wantErr  bool
+	}{
+		{
+			name:     "raw",
+			encoded:  testvalues.EncodedBcrypt2b,
+			password: testvalues.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "base64 wrapped",
+			encoded:  wrapped,
+			password: testvalues.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "base64 wrapped, wrong password",
+			encoded:  wrapped,
+			password: "foobar",
+			want:     verifier.Fail,
+		},
+		{
+			name:     "base64 wrapped other hash",
+			encoded:  base64.StdEncoding.EncodeToString([]byte(testvalues.ScryptEncoded)),
+			password: testvalues.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "not base64",
+			encoded:  testvalues.ScryptEncoded,
+			password: testvalues.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "base64 wrapped 2x",
+			encoded:  base64.StdEncoding.EncodeToString([]byte(strings.Replace(testvalues.EncodedBcrypt2a, "$2a$", "$2x$", 1))),
+			password: testvalues.Password,
+			want:     verifier.Fail,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := v.Verify(tt.encoded, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerify_malformed(t *testing.T) {
+	encoded := testvalues.EncodedBcrypt2b
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"truncated", encoded[:len(encoded)-1]},
+		{"truncated salt", encoded[:7+21]},
+		{"overlong", encoded + "a"},
+		{"invalid salt character", encoded[:10] + "!" + encoded[11:]},
+		{"invalid hash character", encoded[:50] + "$" + encoded[51:]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Verify(tt.encoded, testvalues.Password)
+			if !errors.Is(err, ErrMalformed) {
+				t.Errorf("Verify() error = %v, want %v", err, ErrMalformed)
+			}
+			if got != verifier.Skip {
+				t.Errorf("Verify() = %s, want %s", got, verifier.Skip)
+			}
+		})
+	}
+}
+
+func TestWithInsecureRand(t *testing.T) {
+	if h := New(MinCost); h.rand != nil {
+		t.Errorf("default Hasher reads salts from %T, want crypto/rand.Reader", h.rand)
+	}
+
+	newHasher := func() *Hasher {
+		return New(MinCost, WithInsecureRand(strings.NewReader(strings.Repeat(testvalues.Salt, 2))))
+	}
+	h := newHasher()
+	if err := salt.AssertCryptoRand(h.rand); !errors.Is(err, salt.ErrInsecureRand) {
+		t.Errorf("AssertCryptoRand() = %v, want %v", err, salt.ErrInsecureRand)
+	}
+	first, err := h.Hash(testvalues.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := newHasher().Hash(testvalues.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Errorf("Hasher.Hash() = %s, then %s, want identical", first, second)
+	}
+}
+
+func TestHasher_HashParameters(t *testing.T) {
+	hashers := map[string]*Hasher{
+		"cost":     New(MinCost),
+		"min cost": New(MinCost - 1),
+		"prehash":  New(MinCost, WithPrehash()),
+	}
+	for name, h := range hashers {
+		t.Run(name, func(t *testing.T) {
+			encoded, err := h.Hash(testvalues.Password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := Parse(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantParams := want.Describe()
+			delete(wantParams, "created")
+
+			got := h.HashParameters()
+			if !reflect.DeepEqual(got.Describe(), wantParams) {
+				t.Errorf("Hasher.HashParameters() = %v, want %v", got.Describe(), wantParams)
+			}
+			if got.Strength() != want.Strength() {
+				t.Errorf("Hasher.HashParameters() strength = %d, want %d", got.Strength(), want.Strength())
+			}
+		})
+	}
+}
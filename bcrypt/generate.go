@@ -0,0 +1,93 @@
+package bcrypt
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/zitadel/passwap/internal/salt"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/blowfish"
+)
+
+// saltLen is the length of the raw bcrypt salt.
+const saltLen = 16
+
+// encoding is the bcrypt base64 encoding, without padding.
+var encoding = base64.NewEncoding(alphabet).WithPadding(base64.NoPadding)
+
+// generateFromPassword returns the encoded bcrypt hash of password.
+// When r is nil, the hash is generated by bcrypt.GenerateFromPassword,
+// which reads the salt from crypto/rand.Reader.
+// Otherwise the salt is read from r and the hash is generated
+// by [generateWithSalt].
+// An error wrapping salt.ErrEntropy is returned when the
+// random source fails or returns a short read.
+func generateFromPassword(r io.Reader, password []byte, cost int) ([]byte, error) {
+	if r == nil {
+		encoded, err := bcrypt.GenerateFromPassword(password, cost)
+		if err != nil && !errors.Is(err, bcrypt.ErrPasswordTooLong) && !errors.As(err, new(bcrypt.InvalidCostError)) {
+			// All other errors stem from reading the salt.
+			return nil, fmt.Errorf("bcrypt: %w: %w", salt.ErrEntropy, err)
+		}
+		return encoded, err
+	}
+	s, err := salt.New(r, saltLen)
+	if err != nil {
+		return nil, fmt.Errorf("bcrypt: %w", err)
+	}
+	return generateWithSalt(password, cost, s)
+}
+
+// magicCipherData is "OrpheanBeholderScryDoubt",
+// which is encrypted 64 times by the expanded key.
+var magicCipherData = []byte("OrpheanBeholderScryDoubt")
+
+// generateWithSalt returns the `$2a$` encoded bcrypt hash of password
+// with rawSalt, which must be saltLen bytes.
+//
+// golang.org/x/crypto/bcrypt always reads the salt from crypto/rand.Reader,
+// so this is the only place where the bcrypt key setup is reimplemented.
+// It is used for [Hasher.HashWithSalt] and hashers with a custom random
+// source only, and tested to produce the same output as the bcrypt package.
+// Like the bcrypt package, passwords of more than 72 bytes are rejected
+// and a cost below MinCost is replaced by DefaultCost.
+func generateWithSalt(password []byte, cost int, rawSalt []byte) ([]byte, error) {
+	if len(password) > 72 {
+		return nil, bcrypt.ErrPasswordTooLong
+	}
+	if cost < MinCost {
+		cost = DefaultCost
+	}
+	if cost > MaxCost {
+		return nil, bcrypt.InvalidCostError(cost)
+	}
+
+	// Bug compatibility with C bcrypt implementations,
+	// which use the trailing NULL of the key string.
+	key := make([]byte, len(password)+1)
+	copy(key, password)
+
+	c, err := blowfish.NewSaltedCipher(key, rawSalt)
+	if err != nil {
+		return nil, fmt.Errorf("bcrypt: %w", err)
+	}
+	for i := uint64(0); i < 1<<cost; i++ {
+		blowfish.ExpandKey(key, c)
+		blowfish.ExpandKey(rawSalt, c)
+	}
+
+	cipherData := make([]byte, len(magicCipherData))
+	copy(cipherData, magicCipherData)
+	for i := 0; i < len(cipherData); i += blowfish.BlockSize {
+		for j := 0; j < 64; j++ {
+			c.Encrypt(cipherData[i:i+blowfish.BlockSize], cipherData[i:i+blowfish.BlockSize])
+		}
+	}
+
+	// Only 23 of the 24 encrypted bytes are encoded,
+	// for compatibility with C bcrypt implementations.
+	encoded := fmt.Sprintf("%sa$%02d$%s%s", Prefix, cost, encoding.EncodeToString(rawSalt), encoding.EncodeToString(cipherData[:23]))
+	return []byte(encoded), nil
+}
@@ -0,0 +1,68 @@
+package bcrypt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func Test_generateWithSalt(t *testing.T) {
+	// vectors of the OpenBSD bcrypt regression tests.
+	tests := []struct {
+		password string
+		want     string
+	}{
+		{"U*U", "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW"},
+		{"", "$2a$05$CCCCCCCCCCCCCCCCCCCCC.7uG0VCzI2bS7j6ymqJi9CdcdxiRTWNy"},
+		{"U*U*U", "$2a$05$XXXXXXXXXXXXXXXXXXXXXOAcXxm9kjPGEMsLznoKqmqw7tc8WCx4a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			rawSalt, err := encoding.DecodeString(tt.want[7:29])
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := generateWithSalt([]byte(tt.password), 5, rawSalt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("generateWithSalt() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("password too long", func(t *testing.T) {
+		_, err := generateWithSalt([]byte(strings.Repeat("a", 73)), 5, make([]byte, saltLen))
+		if !errors.Is(err, bcrypt.ErrPasswordTooLong) {
+			t.Errorf("generateWithSalt() error = %v, want %v", err, bcrypt.ErrPasswordTooLong)
+		}
+	})
+}
+
+// Test_generateWithSalt_upstream checks that generateWithSalt reproduces
+// hashes of golang.org/x/crypto/bcrypt from their salt.
+func Test_generateWithSalt_upstream(t *testing.T) {
+	passwords := []string{"", "password", "Pa$$w0rd äöü", strings.Repeat("x", 72)}
+	for _, cost := range []int{MinCost, MinCost + 1} {
+		for _, password := range passwords {
+			want, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rawSalt, err := encoding.DecodeString(string(want[7:29]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := generateWithSalt([]byte(password), cost, rawSalt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("generateWithSalt(%q, %d) =\n%s\nwant\n%s", password, cost, got, want)
+			}
+		}
+	}
+}
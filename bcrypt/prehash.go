@@ -0,0 +1,67 @@
+package bcrypt
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// Identifier and prefix of hashes of SHA-256 prehashed passwords,
+// in the bcrypt_sha256 format of passlib, for example
+// `$bcrypt-sha256$2a,12$n79VH.0Q2TMWmt3Oqt9uku$Kq4Noyk3094Y2QlB8NdRT8SvGiI4ft2`.
+// See https://passlib.readthedocs.io/en/stable/lib/passlib.hash.bcrypt_sha256.html
+const (
+	IdentifierSHA256 = "bcrypt-sha256"
+	PrefixSHA256     = "$" + IdentifierSHA256 + "$"
+)
+
+// WithPrehash makes the Hasher prehash passwords with SHA-256,
+// so that passwords longer than the 72 bytes used by bcrypt
+// are not truncated. Hashes are encoded in the bcrypt_sha256
+// format of passlib, see [PrefixSHA256].
+//
+// Plain bcrypt hashes are verified with NeedUpdate,
+// so that they are migrated to the prehashed format.
+func WithPrehash() Option {
+	return func(h *Hasher) {
+		h.prehash = true
+	}
+}
+
+// prehashPassword returns the key passed to bcrypt for password,
+// which is the standard base64 encoding of its SHA-256 sum.
+func prehashPassword(password string) []byte {
+	sum := sha256.Sum256([]byte(password))
+	return []byte(base64.StdEncoding.EncodeToString(sum[:]))
+}
+
+// encodePrehashed returns a bcrypt hash in the bcrypt_sha256 format.
+func encodePrehashed(encoded []byte) string {
+	salt := encoded[encodedLen-encodedSaltLen-encodedHashLen : encodedLen-encodedHashLen]
+	hash := encoded[encodedLen-encodedHashLen:]
+	return fmt.Sprintf("%s%s,%s$%s$%s", PrefixSHA256, encoded[1:3], encoded[4:6], salt, hash)
+}
+
+// decode parses encoded in the plain bcrypt or the bcrypt_sha256
+// format, as documented for parse. prehashed reports the latter,
+// for which normalized is the plain bcrypt form.
+func decode(encoded string) (normalized []byte, cost int, prehashed bool, err error) {
+	rest, ok := strings.CutPrefix(encoded, PrefixSHA256)
+	if !ok {
+		normalized, cost, err = parse([]byte(encoded))
+		return normalized, cost, false, err
+	}
+
+	// version,cost$salt$hash
+	fields := strings.Split(rest, "$")
+	if len(fields) != 3 || len(fields[1]) != encodedSaltLen {
+		return nil, 0, true, fmt.Errorf("bcrypt parse: %w: %s", ErrMalformed, IdentifierSHA256)
+	}
+	version, rounds, ok := strings.Cut(fields[0], ",")
+	if !ok || (version != "2a" && version != "2b") {
+		return nil, 0, true, fmt.Errorf("bcrypt parse: %w: %s version %q", ErrMalformed, IdentifierSHA256, fields[0])
+	}
+	normalized, cost, err = parse([]byte("$" + version + "$" + rounds + "$" + fields[1] + fields[2]))
+	return normalized, cost, true, err
+}
@@ -0,0 +1,91 @@
+package bcrypt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/verifier"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// longPassword is longer than the 72 bytes used by bcrypt.
+var longPassword = strings.Repeat("password", 10)
+
+func TestWithPrehash(t *testing.T) {
+	h := New(5, WithPrehash())
+
+	encoded, err := h.Hash(longPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encoded, PrefixSHA256+"2a,05$") {
+		t.Errorf("Hasher.Hash() = %s, want prefix %s", encoded, PrefixSHA256)
+	}
+
+	// Plain bcrypt truncates passwords after 72 bytes.
+	truncated, err := bcrypt.GenerateFromPassword([]byte(longPassword[:72]), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		h        *Hasher
+		encoded  string
+		password string
+		want     verifier.Result
+	}{
+		{"prehashed", h, encoded, longPassword, verifier.OK},
+		{"prehashed truncated", h, encoded, longPassword[:72], verifier.Fail},
+		{"test value", h, testvalues.EncodedBcryptSHA256, testvalues.Password, verifier.OK},
+		{"plain", h, testvalues.EncodedBcryptCost5, testvalues.Password, verifier.NeedUpdate},
+		{"plain truncated", h, string(truncated), longPassword, verifier.NeedUpdate},
+		{"plain wrong password", h, testvalues.EncodedBcryptCost5, "spanac", verifier.Fail},
+		{"prehashed without prehash", New(5), encoded, longPassword, verifier.NeedUpdate},
+		{"prehashed other cost", New(6, WithPrehash()), encoded, longPassword, verifier.NeedUpdate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.h.Verify(tt.encoded, tt.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Hasher.Verify() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if got, err := Verify(encoded, longPassword); got != verifier.OK || err != nil {
+		t.Errorf("Verify() = %s, %v, want %s", got, err, verifier.OK)
+	}
+	if _, needsUpdate, err := h.Inspect(testvalues.EncodedBcryptCost5); !needsUpdate || err != nil {
+		t.Errorf("Hasher.Inspect() = %t, %v, want needs update", needsUpdate, err)
+	}
+	params, err := Identify(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["prehash"] != "sha256" || params["cost"] != 5 {
+		t.Errorf("Identify() = %v, want prehash sha256 and cost 5", params)
+	}
+}
+
+func Test_decode_malformed(t *testing.T) {
+	for _, encoded := range []string{
+		PrefixSHA256 + "2a,05$ruJLFkvFR4ay6YGvOetJKuXJSAW27wMqId2VIm5YTpE1BXmQuAbBi",
+		PrefixSHA256 + "2a,05$ruJLFkvFR4ay6YGvOetJK$uXJSAW27wMqId2VIm5YTpE1BXmQuAbBi",
+		PrefixSHA256 + "2y,05$ruJLFkvFR4ay6YGvOetJKu$XJSAW27wMqId2VIm5YTpE1BXmQuAbBi",
+		PrefixSHA256 + "2a$ruJLFkvFR4ay6YGvOetJKu$XJSAW27wMqId2VIm5YTpE1BXmQuAbBi",
+		PrefixSHA256 + "2a,05$ruJLFkvFR4ay6YGvOetJKu$XJSAW27wMqId2VIm5YTpE1BXmQuAb",
+	} {
+		t.Run(encoded, func(t *testing.T) {
+			got, err := Verify(encoded, testvalues.Password)
+			if !errors.Is(err, ErrMalformed) || got != verifier.Skip {
+				t.Errorf("Verify() = %s, %v, want %s, %v", got, err, verifier.Skip, ErrMalformed)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package bcrypt
+
+import (
+	"errors"
+
+	"github.com/zitadel/passwap/verifier"
+)
+
+// ValidationOpts bound the cost of bcrypt hashes,
+// as checked by [Validate] and [Hasher.Validate].
+// Zero values are not checked.
+type ValidationOpts struct {
+	MinCost int
+	MaxCost int
+
+	// DisallowedCosts are rejected even when they are
+	// within MinCost and MaxCost, for example costs
+	// known to be too fast on available hardware.
+	DisallowedCosts []int
+}
+
+// check returns a BoundsError when cost is out of bounds
+// and a DisallowedError when it is disallowed.
+func (o ValidationOpts) check(cost int) error {
+	if err := verifier.CheckBounds(Algorithm, "cost", int64(cost), int64(o.MinCost), int64(o.MaxCost)); err != nil {
+		return err
+	}
+	disallowed := make([]int64, len(o.DisallowedCosts))
+	for i, c := range o.DisallowedCosts {
+		disallowed[i] = int64(c)
+	}
+	return verifier.CheckDisallowed(Algorithm, "cost", int64(cost), disallowed...)
+}
+
+// Validate parses encoded and checks its cost against opts.
+// A [verifier.BoundsError] or [verifier.DisallowedError]
+// is returned when the cost is rejected.
+func Validate(encoded string, opts ValidationOpts) error {
+	encodedB, cost, _, err := decode(encoded)
+	if err != nil {
+		return err
+	}
+	if encodedB == nil {
+		return errors.New("bcrypt validate: not a bcrypt hash")
+	}
+	return opts.check(cost)
+}
+
+// WithValidation sets the bounds used by [Hasher.Validate].
+// By default no bounds are checked.
+func WithValidation(opts ValidationOpts) Option {
+	return func(h *Hasher) {
+		h.vopts = opts
+	}
+}
+
+// Validate implements verifier.Validator.
+func (h *Hasher) Validate(encoded string) error {
+	return Validate(encoded, h.vopts)
+}
@@ -0,0 +1,108 @@
+package bcrypt
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/verifier"
+)
+
+// withCost returns tv.EncodedBcryptCost10 with its cost
+// replaced. The result is not a valid hash of any password,
+// but sufficient for validation.
+func withCost(cost int) string {
+	return strings.Replace(tv.EncodedBcryptCost10, "$10$", "$"+strconv.Itoa(cost)+"$", 1)
+}
+
+func TestValidate_disallowedCosts(t *testing.T) {
+	opts := ValidationOpts{
+		MinCost:         10,
+		MaxCost:         14,
+		DisallowedCosts: []int{10},
+	}
+
+	err := Validate(withCost(10), opts)
+	var target *verifier.DisallowedError
+	if !errors.As(err, &target) {
+		t.Fatalf("Validate() error = %v, want DisallowedError", err)
+	}
+	if target.Algorithm != Algorithm || target.Param != "cost" || target.Value != 10 {
+		t.Errorf("Validate() error = %#v", target)
+	}
+
+	for cost := 11; cost <= 14; cost++ {
+		if err := Validate(withCost(cost), opts); err != nil {
+			t.Errorf("Validate() cost %d error = %v", cost, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		encoded    string
+		opts       ValidationOpts
+		wantBounds bool
+		wantErr    bool
+	}{
+		{
+			name:    "unbounded",
+			encoded: tv.EncodedBcryptCost10,
+		},
+		{
+			name:       "below min",
+			encoded:    tv.EncodedBcryptCost5,
+			opts:       ValidationOpts{MinCost: 10},
+			wantBounds: true,
+			wantErr:    true,
+		},
+		{
+			name:       "above max",
+			encoded:    tv.EncodedBcrypt2b,
+			opts:       ValidationOpts{MaxCost: 11},
+			wantBounds: true,
+			wantErr:    true,
+		},
+		{
+			name:    "single digit cost",
+			encoded: tv.EncodedBcryptCost5SingleDigit,
+			opts:    ValidationOpts{MinCost: 4, DisallowedCosts: []int{10}},
+		},
+		{
+			name:    "malformed",
+			encoded: tv.EncodedBcryptCost10[:50],
+			wantErr: true,
+		},
+		{
+			name:    "not bcrypt",
+			encoded: tv.Argon2idEncoded,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.encoded, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var target *verifier.BoundsError
+			if errors.As(err, &target) != tt.wantBounds {
+				t.Errorf("Validate() error = %v, want BoundsError %t", err, tt.wantBounds)
+			}
+		})
+	}
+}
+
+func TestHasher_Validate(t *testing.T) {
+	if err := New(MinCost).Validate(tv.EncodedBcryptCost10); err != nil {
+		t.Errorf("Hasher.Validate() without bounds error = %v", err)
+	}
+	h := New(MinCost, WithValidation(ValidationOpts{DisallowedCosts: []int{10}}))
+	var target *verifier.DisallowedError
+	if err := h.Validate(tv.EncodedBcryptCost10); !errors.As(err, &target) {
+		t.Errorf("Hasher.Validate() error = %v, want DisallowedError", err)
+	}
+}
@@ -0,0 +1,136 @@
+package passwap
+
+import (
+	"container/list"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"sync"
+	"time"
+)
+
+// WithVerificationCache caches successful verifications of
+// [Swapper.Verify] and [Swapper.Matches] for ttl,
+// so that repeated verification of the same encoded hash and
+// password skips the key derivation. At most size entries
+// are kept, the least recently used entry is evicted first.
+// A size or ttl of 0 or less disables the cache.
+//
+// This is meant for services which verify the same secret
+// at a high rate, such as API keys checked on every request.
+// Entries are keyed by an HMAC of the encoded hash and password,
+// using a random key which only exists in memory,
+// so the cache never holds passwords or reusable digests of them.
+// Only matching passwords that don't require an update are cached.
+// Failed verifications are never cached, so guessing passwords
+// still costs a full key derivation for every guess.
+//
+// The tradeoff is that a cache hit returns much faster than a miss,
+// revealing that the pair was recently verified successfully,
+// and that a verified password stays valid for up to ttl after
+// its hash is replaced, for example when a password is changed.
+// Keep the ttl short and don't use the cache for interactive logins.
+func WithVerificationCache(size int, ttl time.Duration) Option {
+	return func(s *Swapper) {
+		if size <= 0 || ttl <= 0 {
+			s.cache = nil
+			return
+		}
+		s.cache = newVerifyCache(size, ttl)
+	}
+}
+
+type cacheKey [sha256.Size]byte
+
+type cacheEntry struct {
+	key     cacheKey
+	expires time.Time
+}
+
+// verifyCache is a size bounded LRU set of verified keys,
+// with expiry.
+type verifyCache struct {
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mtx     sync.Mutex
+	mac     hash.Hash
+	entries map[cacheKey]*list.Element
+	lru     *list.List
+}
+
+func newVerifyCache(size int, ttl time.Duration) *verifyCache {
+	secret := make([]byte, sha256.Size)
+	if _, err := rand.Read(secret); err != nil {
+		panic("passwap: verification cache key: " + err.Error())
+	}
+	return &verifyCache{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		mac:     hmac.New(sha256.New, secret),
+		entries: make(map[cacheKey]*list.Element, size),
+		lru:     list.New(),
+	}
+}
+
+// key returns the HMAC of encoded and password.
+// The length of encoded is prefixed, so that the
+// boundary between both can't be shifted.
+// The caller must hold the lock.
+func (c *verifyCache) key(encoded, password string) (key cacheKey) {
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(len(encoded)))
+
+	c.mac.Reset()
+	c.mac.Write(n[:])
+	c.mac.Write([]byte(encoded))
+	c.mac.Write([]byte(password))
+	c.mac.Sum(key[:0])
+	return key
+}
+
+// get reports if encoded and password were verified
+// before and the entry did not expire.
+func (c *verifyCache) get(encoded, password string) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	elem, ok := c.entries[c.key(encoded, password)]
+	if !ok {
+		return false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.lru.Remove(elem)
+		delete(c.entries, entry.key)
+		return false
+	}
+	c.lru.MoveToFront(elem)
+	return true
+}
+
+// add encoded and password as verified,
+// evicting the least recently used entry when full.
+func (c *verifyCache) add(encoded, password string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	key := c.key(encoded, password)
+	expires := c.now().Add(c.ttl)
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*cacheEntry).expires = expires
+		c.lru.MoveToFront(elem)
+		return
+	}
+	if c.lru.Len() >= c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, expires: expires})
+}
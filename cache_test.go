@@ -0,0 +1,122 @@
+package passwap
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/verifier"
+)
+
+// countingHasher counts the calls to Verify.
+type countingHasher struct {
+	Hasher
+	calls int
+}
+
+func (h *countingHasher) Verify(encoded, password string) (verifier.Result, error) {
+	h.calls++
+	return h.Hasher.Verify(encoded, password)
+}
+
+func TestWithVerificationCache(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		name      string
+		size      int
+		encoded   []string
+		password  string
+		advance   time.Duration
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "hit",
+			size:      2,
+			encoded:   []string{tv.Argon2idEncoded, tv.Argon2idEncoded},
+			password:  tv.Password,
+			wantCalls: 1,
+		},
+		{
+			name:      "wrong password not cached",
+			size:      2,
+			encoded:   []string{tv.Argon2idEncoded, tv.Argon2idEncoded},
+			password:  "foobar",
+			wantErr:   ErrPasswordMismatch,
+			wantCalls: 2,
+		},
+		{
+			name:      "update not cached",
+			size:      2,
+			encoded:   []string{tv.Argon2iEncoded, tv.Argon2iEncoded},
+			password:  tv.Password,
+			wantCalls: 2,
+		},
+		{
+			name:      "ttl expired",
+			size:      2,
+			encoded:   []string{tv.Argon2idEncoded, tv.Argon2idEncoded},
+			password:  tv.Password,
+			advance:   time.Minute,
+			wantCalls: 2,
+		},
+		{
+			name:      "within ttl",
+			size:      2,
+			encoded:   []string{tv.Argon2idEncoded, tv.Argon2idEncoded},
+			password:  tv.Password,
+			advance:   time.Minute - time.Second,
+			wantCalls: 1,
+		},
+		{
+			name:      "evicted",
+			size:      1,
+			encoded:   []string{tv.Argon2idEncoded, " " + tv.Argon2idEncoded, tv.Argon2idEncoded},
+			password:  tv.Password,
+			wantCalls: 3,
+		},
+		{
+			name:      "disabled",
+			size:      0,
+			encoded:   []string{tv.Argon2idEncoded, tv.Argon2idEncoded},
+			password:  tv.Password,
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &countingHasher{Hasher: testHasher}
+			s := NewSwapper(h).Apply(WithTrimSpace(), WithVerificationCache(tt.size, time.Minute))
+			if s.cache != nil {
+				s.cache.now = func() time.Time { return now }
+			}
+
+			for i, encoded := range tt.encoded {
+				if i == len(tt.encoded)-1 && s.cache != nil {
+					s.cache.now = func() time.Time { return now.Add(tt.advance) }
+				}
+				_, err := s.Verify(encoded, tt.password)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Swapper.Verify() error = %v, want %v", err, tt.wantErr)
+				}
+			}
+			if h.calls != tt.wantCalls {
+				t.Errorf("Hasher.Verify() calls = %d, want %d", h.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func Test_verifyCache_key(t *testing.T) {
+	c := newVerifyCache(1, time.Minute)
+	if c.key("ab", "c") == c.key("a", "bc") {
+		t.Error("verifyCache.key() equal for shifted boundary")
+	}
+	if c.key("a", "b") != c.key("a", "b") {
+		t.Error("verifyCache.key() not deterministic")
+	}
+	if newVerifyCache(1, time.Minute).key("a", "b") == c.key("a", "b") {
+		t.Error("verifyCache.key() equal for different caches")
+	}
+}
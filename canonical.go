@@ -0,0 +1,51 @@
+package passwap
+
+import "strings"
+
+// Canonicalize encodes a hash again in the form produced by
+// the Hasher of its algorithm, without changing the password
+// it verifies. This allows imported hashes to be normalized
+// in one pass. Surrounding whitespace is removed.
+//
+// Argon2, pbkdf2 and scrypt hashes are parsed, which returns
+// an error when they are malformed, and encoded again by
+// the Canonicalize function of their package.
+// Plain md5 digests are returned in lower case.
+// Other formats have a single encoding and are returned as is,
+// after they are parsed when their package provides an Identify function.
+// ErrNoVerifier is returned for formats which are not recognized,
+// including DES crypt hashes, which can't be told apart from other strings.
+func Canonicalize(encoded string) (string, error) {
+	encoded = strings.TrimSpace(encoded)
+
+	if a := algorithmFor(encoded); a != nil {
+		return a.canonical(encoded)
+	}
+	for _, a := range algorithms {
+		if len(a.prefixes) > 0 || a.canonicalize == nil {
+			continue
+		}
+		if canonical, err := a.canonicalize(encoded); err == nil {
+			return canonical, nil
+		}
+	}
+	return "", ErrNoVerifier
+}
+
+// canonical returns the canonical form of encoded,
+// which has one of the prefixes of a.
+func (a *algorithm) canonical(encoded string) (string, error) {
+	if a.canonicalize != nil {
+		return a.canonicalize(encoded)
+	}
+	if a.identify != nil {
+		params, err := a.identify(encoded)
+		if err != nil {
+			return "", err
+		}
+		if params == nil {
+			return "", ErrNoVerifier
+		}
+	}
+	return encoded, nil
+}
@@ -0,0 +1,154 @@
+package passwap
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zitadel/passwap/argon2"
+	"github.com/zitadel/passwap/bcrypt"
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/md5plain"
+)
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		want    string
+	}{
+		{
+			name:    "argon2 param order and padding",
+			encoded: `$argon2id$v=19$p=1,t=3,m=4096$cmFuZG9tc2FsdGlzaGFyZA==$DYojYpnUWSMmTtrkVXyaNWVGxLmGe1n8VJBPDdFkbjU=`,
+			want:    tv.Argon2idEncoded,
+		},
+		{
+			name:    "argon2 keyid and ts",
+			encoded: `$argon2id$v=19$ts=1700000000,keyid=abc,m=4096,p=1,t=3$cmFuZG9tc2FsdGlzaGFyZA$DYojYpnUWSMmTtrkVXyaNWVGxLmGe1n8VJBPDdFkbjU`,
+			want:    `$argon2id$v=19$m=4096,t=3,p=1,keyid=abc,ts=1700000000$cmFuZG9tc2FsdGlzaGFyZA$DYojYpnUWSMmTtrkVXyaNWVGxLmGe1n8VJBPDdFkbjU`,
+		},
+		{
+			name:    "pbkdf2 std padding",
+			encoded: tv.Pbkdf2Sha256StdEncodedPadding,
+			want:    tv.Pbkdf2Sha256Encoded,
+		},
+		{
+			name:    "pbkdf2 std",
+			encoded: tv.Pbkdf2Sha256StdEncoded,
+			want:    tv.Pbkdf2Sha256Encoded,
+		},
+		{
+			name:    "scrypt param order and whitespace",
+			encoded: " $scrypt$p=1,r=8,ln=16$cmFuZG9tc2FsdGlzaGFyZA$Rh+NnJNo1I6nRwaNqbDm6kmADswD1+7FTKZ7Ln9D8nQ\n",
+			want:    tv.ScryptEncoded,
+		},
+		{
+			name:    "scrypt sodium",
+			encoded: tv.ScryptSodiumEncoded,
+			want:    tv.ScryptSodiumEncoded,
+		},
+		{
+			name:    "canonical",
+			encoded: tv.Argon2idEncoded,
+			want:    tv.Argon2idEncoded,
+		},
+		{
+			name:    "other",
+			encoded: tv.EncodedBcrypt2b,
+			want:    tv.EncodedBcrypt2b,
+		},
+		{
+			name:    "md5plain",
+			encoded: strings.ToUpper(tv.MD5PlainHex),
+			want:    tv.MD5PlainHex,
+		},
+		{
+			name:    "md5plain prefix",
+			encoded: md5plain.Prefix + strings.ToUpper(tv.MD5PlainHex),
+			want:    md5plain.Prefix + tv.MD5PlainHex,
+		},
+		{
+			name:    "dovecot",
+			encoded: tv.DovecotSMD5,
+			want:    tv.DovecotSMD5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Canonicalize(tt.encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Canonicalize() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalize_hash(t *testing.T) {
+	s := NewSwapper(argon2.NewArgon2id(testArgon2Params), argon2.Verifier)
+	encoded, err := s.Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Canonicalize(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != encoded {
+		t.Errorf("Canonicalize() = %s, want %s", got, encoded)
+	}
+}
+
+func TestCanonicalize_error(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		wantErr error
+	}{
+		{
+			name:    "argon2 without version",
+			encoded: `$argon2i$m=4096,t=3,p=1$cmFuZG9tc2FsdGlzaGFyZA$DYojYpnUWSMmTtrkVXyaNWVGxLmGe1n8VJBPDdFkbjU`,
+			wantErr: argon2.ErrArgon2VersionUnsupported,
+		},
+		{
+			name:    "pbkdf2 malformed",
+			encoded: "$pbkdf2-sha256$12$foo",
+		},
+		{
+			name:    "scrypt malformed",
+			encoded: "$scrypt$ln=16$foo$bar",
+		},
+		{
+			name:    "bcrypt malformed",
+			encoded: "$2a$10$foo",
+			wantErr: bcrypt.ErrMalformed,
+		},
+		{
+			name:    "md5crypt malformed",
+			encoded: "$1$foo",
+		},
+		{
+			name:    "unknown",
+			encoded: "foobar",
+			wantErr: ErrNoVerifier,
+		},
+		{
+			name:    "empty",
+			encoded: " ",
+			wantErr: ErrNoVerifier,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Canonicalize(tt.encoded)
+			if err == nil {
+				t.Fatal("Canonicalize() expected error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Canonicalize() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
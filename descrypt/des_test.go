@@ -0,0 +1,35 @@
+package descrypt
+
+import (
+	"crypto/des"
+	"encoding/binary"
+	"testing"
+)
+
+// Test_encrypt compares the unsalted DES implementation
+// against the standard library.
+func Test_encrypt(t *testing.T) {
+	keys := []uint64{0, 0x133457799bbcdff1, 0xe0e0e0e0f1f1f1f1, 0xffffffffffffffff}
+	blocks := []uint64{0, 0x0123456789abcdef, 0xffffffffffffffff}
+	e := saltedExpansion(0)
+
+	for _, key := range keys {
+		var keyB [8]byte
+		binary.BigEndian.PutUint64(keyB[:], key)
+		cipher, err := des.NewCipher(keyB[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sk := subkeys(key)
+
+		for _, block := range blocks {
+			var in, want [8]byte
+			binary.BigEndian.PutUint64(in[:], block)
+			cipher.Encrypt(want[:], in[:])
+
+			if got := encrypt(block, &sk, &e); got != binary.BigEndian.Uint64(want[:]) {
+				t.Errorf("encrypt(%x, %x) = %x, want %x", block, key, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Package descrypt provides verification of traditional
+// DES based crypt(3) hashes, as found on ancient Unix systems.
+//
+// Such hashes are 13 characters long and have no identifier:
+// 2 characters of salt followed by 11 characters of checksum,
+// for example `abJnggxhB/yWI`.
+//
+// Note that DES crypt is severely broken and should never be
+// used for new applications. Only the first 8 characters of a
+// password are used and only 7 bits of each character,
+// resulting in a 56-bit key. The 12-bit salt is too small to
+// prevent pre-computation attacks.
+// Therefore passwap only supports verification,
+// to allow applications to migrate to better methods.
+package descrypt
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/zitadel/passwap/verifier"
+)
+
+const (
+	// Encoding is the character set used for encoding salt and checksum.
+	Encoding = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+	// EncodedLen is the length of a DES crypt hash,
+	// including the salt.
+	EncodedLen = 13
+
+	// MaxPasswordLen is the amount of password characters
+	// used by DES crypt. Any characters beyond are ignored.
+	MaxPasswordLen = 8
+
+	saltLen = 2
+	rounds  = 25
+)
+
+// checksum implements the DES based crypt(3) algorithm.
+// The key is made of the first 8 bytes of password,
+// each shifted left by one bit, dropping the most significant bit.
+// A zero block is encrypted 25 times with DES, of which the
+// expansion table is modified by salt.
+func checksum(password []byte, salt uint32) []byte {
+	var key uint64
+	for i := 0; i < MaxPasswordLen; i++ {
+		key <<= 8
+		if i < len(password) {
+			key |= uint64(password[i]<<1) & 0xff
+		}
+	}
+
+	keys := subkeys(key)
+	e := saltedExpansion(salt)
+
+	var block uint64
+	for i := 0; i < rounds; i++ {
+		block = encrypt(block, &keys, &e)
+	}
+
+	// 64 bits are encoded in 11 characters of 6 bits,
+	// the last one is padded with 2 zero bits.
+	out := make([]byte, EncodedLen-saltLen)
+	for i := 0; i < len(out)-1; i++ {
+		out[i] = Encoding[block>>(58-6*i)&0x3f]
+	}
+	out[len(out)-1] = Encoding[block<<2&0x3f]
+
+	return out
+}
+
+// parse returns the salt and checksum of encoded.
+// ok is false when encoded is not shaped like a DES crypt hash.
+func parse(encoded string) (salt uint32, sum []byte, ok bool) {
+	if len(encoded) != EncodedLen {
+		return 0, nil, false
+	}
+	for i := 0; i < len(encoded); i++ {
+		v := strings.IndexByte(Encoding, encoded[i])
+		if v < 0 {
+			return 0, nil, false
+		}
+		if i < saltLen {
+			salt |= uint32(v) << (6 * i)
+		}
+	}
+	return salt, []byte(encoded[saltLen:]), true
+}
+
+// truncate password at the first NUL byte,
+// as crypt(3) takes C strings.
+func truncate(password string) []byte {
+	if i := strings.IndexByte(password, 0); i >= 0 {
+		password = password[:i]
+	}
+	return []byte(password)
+}
+
+// Verify a traditional DES crypt hash.
+// Skip is returned when encoded is not exactly 13
+// characters from the crypt(3) alphabet.
+//
+// Note that DES crypt hashes do not have an identifier.
+// Therefore it might be that Verify accepts any 13
+// character string of the alphabet, but fails password verification.
+func Verify(encoded, password string) (verifier.Result, error) {
+	salt, sum, ok := parse(encoded)
+	if !ok {
+		return verifier.Skip, nil
+	}
+	res := subtle.ConstantTimeCompare(checksum(truncate(password), salt), sum)
+
+	return verifier.Result(res), nil
+}
+
+// Algorithm is the name reported by the Verifier,
+// see verifier.Named.
+const Algorithm = "descrypt"
+
+// Verifier for DES crypt.
+var Verifier = &verifier.NamedFunc{Name: Algorithm, VerifyFunc: Verify}
+
+// Prefixes returns nil, as DES crypt hashes have no prefix,
+// see verifier.Prefixed.
+func Prefixes() []string {
+	return nil
+}
+
+// SelfTestVector returns a fixed password and its encoded hash,
+// which [Verify] must accept. It guards against regressions
+// of the encoding format, see passwap.SelfTest.
+func SelfTestVector() (password, encoded string) {
+	return "password", `abJnggxhB/yWI`
+}
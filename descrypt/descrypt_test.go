@@ -0,0 +1,138 @@
+package descrypt
+
+import (
+	"reflect"
+	"testing"
+
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/verifier"
+)
+
+func Test_parse(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoded  string
+		wantSalt uint32
+		wantSum  []byte
+		wantOK   bool
+	}{
+		{
+			name:    "too short",
+			encoded: tv.DESCryptEncoded[:12],
+		},
+		{
+			name:    "too long",
+			encoded: tv.DESCryptEncoded + "a",
+		},
+		{
+			name:    "outside alphabet",
+			encoded: "ab$nggxhB/yWI",
+		},
+		{
+			name:    "other format",
+			encoded: tv.MD5Encoded,
+		},
+		{
+			name:     "zero salt",
+			encoded:  tv.DESCryptEncodedNoSalt,
+			wantSalt: 0,
+			wantSum:  []byte(tv.DESCryptEncodedNoSalt[2:]),
+			wantOK:   true,
+		},
+		{
+			name:     "salt",
+			encoded:  tv.DESCryptEncoded,
+			wantSalt: 38 | 39<<6,
+			wantSum:  []byte(tv.DESCryptEncoded[2:]),
+			wantOK:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSalt, gotSum, gotOK := parse(tt.encoded)
+			if gotOK != tt.wantOK {
+				t.Fatalf("parse() ok = %v, want %v", gotOK, tt.wantOK)
+			}
+			if gotSalt != tt.wantSalt {
+				t.Errorf("parse() salt = %v, want %v", gotSalt, tt.wantSalt)
+			}
+			if !reflect.DeepEqual(gotSum, tt.wantSum) {
+				t.Errorf("parse() sum = %s, want %s", gotSum, tt.wantSum)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	type args struct {
+		encoded  string
+		password string
+	}
+	tests := []struct {
+		name string
+		args args
+		want verifier.Result
+	}{
+		{
+			name: "skip",
+			args: args{tv.Argon2idEncoded, tv.Password},
+			want: verifier.Skip,
+		},
+		{
+			name: "wrong password",
+			args: args{tv.DESCryptEncoded, "foobar"},
+			want: verifier.Fail,
+		},
+		{
+			name: "success",
+			args: args{tv.DESCryptEncoded, tv.Password},
+			want: verifier.OK,
+		},
+		{
+			name: "truncated password",
+			args: args{tv.DESCryptEncoded, tv.Password + "123"},
+			want: verifier.OK,
+		},
+		{
+			name: "truncated at NUL",
+			args: args{tv.DESCryptEncodedTest, "test\x00foo"},
+			want: verifier.OK,
+		},
+		{
+			name: "empty password",
+			args: args{tv.DESCryptEncodedEmpty, ""},
+			want: verifier.OK,
+		},
+		{
+			name: "zero salt",
+			args: args{tv.DESCryptEncodedNoSalt, tv.Password},
+			want: verifier.OK,
+		},
+		{
+			name: "high salt",
+			args: args{tv.DESCryptEncodedHighSalt, tv.Password},
+			want: verifier.OK,
+		},
+		{
+			name: "8-bit characters",
+			args: args{tv.DESCryptEncodedUTF8, "pässwörd"},
+			want: verifier.OK,
+		},
+		{
+			name: "test",
+			args: args{tv.DESCryptEncodedTest, "test"},
+			want: verifier.OK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Verify(tt.args.encoded, tt.args.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
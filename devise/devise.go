@@ -0,0 +1,79 @@
+// Package devise provides verification of bcrypt hashes
+// created by the Devise authentication library for Ruby on Rails,
+// when configured with a pepper.
+//
+// Devise appends the pepper to the password before hashing,
+// storing `bcrypt(password + pepper)` in the encrypted_password column.
+// Without a pepper, Devise hashes are plain bcrypt
+// and can be verified with the bcrypt package.
+package devise
+
+import (
+	"github.com/zitadel/passwap/bcrypt"
+	"github.com/zitadel/passwap/verifier"
+)
+
+// Algorithm is the name reported by the Verifier,
+// see verifier.Named.
+const Algorithm = "devise"
+
+// Verifier for Devise bcrypt hashes with a pepper.
+type Verifier struct {
+	// peppers to try, the current one first.
+	peppers []string
+}
+
+// New returns a Verifier for hashes created with pepper,
+// as configured by `config.pepper` in the Devise initializer.
+func New(pepper string) *Verifier {
+	return &Verifier{peppers: []string{pepper}}
+}
+
+// NewRotated returns a Verifier for hashes created with
+// the current pepper or any of the retired peppers,
+// for the time a pepper is rotated.
+//
+// Devise hashes don't record which pepper was used,
+// so the current pepper is tried first, followed by the
+// retired peppers in order. Each attempt costs a full bcrypt
+// verification. Hashes matching a retired pepper are verified
+// with NeedUpdate, so that they are updated even when
+// the Verifier is considered current by the Swapper.
+func NewRotated(current string, retired ...string) *Verifier {
+	peppers := make([]string, 0, len(retired)+1)
+	peppers = append(peppers, current)
+	return &Verifier{peppers: append(peppers, retired...)}
+}
+
+// Algorithm implements verifier.Named.
+func (v *Verifier) Algorithm() string {
+	return Algorithm
+}
+
+// HashPrefixes implements verifier.Prefixed.
+func (v *Verifier) HashPrefixes() []string {
+	return bcrypt.Prefixes()
+}
+
+// Prefixes returns the prefixes of the hashes handled by this package,
+// which are those of bcrypt, see verifier.Prefixed.
+func Prefixes() []string {
+	return bcrypt.Prefixes()
+}
+
+// Verify implements verifier.Verifier.
+// Skip is returned for encoded strings which are not bcrypt hashes.
+// NeedUpdate is returned when the password matches
+// with a retired pepper, see [NewRotated].
+func (v *Verifier) Verify(encoded, password string) (verifier.Result, error) {
+	for i, pepper := range v.peppers {
+		result, err := bcrypt.Verify(encoded, password+pepper)
+		if result != verifier.Fail || err != nil {
+			if result == verifier.OK && i > 0 {
+				return verifier.NeedUpdate, nil
+			}
+			return result, err
+		}
+	}
+	return verifier.Fail, nil
+}
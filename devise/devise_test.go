@@ -0,0 +1,128 @@
+package devise
+
+import (
+	"testing"
+
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/verifier"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	tests := []struct {
+		name     string
+		pepper   string
+		encoded  string
+		password string
+		want     verifier.Result
+		wantErr  bool
+	}{
+		{
+			name:     "not bcrypt",
+			pepper:   tv.DevisePepper,
+			encoded:  tv.Argon2idEncoded,
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "wrong pepper",
+			pepper:   "foobar",
+			encoded:  tv.DeviseEncoded,
+			password: tv.Password,
+			want:     verifier.Fail,
+		},
+		{
+			name:     "no pepper",
+			encoded:  tv.DeviseEncoded,
+			password: tv.Password,
+			want:     verifier.Fail,
+		},
+		{
+			name:     "wrong password",
+			pepper:   tv.DevisePepper,
+			encoded:  tv.DeviseEncoded,
+			password: "foobar",
+			want:     verifier.Fail,
+		},
+		{
+			name:     "success",
+			pepper:   tv.DevisePepper,
+			encoded:  tv.DeviseEncoded,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "plain bcrypt without pepper",
+			encoded:  tv.EncodedBcrypt2b,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.pepper).Verify(tt.encoded, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verifier.Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Verifier.Verify() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRotated(t *testing.T) {
+	tests := []struct {
+		name     string
+		v        *Verifier
+		encoded  string
+		password string
+		want     verifier.Result
+	}{
+		{
+			name:     "current pepper",
+			v:        NewRotated(tv.DevisePepper, "retired"),
+			encoded:  tv.DeviseEncoded,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "retired pepper",
+			v:        NewRotated("current", "older", tv.DevisePepper),
+			encoded:  tv.DeviseEncoded,
+			password: tv.Password,
+			want:     verifier.NeedUpdate,
+		},
+		{
+			name:     "unknown pepper",
+			v:        NewRotated("current", "retired"),
+			encoded:  tv.DeviseEncoded,
+			password: tv.Password,
+			want:     verifier.Fail,
+		},
+		{
+			name:     "wrong password",
+			v:        NewRotated("current", tv.DevisePepper),
+			encoded:  tv.DeviseEncoded,
+			password: "foobar",
+			want:     verifier.Fail,
+		},
+		{
+			name:     "not bcrypt",
+			v:        NewRotated("current", tv.DevisePepper),
+			encoded:  tv.Argon2idEncoded,
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.v.Verify(tt.encoded, tt.password)
+			if err != nil {
+				t.Errorf("Verifier.Verify() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Verifier.Verify() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
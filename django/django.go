@@ -0,0 +1,72 @@
+// Package django provides verification of password hashes
+// created by the Django web framework, for the formats which
+// wrap a hash of another package.
+//
+// Django prefixes each hash with the name of its hasher and a `$`.
+// The following hashers are supported:
+//
+//   - bcrypt_sha256: `bcrypt_sha256$$2b$...`, bcrypt of the hex encoded
+//     SHA-256 digest of the password.
+//   - bcrypt: `bcrypt$$2b$...`, plain bcrypt.
+//   - argon2: `argon2$argon2id$v=19$...`, argon2 in the PHC string format.
+//
+// Other Django hashers, such as the default pbkdf2_sha256,
+// are not supported and result in Skip.
+package django
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/zitadel/passwap/argon2"
+	"github.com/zitadel/passwap/bcrypt"
+	"github.com/zitadel/passwap/verifier"
+)
+
+// Names of the supported Django hashers,
+// as used in the prefix of encoded hashes.
+const (
+	HasherBcryptSHA256 = "bcrypt_sha256"
+	HasherBcrypt       = "bcrypt"
+	HasherArgon2       = "argon2"
+)
+
+// Algorithm is the name reported by the Verifier,
+// see verifier.Named.
+const Algorithm = "django"
+
+// Verify parses encoded and verifies password against the
+// wrapped hash. Skip is returned for encoded strings
+// without a supported Django hasher prefix.
+func Verify(encoded, password string) (verifier.Result, error) {
+	hasher, wrapped, ok := strings.Cut(encoded, "$")
+	if !ok {
+		return verifier.Skip, nil
+	}
+
+	switch hasher {
+	case HasherBcryptSHA256:
+		sum := sha256.Sum256([]byte(password))
+		return bcrypt.Verify(wrapped, hex.EncodeToString(sum[:]))
+	case HasherBcrypt:
+		return bcrypt.Verify(wrapped, password)
+	case HasherArgon2:
+		// Django stores argon2 hashes without the leading `$`.
+		return argon2.Verify("$"+wrapped, password)
+	default:
+		return verifier.Skip, nil
+	}
+}
+
+// prefixes recognized by the Verifier.
+var prefixes = []string{HasherBcryptSHA256 + "$", HasherBcrypt + "$", HasherArgon2 + "$"}
+
+// Verifier for Django hashes.
+var Verifier = &verifier.NamedFunc{Name: Algorithm, VerifyFunc: Verify, Prefixes: prefixes}
+
+// Prefixes returns the prefixes of the hashes handled by this package,
+// as recognized by the Verifier, see verifier.Prefixed.
+func Prefixes() []string {
+	return append([]string(nil), prefixes...)
+}
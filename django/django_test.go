@@ -0,0 +1,97 @@
+package django
+
+import (
+	"testing"
+
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/verifier"
+)
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoded  string
+		password string
+		want     verifier.Result
+		wantErr  bool
+	}{
+		{
+			name:     "bcrypt_sha256",
+			encoded:  tv.DjangoBcryptSHA256Encoded,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "bcrypt_sha256 wrong password",
+			encoded:  tv.DjangoBcryptSHA256Encoded,
+			password: "foobar",
+			want:     verifier.Fail,
+		},
+		{
+			name:     "bcrypt_sha256 without prehash",
+			encoded:  HasherBcryptSHA256 + "$" + tv.EncodedBcrypt2b,
+			password: tv.Password,
+			want:     verifier.Fail,
+		},
+		{
+			name:     "bcrypt",
+			encoded:  tv.DjangoBcryptEncoded,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "argon2",
+			encoded:  tv.DjangoArgon2Encoded,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "argon2 wrong password",
+			encoded:  tv.DjangoArgon2Encoded,
+			password: "foobar",
+			want:     verifier.Fail,
+		},
+		{
+			name:     "argon2 parse error",
+			encoded:  "argon2$argon2id$v=19$foo",
+			password: tv.Password,
+			want:     verifier.Skip,
+			wantErr:  true,
+		},
+		{
+			name:     "unsupported hasher",
+			encoded:  "pbkdf2_sha256$260000$c2FsdA$aGFzaA==",
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "unwrapped bcrypt",
+			encoded:  tv.EncodedBcrypt2b,
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "unwrapped argon2",
+			encoded:  tv.Argon2idEncoded,
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "no separator",
+			encoded:  "foobar",
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Verify(tt.encoded, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
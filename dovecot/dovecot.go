@@ -0,0 +1,193 @@
+// Package dovecot provides verification of password hashes
+// in the `{SCHEME}` prefixed format, as used by Dovecot and Postfix.
+// See https://doc.dovecot.org/configuration_manual/authentication/password_schemes/.
+//
+// Crypt based schemes, like `{BLF-CRYPT}` or `{MD5-CRYPT}`, delegate
+// to the verifiers of the corresponding passwap packages.
+// Digest based schemes, like `{SSHA}` or `{PLAIN-MD5}`, are verified
+// by this package.
+// The encoding of the stored value can be overridden by a `.HEX`,
+// `.B64` or `.BASE64` suffix on the scheme name, for example
+// `{SSHA256.HEX}`.
+//
+// Many of the supported schemes are cryptographically weak.
+// This package is only provided to allow migration of mail server
+// accounts to better methods.
+package dovecot
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/zitadel/passwap/argon2"
+	"github.com/zitadel/passwap/bcrypt"
+	"github.com/zitadel/passwap/descrypt"
+	md5crypt "github.com/zitadel/passwap/md5"
+	"github.com/zitadel/passwap/sha1crypt"
+	"github.com/zitadel/passwap/verifier"
+)
+
+type encoding int
+
+const (
+	encodingNone encoding = iota
+	encodingHex
+	encodingBase64
+)
+
+func (e encoding) decode(value string) ([]byte, error) {
+	switch e {
+	case encodingHex:
+		return hex.DecodeString(value)
+	case encodingBase64:
+		return base64.StdEncoding.DecodeString(value)
+	default:
+		return []byte(value), nil
+	}
+}
+
+type scheme struct {
+	enc    encoding
+	verify func(raw []byte, password string) (verifier.Result, error)
+}
+
+// crypt verifies raw as a crypt(3) string using the first
+// of verifiers that does not Skip.
+func crypt(verifiers ...verifier.Verifier) func([]byte, string) (verifier.Result, error) {
+	return func(raw []byte, password string) (verifier.Result, error) {
+		for _, v := range verifiers {
+			if res, err := v.Verify(string(raw), password); res != verifier.Skip {
+				return res, err
+			}
+		}
+		return verifier.Skip, nil
+	}
+}
+
+// digest verifies raw as the output of hf over the password,
+// with an optional salt appended to both password and raw.
+func digest(hf func() hash.Hash, salted bool) func([]byte, string) (verifier.Result, error) {
+	return func(raw []byte, password string) (verifier.Result, error) {
+		h := hf()
+		size := h.Size()
+		if len(raw) < size || (!salted && len(raw) != size) {
+			return verifier.Skip, fmt.Errorf("dovecot: digest length %d, want %d", len(raw), size)
+		}
+
+		h.Write([]byte(password))
+		h.Write(raw[size:])
+
+		return verifier.Result(subtle.ConstantTimeCompare(h.Sum(nil), raw[:size])), nil
+	}
+}
+
+func plain(raw []byte, password string) (verifier.Result, error) {
+	return verifier.Result(subtle.ConstantTimeCompare(raw, []byte(password))), nil
+}
+
+// schemes by upper case name.
+var schemes = map[string]scheme{
+	"CRYPT":     {encodingNone, crypt(bcrypt.Verifier, md5crypt.Verifier, sha1crypt.Verifier, descrypt.Verifier)},
+	"DES-CRYPT": {encodingNone, crypt(descrypt.Verifier)},
+	"MD5":       {encodingNone, crypt(md5crypt.Verifier)},
+	"MD5-CRYPT": {encodingNone, crypt(md5crypt.Verifier)},
+	"BLF-CRYPT": {encodingNone, crypt(bcrypt.Verifier)},
+	"ARGON2I":   {encodingNone, crypt(argon2.Verifier)},
+	"ARGON2ID":  {encodingNone, crypt(argon2.Verifier)},
+
+	"PLAIN-MD5": {encodingHex, digest(md5.New, false)},
+	"LDAP-MD5":  {encodingBase64, digest(md5.New, false)},
+	"SMD5":      {encodingBase64, digest(md5.New, true)},
+	"SHA":       {encodingBase64, digest(sha1.New, false)},
+	"SHA1":      {encodingBase64, digest(sha1.New, false)},
+	"SSHA":      {encodingBase64, digest(sha1.New, true)},
+	"SHA256":    {encodingBase64, digest(sha256.New, false)},
+	"SSHA256":   {encodingBase64, digest(sha256.New, true)},
+	"SHA512":    {encodingBase64, digest(sha512.New, false)},
+	"SSHA512":   {encodingBase64, digest(sha512.New, true)},
+
+	"PLAIN":     {encodingNone, plain},
+	"CLEARTEXT": {encodingNone, plain},
+}
+
+// parse splits encoded into its scheme and decoded value.
+// ok is false when encoded has no known scheme.
+func parse(encoded string) (s scheme, raw []byte, ok bool, err error) {
+	if !strings.HasPrefix(encoded, "{") {
+		return scheme{}, nil, false, nil
+	}
+	name, value, found := strings.Cut(encoded[1:], "}")
+	if !found {
+		return scheme{}, nil, false, nil
+	}
+	name = strings.ToUpper(name)
+
+	enc, hasEnc := encodingNone, false
+	if base, suffix, found := strings.Cut(name, "."); found {
+		switch suffix {
+		case "HEX":
+			enc, hasEnc = encodingHex, true
+		case "B64", "BASE64":
+			enc, hasEnc = encodingBase64, true
+		default:
+			return scheme{}, nil, false, nil
+		}
+		name = base
+	}
+
+	s, ok = schemes[name]
+	if !ok {
+		return scheme{}, nil, false, nil
+	}
+	if !hasEnc {
+		enc = s.enc
+	}
+	raw, err = enc.decode(value)
+	if err != nil {
+		return scheme{}, nil, true, fmt.Errorf("dovecot parse %s: %w", name, err)
+	}
+	return s, raw, true, nil
+}
+
+// Verify a password against a Dovecot scheme prefixed hash.
+// Skip is returned for unknown or unsupported schemes,
+// such as `{SHA512-CRYPT}`.
+func Verify(encoded, password string) (verifier.Result, error) {
+	s, raw, ok, err := parse(encoded)
+	if err != nil || !ok {
+		return verifier.Skip, err
+	}
+	return s.verify(raw, password)
+}
+
+// Algorithm is the name reported by the Verifier,
+// see verifier.Named.
+const Algorithm = "dovecot"
+
+// prefixes recognized by the Verifier,
+// as every scheme is enclosed in curly braces.
+var prefixes = []string{"{"}
+
+// Verifier for Dovecot schemes.
+var Verifier = &verifier.NamedFunc{Name: Algorithm, VerifyFunc: Verify, Prefixes: prefixes}
+
+// Prefixes returns the prefixes of the hashes handled by this package,
+// as recognized by the Verifier, see verifier.Prefixed.
+func Prefixes() []string {
+	return append([]string(nil), prefixes...)
+}
+
+// SelfTestVector returns a fixed password and its encoded hash,
+// which [Verify] must accept. It guards against regressions
+// of the encoding format, see passwap.SelfTest.
+func SelfTestVector() (password, encoded string) {
+	return "password", `{SSHA256}DIzeh0gCRMTRu9dAH3C3rr7fWkRT0Bp2ZdtRqvTX3XJzYWx0c2FsdA==`
+}
@@ -0,0 +1,68 @@
+package dovecot
+
+import (
+	"encoding/base64"
+	"testing"
+
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/verifier"
+)
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoded  string
+		password string
+		want     verifier.Result
+		wantErr  bool
+	}{
+		{"no scheme", tv.MD5Encoded, tv.Password, verifier.Skip, false},
+		{"unterminated scheme", "{SHA" + tv.MD5Encoded, tv.Password, verifier.Skip, false},
+		{"unknown scheme", "{FOO}bar", tv.Password, verifier.Skip, false},
+		{"unsupported scheme", "{SHA512-CRYPT}$6$foo$bar", tv.Password, verifier.Skip, false},
+		{"unknown suffix", "{SHA.FOO}W6ph5Mm5Pz8GgiULbPgzG37mj9g=", tv.Password, verifier.Skip, false},
+		{"decode error", "{SHA}!!!", tv.Password, verifier.Skip, true},
+		{"digest length", "{SHA}" + base64.StdEncoding.EncodeToString([]byte("short")), tv.Password, verifier.Skip, true},
+
+		{"CRYPT md5", "{CRYPT}" + tv.MD5Encoded, tv.Password, verifier.OK, false},
+		{"CRYPT bcrypt", "{CRYPT}" + tv.EncodedBcrypt2y, tv.Password, verifier.OK, false},
+		{"CRYPT des", "{CRYPT}" + tv.DESCryptEncoded, tv.Password, verifier.OK, false},
+		{"CRYPT unsupported", "{CRYPT}$6$foo$bar", tv.Password, verifier.Skip, false},
+		{"MD5-CRYPT", "{MD5-CRYPT}" + tv.MD5Encoded, tv.Password, verifier.OK, false},
+		{"MD5-CRYPT wrong password", "{MD5-CRYPT}" + tv.MD5Encoded, "foobar", verifier.Fail, false},
+		{"BLF-CRYPT", "{BLF-CRYPT}" + tv.EncodedBcrypt2y, tv.Password, verifier.OK, false},
+		{"BLF-CRYPT.B64", "{BLF-CRYPT.B64}" + base64.StdEncoding.EncodeToString([]byte(tv.EncodedBcrypt2y)), tv.Password, verifier.OK, false},
+		{"ARGON2ID", "{ARGON2ID}" + tv.Argon2idEncoded, tv.Password, verifier.OK, false},
+		{"lower case", "{md5-crypt}" + tv.MD5Encoded, tv.Password, verifier.OK, false},
+
+		{"PLAIN-MD5", "{PLAIN-MD5}" + tv.MD5PlainHex, tv.Password, verifier.OK, false},
+		{"PLAIN-MD5.b64", tv.DovecotPlainMD5B64, tv.Password, verifier.OK, false},
+		{"LDAP-MD5", "{LDAP-MD5}X03MO1qnZdYdgyfeuILPmQ==", tv.Password, verifier.OK, false},
+		{"SMD5", tv.DovecotSMD5, tv.Password, verifier.OK, false},
+		{"SHA", tv.DovecotSHA, tv.Password, verifier.OK, false},
+		{"SHA wrong password", tv.DovecotSHA, "foobar", verifier.Fail, false},
+		{"SSHA", tv.DovecotSSHA, tv.Password, verifier.OK, false},
+		{"SSHA wrong password", tv.DovecotSSHA, "foobar", verifier.Fail, false},
+		{"SSHA.HEX", tv.DovecotSSHAHex, tv.Password, verifier.OK, false},
+		{"SHA256", tv.DovecotSHA256, tv.Password, verifier.OK, false},
+		{"SSHA256", tv.DovecotSSHA256, tv.Password, verifier.OK, false},
+		{"SHA512", tv.DovecotSHA512, tv.Password, verifier.OK, false},
+		{"SSHA512", tv.DovecotSSHA512, tv.Password, verifier.OK, false},
+
+		{"PLAIN", "{PLAIN}" + tv.Password, tv.Password, verifier.OK, false},
+		{"PLAIN wrong password", "{PLAIN}" + tv.Password, "foobar", verifier.Fail, false},
+		{"CLEARTEXT.B64", "{CLEARTEXT.B64}" + base64.StdEncoding.EncodeToString([]byte(tv.Password)), tv.Password, verifier.OK, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Verify(tt.encoded, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package passwap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// ErrFingerprintEmpty is returned by [Fingerprint]
+// for an empty encoded string.
+var ErrFingerprintEmpty = errors.New("passwap: cannot fingerprint empty encoded string")
+
+// Fingerprint returns a hex encoded SHA-256 of the canonical
+// form of encoded. It can be used to find identical stored hashes,
+// for example for analytics, without exposing the hashes themselves.
+//
+// Encodings which only differ in surrounding whitespace,
+// base64 alphabet or padding or parameter order
+// result in the same fingerprint, see [Canonicalize].
+// Errors of Canonicalize are returned, such as ErrNoVerifier
+// for unknown formats, so that garbage is not fingerprinted.
+func Fingerprint(encoded string) (string, error) {
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return "", ErrFingerprintEmpty
+	}
+	canonical, err := Canonicalize(encoded)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return hex.EncodeToString(sum[:]), nil
+}
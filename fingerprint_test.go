@@ -0,0 +1,61 @@
+package passwap
+
+import (
+	"errors"
+	"testing"
+
+	tv "github.com/zitadel/passwap/internal/testvalues"
+)
+
+func TestFingerprint(t *testing.T) {
+	want, err := Fingerprint(tv.Pbkdf2Sha256Encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(want) != 64 {
+		t.Errorf("Fingerprint() = %q, want 64 hex characters", want)
+	}
+
+	same := []string{
+		tv.Pbkdf2Sha256StdEncoded,
+		tv.Pbkdf2Sha256StdEncodedPadding,
+		" " + tv.Pbkdf2Sha256Encoded + "\n",
+	}
+	for _, encoded := range same {
+		got, err := Fingerprint(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("Fingerprint(%q) = %q, want %q", encoded, got, want)
+		}
+	}
+
+	different := []string{
+		tv.Pbkdf2Sha1Encoded,
+		tv.Pbkdf2Sha512Encoded,
+		tv.Argon2idEncoded,
+		tv.EncodedBcrypt2b,
+	}
+	for _, encoded := range different {
+		got, err := Fingerprint(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got == want {
+			t.Errorf("Fingerprint(%q) = %q, want different fingerprint", encoded, got)
+		}
+	}
+}
+
+func TestFingerprint_error(t *testing.T) {
+	if _, err := Fingerprint(" "); !errors.Is(err, ErrFingerprintEmpty) {
+		t.Errorf("Fingerprint() error = %v, want %v", err, ErrFingerprintEmpty)
+	}
+	if _, err := Fingerprint("$pbkdf2-sha256$12$foo"); err == nil {
+		t.Error("Fingerprint() expected parse error")
+	}
+	if _, err := Fingerprint("foobar"); !errors.Is(err, ErrNoVerifier) {
+		t.Errorf("Fingerprint() error = %v, want %v", err, ErrNoVerifier)
+	}
+}
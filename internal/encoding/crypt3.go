@@ -0,0 +1,77 @@
+package encoding
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Crypt3 is the character set of the hash64 encoding,
+// as used by crypt(3) implementations like md5-crypt and sha1-crypt.
+const Crypt3 = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// EncodeCrypt3 encodes raw using the Crypt3 character set.
+// Bytes are consumed little-endian, 6 bits at a time.
+// https://passlib.readthedocs.io/en/stable/lib/passlib.utils.binary.html#passlib.utils.binary.h64
+func EncodeCrypt3(raw []byte) []byte {
+	return EncodeWithAlphabet(raw, Crypt3)
+}
+
+// EncodeWithAlphabet encodes raw like EncodeCrypt3,
+// using the 64 characters of alphabet.
+// Note that bcrypt's base64 is consumed big-endian,
+// like the standard base64 encoding with another alphabet,
+// and can't be produced by this function.
+func EncodeWithAlphabet(raw []byte, alphabet string) []byte {
+	dest := make([]byte, 0, (len(raw)*8+6-1)/6)
+
+	v := uint(0)
+	bits := uint(0)
+
+	for _, b := range raw {
+		v |= (uint(b) << bits)
+
+		for bits = bits + 8; bits > 6; bits -= 6 {
+			dest = append(dest, alphabet[v&63])
+			v >>= 6
+		}
+	}
+	dest = append(dest, alphabet[v&63])
+	return dest
+}
+
+// ErrCrypt3 is returned by DecodeCrypt3 for invalid input.
+var ErrCrypt3 = errors.New("encoding: invalid crypt3 input")
+
+// DecodeCrypt3 decodes src, as encoded by EncodeCrypt3.
+// An error is returned for characters outside the Crypt3
+// character set, for lengths which can't be produced by
+// EncodeCrypt3 and when the unused bits of the last
+// character are not zero.
+func DecodeCrypt3(src []byte) ([]byte, error) {
+	if len(src)%4 == 1 {
+		return nil, fmt.Errorf("%w: length %d", ErrCrypt3, len(src))
+	}
+	dst := make([]byte, 0, len(src)*6/8)
+
+	v := uint(0)
+	bits := uint(0)
+
+	for i, c := range src {
+		d := strings.IndexByte(Crypt3, c)
+		if d < 0 {
+			return nil, fmt.Errorf("%w: character %q at %d", ErrCrypt3, c, i)
+		}
+		v |= uint(d) << bits
+
+		if bits += 6; bits >= 8 {
+			dst = append(dst, byte(v))
+			v >>= 8
+			bits -= 8
+		}
+	}
+	if v != 0 {
+		return nil, fmt.Errorf("%w: trailing bits", ErrCrypt3)
+	}
+	return dst, nil
+}
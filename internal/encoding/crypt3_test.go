@@ -0,0 +1,98 @@
+package encoding
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEncodeCrypt3(t *testing.T) {
+	tests := []struct {
+		raw  []byte
+		want string
+	}{
+		{[]byte{0}, ".."},
+		{[]byte{0xff}, "z1"},
+		{[]byte{0, 0, 0}, "...."},
+		{[]byte{0xff, 0xff, 0xff}, "zzzz"},
+		{[]byte{1, 2, 3}, "/6k."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := EncodeCrypt3(tt.raw); string(got) != tt.want {
+				t.Errorf("EncodeCrypt3() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeWithAlphabet(t *testing.T) {
+	const std = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+	tests := []struct {
+		raw  []byte
+		want string
+	}{
+		{[]byte{0}, "AA"},
+		{[]byte{0xff}, "/D"},
+		{[]byte{0, 0, 0}, "AAAA"},
+		{[]byte{0xff, 0xff, 0xff}, "////"},
+		{[]byte{1, 2, 3}, "BIwA"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := EncodeWithAlphabet(tt.raw, std); string(got) != tt.want {
+				t.Errorf("EncodeWithAlphabet() = %s, want %s", got, tt.want)
+			}
+			if got, want := EncodeWithAlphabet(tt.raw, Crypt3), EncodeCrypt3(tt.raw); string(got) != string(want) {
+				t.Errorf("EncodeWithAlphabet(Crypt3) = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeCrypt3(t *testing.T) {
+	tests := []struct {
+		src     string
+		want    []byte
+		wantErr bool
+	}{
+		{"..", []byte{0}, false},
+		{"z1", []byte{0xff}, false},
+		{"....", []byte{0, 0, 0}, false},
+		{"zzzz", []byte{0xff, 0xff, 0xff}, false},
+		{"/6k.", []byte{1, 2, 3}, false},
+		{".", nil, true},
+		{"z1z1z", nil, true},
+		{"z!", nil, true},
+		{"z2", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			got, err := DecodeCrypt3([]byte(tt.src))
+			if !errors.Is(err, ErrCrypt3) != !tt.wantErr {
+				t.Fatalf("DecodeCrypt3() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("DecodeCrypt3() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeCrypt3_roundTrip(t *testing.T) {
+	raw := make([]byte, 64)
+	for i := range raw {
+		raw[i] = byte(i*37 + 11)
+	}
+	for n := 1; n <= len(raw); n++ {
+		encoded := EncodeCrypt3(raw[:n])
+		got, err := DecodeCrypt3(encoded)
+		if err != nil {
+			t.Fatalf("DecodeCrypt3(%s) error = %v", encoded, err)
+		}
+		if !bytes.Equal(got, raw[:n]) {
+			t.Errorf("DecodeCrypt3(%s) = %v, want %v", encoded, got, raw[:n])
+		}
+	}
+}
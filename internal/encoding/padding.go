@@ -0,0 +1,13 @@
+package encoding
+
+import "strings"
+
+// TrimPadding removes base64 padding from the
+// last n `$` separated fields of encoded.
+func TrimPadding(encoded string, n int) string {
+	fields := strings.Split(encoded, "$")
+	for i := len(fields) - 1; i >= 0 && i >= len(fields)-n; i-- {
+		fields[i] = strings.TrimRight(fields[i], "=")
+	}
+	return strings.Join(fields, "$")
+}
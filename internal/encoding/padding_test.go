@@ -0,0 +1,22 @@
+package encoding
+
+import "testing"
+
+func TestTrimPadding(t *testing.T) {
+	tests := []struct {
+		encoded string
+		n       int
+		want    string
+	}{
+		{"$id$p=1$c2FsdA==$aGFzaA==", 2, "$id$p=1$c2FsdA$aGFzaA"},
+		{"$id$p=1$c2FsdA==$aGFzaA==", 1, "$id$p=1$c2FsdA==$aGFzaA"},
+		{"$id$p==$c2FsdA$aGFzaA", 2, "$id$p==$c2FsdA$aGFzaA"},
+		{"aGFzaA==", 2, "aGFzaA"},
+		{"", 2, ""},
+	}
+	for _, tt := range tests {
+		if got := TrimPadding(tt.encoded, tt.n); got != tt.want {
+			t.Errorf("TrimPadding(%q, %d) = %q, want %q", tt.encoded, tt.n, got, tt.want)
+		}
+	}
+}
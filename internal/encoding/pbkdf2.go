@@ -13,11 +13,17 @@ const encodePbkdf2 = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz012345
 var Pbkdf2B64 = base64.NewEncoding(encodePbkdf2).WithPadding(base64.NoPadding)
 
 // AutoDecodePbkdf2 decodes a base64 encoded string in the
-// Pbkdf alternative format or [base64.RawStdEncoding].
+// Pbkdf alternative format, [base64.RawStdEncoding]
+// or [base64.RawURLEncoding].
+// The URL encoding is chosen when encoded contains `-` or `_`,
+// the standard encoding when it contains `+`.
 // Any padding is removed from the encoded string
 func AutoDecodePbkdf2(encoded string) ([]byte, error) {
 	encoding := Pbkdf2B64
-	if strings.ContainsRune(encoded, '+') {
+	switch {
+	case strings.ContainsAny(encoded, "-_"):
+		encoding = base64.RawURLEncoding
+	case strings.ContainsRune(encoded, '+'):
 		encoding = base64.RawStdEncoding
 	}
 	return encoding.DecodeString(strings.TrimRight(encoded, "="))
@@ -46,6 +46,28 @@ func TestAutoDecodePbkdf2(t *testing.T) {
 			},
 			want: in,
 		},
+		{
+			name: "URL, no padding",
+			args: args{
+				encoded: base64.RawURLEncoding.EncodeToString(in),
+			},
+			want: in,
+		},
+		{
+			name: "URL, padding",
+			args: args{
+				encoded: base64.URLEncoding.EncodeToString(in),
+			},
+			want: in,
+		},
+		{
+			name: "mixed URL and standard",
+			args: args{
+				encoded: "-+",
+			},
+			want:    []byte{},
+			wantErr: true,
+		},
 		{
 			name: "decode erorr",
 			args: args{
@@ -3,6 +3,7 @@ package salt
 
 import (
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"io"
 )
@@ -11,11 +12,33 @@ const RecommendedSize = 16
 
 var Reader = rand.Reader
 
+// ErrEntropy is returned when a salt
+// can't be read from the random source.
+var ErrEntropy = errors.New("salt: random source failed")
+
+// ErrInsecureRand is returned by AssertCryptoRand
+// for any source other than crypto/rand.Reader.
+var ErrInsecureRand = errors.New("salt: random source is not crypto/rand.Reader")
+
+// AssertCryptoRand returns ErrInsecureRand unless r is
+// crypto/rand.Reader itself. Readers are compared by identity,
+// so wrappers of crypto/rand.Reader are refused as well.
+func AssertCryptoRand(r io.Reader) error {
+	if r != rand.Reader {
+		return ErrInsecureRand
+	}
+	return nil
+}
+
+// New reads a salt of size bytes. An error wrapping
+// ErrEntropy and the error of the reader is returned
+// when less than size bytes could be read, so that
+// a salt is never built from a short read.
 func New(from io.Reader, size uint32) ([]byte, error) {
 	salt := make([]byte, size)
 
-	if _, err := from.Read(salt); err != nil {
-		return nil, fmt.Errorf("salt: %w", err)
+	if _, err := io.ReadFull(from, salt); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrEntropy, err)
 	}
 
 	return salt, nil
@@ -1,6 +1,8 @@
 package salt
 
 import (
+	"crypto/rand"
+	"errors"
 	"io"
 	"reflect"
 	"strings"
@@ -23,6 +25,11 @@ func TestNew(t *testing.T) {
 			args:    args{ErrReader{}, RecommendedSize},
 			wantErr: true,
 		},
+		{
+			name:    "short read",
+			args:    args{strings.NewReader("short"), RecommendedSize},
+			wantErr: true,
+		},
 		{
 			name: "string reader",
 			args: args{strings.NewReader("insecuresalt"), 12},
@@ -36,9 +43,32 @@ func TestNew(t *testing.T) {
 				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if err != nil && !errors.Is(err, ErrEntropy) {
+				t.Errorf("New() error = %v, want %v", err, ErrEntropy)
+			}
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("New() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestAssertCryptoRand(t *testing.T) {
+	tests := []struct {
+		name string
+		r    io.Reader
+		want error
+	}{
+		{"crypto/rand", rand.Reader, nil},
+		{"nil", nil, ErrInsecureRand},
+		{"fixed", strings.NewReader("insecuresalt"), ErrInsecureRand},
+		{"wrapped", io.LimitReader(rand.Reader, 16), ErrInsecureRand},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := AssertCryptoRand(tt.r); !errors.Is(err, tt.want) {
+				t.Errorf("AssertCryptoRand() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// Package semaphore provides a weighted semaphore,
+// used to bound the memory in use by concurrent
+// memory-hard key derivations.
+package semaphore
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// waiter is queued by Acquire until n is available.
+type waiter struct {
+	n     uint64
+	ready chan struct{}
+}
+
+// Weighted bounds access to a resource of a total size,
+// for example memory in bytes.
+// Waiters are served in the order they called Acquire,
+// so that large requests are not starved by a stream of small ones.
+type Weighted struct {
+	mu      sync.Mutex
+	size    uint64
+	cur     uint64
+	waiters list.List
+}
+
+// NewWeighted returns a Weighted semaphore for a resource of size.
+func NewWeighted(size uint64) *Weighted {
+	return &Weighted{size: size}
+}
+
+// clamp reduces n to the size of the semaphore.
+func (w *Weighted) clamp(n uint64) uint64 {
+	if n > w.size {
+		return w.size
+	}
+	return n
+}
+
+// Acquire n of the resource, blocking until it is available
+// or ctx is done. On success, n must be passed to Release.
+// An n larger than the size of the semaphore is reduced to size,
+// so that it can still be acquired once nothing else is in use.
+// When ctx is done first, nothing is acquired and ctx.Err() is returned.
+func (w *Weighted) Acquire(ctx context.Context, n uint64) error {
+	n = w.clamp(n)
+
+	w.mu.Lock()
+	if w.size-w.cur >= n && w.waiters.Len() == 0 {
+		w.cur += n
+		w.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	elem := w.waiters.PushBack(waiter{n: n, ready: ready})
+	w.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	select {
+	case <-ready:
+		// Acquired after ctx was done, give it back.
+		w.cur -= n
+	default:
+		w.waiters.Remove(elem)
+	}
+	// Waiters behind this one may fit now.
+	w.notify()
+	return ctx.Err()
+}
+
+// Release n of the resource, as passed to Acquire.
+func (w *Weighted) Release(n uint64) {
+	n = w.clamp(n)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if n > w.cur {
+		panic("semaphore: released more than held")
+	}
+	w.cur -= n
+	w.notify()
+}
+
+// notify wakes waiters in order, as long as they fit.
+// w.mu must be held.
+func (w *Weighted) notify() {
+	for {
+		front := w.waiters.Front()
+		if front == nil {
+			return
+		}
+		wt := front.Value.(waiter)
+		if w.size-w.cur < wt.n {
+			return
+		}
+		w.cur += wt.n
+		w.waiters.Remove(front)
+		close(wt.ready)
+	}
+}
@@ -0,0 +1,127 @@
+package semaphore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWeighted(t *testing.T) {
+	const (
+		size   = 10
+		weight = 4
+		n      = 20
+	)
+
+	w := NewWeighted(size)
+
+	var (
+		wg      sync.WaitGroup
+		current atomic.Int64
+		max     atomic.Int64
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := w.Acquire(context.Background(), weight); err != nil {
+				t.Error(err)
+				return
+			}
+			defer w.Release(weight)
+
+			c := current.Add(1)
+			for m := max.Load(); c > m && !max.CompareAndSwap(m, c); m = max.Load() {
+			}
+			time.Sleep(time.Millisecond)
+			current.Add(-1)
+		}()
+	}
+	wg.Wait()
+
+	if got := max.Load(); got > size/weight {
+		t.Errorf("max concurrent = %d, want at most %d", got, size/weight)
+	}
+}
+
+func TestWeighted_Acquire_oversized(t *testing.T) {
+	w := NewWeighted(10)
+	if err := w.Acquire(context.Background(), 100); err != nil {
+		t.Fatal(err)
+	}
+	w.Release(100)
+	if w.cur != 0 {
+		t.Errorf("Weighted.Release() left %d in use", w.cur)
+	}
+}
+
+// queued waits until w has n waiters.
+func queued(w *Weighted, n int) {
+	for {
+		w.mu.Lock()
+		l := w.waiters.Len()
+		w.mu.Unlock()
+		if l == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWeighted_Acquire_cancel(t *testing.T) {
+	w := NewWeighted(10)
+	if err := w.Acquire(context.Background(), 10); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error)
+	go func() { errc <- w.Acquire(ctx, 1) }()
+	queued(w, 1)
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("Weighted.Acquire() = %v, want %v", err, context.Canceled)
+	}
+
+	w.Release(10)
+	if w.cur != 0 || w.waiters.Len() != 0 {
+		t.Errorf("cancelled Acquire left %d in use and %d waiters", w.cur, w.waiters.Len())
+	}
+}
+
+func TestWeighted_Acquire_fifo(t *testing.T) {
+	w := NewWeighted(10)
+	if err := w.Acquire(context.Background(), 6); err != nil {
+		t.Fatal(err)
+	}
+
+	// large waits for all of the resource.
+	large := make(chan error)
+	go func() { large <- w.Acquire(context.Background(), 10) }()
+	queued(w, 1)
+
+	// small would fit, but must not overtake large.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := w.Acquire(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Weighted.Acquire() = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	w.Release(6)
+	if err := <-large; err != nil {
+		t.Fatal(err)
+	}
+	w.Release(10)
+}
+
+func TestWeighted_Release_panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Weighted.Release() did not panic")
+		}
+	}()
+	NewWeighted(10).Release(1)
+}
@@ -7,3 +7,20 @@ const (
 	EncodedBcrypt2y = `$2y$12$hXUrnqdq1RIIYZ2HPytIIe5lXdIvbhqrTvdPsSF7o.jFh817Z6lwm`
 	BcryptCost      = 12
 )
+
+// Bcrypt hash with cost 5, generated with x/crypto/bcrypt.
+// The single digit variant is the same hash with the cost
+// written without leading zero, as found in some legacy databases.
+const (
+	EncodedBcryptCost5            = `$2a$05$gwYVA3iVXaOHnrSTssht8.mpL3XXO0bP4FbnI6Ge23P4LF1TGEIbu`
+	EncodedBcryptCost5SingleDigit = `$2a$5$gwYVA3iVXaOHnrSTssht8.mpL3XXO0bP4FbnI6Ge23P4LF1TGEIbu`
+)
+
+// Bcrypt hash with cost 10, generated with x/crypto/bcrypt.
+const EncodedBcryptCost10 = `$2a$10$D6q4zDyXuQG3XJnQSyQUbuHVzzwMrUJ9EkgdKIYWt48ndMT7gxbdm`
+
+// Bcrypt hash with cost 5 of the SHA-256 prehashed Password,
+// in the bcrypt_sha256 format of passlib.
+// Generated with x/crypto/bcrypt, over the base64 encoded
+// SHA-256 sum of Password.
+const EncodedBcryptSHA256 = `$bcrypt-sha256$2a,05$ruJLFkvFR4ay6YGvOetJKu$XJSAW27wMqId2VIm5YTpE1BXmQuAbBi`
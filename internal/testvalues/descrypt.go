@@ -0,0 +1,12 @@
+package testvalues
+
+// DES crypt test values generated with Perl's crypt(),
+// using glibc's libcrypt.
+const (
+	DESCryptEncoded         = `abJnggxhB/yWI`
+	DESCryptEncodedEmpty    = `abmF1QH4PEr.E`
+	DESCryptEncodedNoSalt   = `..UZoIyj/Hy/c`
+	DESCryptEncodedHighSalt = `zZDDIZ0NOlPzw`
+	DESCryptEncodedUTF8     = `abzp3RXJm5gNA` // pässwörd
+	DESCryptEncodedTest     = `9AyQs.WYSYTuE` // test
+)
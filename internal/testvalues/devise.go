@@ -0,0 +1,8 @@
+package testvalues
+
+// Devise bcrypt hash of Password with DevisePepper,
+// generated with x/crypto/bcrypt from Password + DevisePepper.
+const (
+	DevisePepper  = "b6ba0ad8e3a01f7cd9a7f0b2b6b19d6d"
+	DeviseEncoded = `$2a$10$7pRf5.W2Mz4mn31aLNvRleQHZj7.y2feVhXbAK6wDm8ObH149nhxK`
+)
@@ -0,0 +1,14 @@
+package testvalues
+
+// Synthetic Django bcrypt hashes of Password. They were not created
+// by Django, but with x/crypto/bcrypt and the storage format of
+// Django's BCryptSHA256PasswordHasher and BCryptPasswordHasher.
+// Django itself emits `$2b$` hashes, x/crypto/bcrypt `$2a$`.
+const (
+	DjangoBcryptSHA256Encoded = `bcrypt_sha256$$2a$10$DTxL6TemPDNhE.Un7ssTg.QaF.1D/UlNcAOYsSLYC3kllENp.e3/u`
+	DjangoBcryptEncoded       = `bcrypt$$2a$10$9Xx5F.1vtkA0FqEm.ctIHOScyoRL997RCVfa.Bd/5BV0fDYEBDOhW`
+)
+
+// DjangoArgon2Encoded is Argon2idEncoded as stored
+// by Django's Argon2PasswordHasher.
+const DjangoArgon2Encoded = `argon2` + Argon2idEncoded
@@ -0,0 +1,15 @@
+package testvalues
+
+// Dovecot digest scheme values for Password,
+// with salt "saltsalt" where salted, generated with Python's hashlib.
+const (
+	DovecotPlainMD5B64 = `{PLAIN-MD5.b64}X03MO1qnZdYdgyfeuILPmQ==`
+	DovecotSHA         = `{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=`
+	DovecotSSHA        = `{SSHA}yrht1iYXEIkejLVu42JWkadd80RzYWx0c2FsdA==`
+	DovecotSSHAHex     = `{SSHA.HEX}cab86dd6261710891e8cb56ee3625691a75df34473616c7473616c74`
+	DovecotSMD5        = `{SMD5}/b3zQZ//mL2wJBOQ9iqds3NhbHRzYWx0`
+	DovecotSHA256      = `{SHA256}XohImNooBHFR0OVvjcYpJ3NgPQ1qq73WKhHvch0VQtg=`
+	DovecotSSHA256     = `{SSHA256}DIzeh0gCRMTRu9dAH3C3rr7fWkRT0Bp2ZdtRqvTX3XJzYWx0c2FsdA==`
+	DovecotSHA512      = `{SHA512}sQnzu7wkTrgkQZF+0G1hi5AI3Qmzvv0bXgc5THBqi7mAsdd4Xll27ASbRt9fEyavWi6m0QP9B8lThf+rDKy8hg==`
+	DovecotSSHA512     = `{SSHA512}9ZxHVj4YomwqqFiYKcIjExMLx2ZblYfXRGc4KMqbgvHq2+HOgwiTIi+eO/Uam/8D0beDAkGpvx14+UFlfBskLnNhbHRzYWx0`
+)
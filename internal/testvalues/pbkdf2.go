@@ -21,6 +21,9 @@ const (
 	Pbkdf2Sha256StdEncodedPadding = `$pbkdf2-sha256$12$cmFuZG9tc2FsdGlzaGFyZA==$OFvEcLOIPFd/oq8egf10i+qJLI7A8nDjPLnolCWarQY=`
 )
 
+// manually created to test decoding of URL-safe encoding
+const Pbkdf2Sha256URLEncoded = `$pbkdf2-sha256$12$cmFuZG9tc2FsdGlzaGFyZA$OFvEcLOIPFd_oq8egf10i-qJLI7A8nDjPLnolCWarQY`
+
 var (
 	Pbkdf2Sha1Hash   = parseBase64HashComponent(encoding.Pbkdf2B64, Pbkdf2Sha1Encoded, 4)
 	Pbkdf2Sha256Hash = parseBase64HashComponent(encoding.Pbkdf2B64, Pbkdf2Sha256Encoded, 4)
@@ -13,3 +13,10 @@ const (
 var (
 	ScryptHash = parseBase64HashComponent(base64.RawStdEncoding, ScryptEncoded, 4)
 )
+
+// Scrypt hash in the libsodium crypto_pwhash_scryptsalsa208sha256_str
+// layout, taken from the libsodium test suite.
+const (
+	ScryptSodiumPassword = "pleaseletmein"
+	ScryptSodiumEncoded  = `$7$C6..../....SodiumChloride$kBGj9fHznVYFQMEn/qDCfrDevf9YDtcDdKvEqHJLV8D`
+)
@@ -0,0 +1,11 @@
+package testvalues
+
+// SHA-1 crypt test values taken from the passlib test suite.
+const (
+	Sha1CryptEncoded        = `$sha1$19703$iVdJqfSE$v4qYKl1zqYThwpjJAoKX6UvlHq/a`
+	Sha1CryptEncoded2       = `$sha1$21773$uV7PTeux$I9oHnvwPZHMO0Nq6/WgyGV/tDJIH`
+	Sha1CryptEncodedUnicode = `$sha1$40000$uJ3Sp7LE$.VEmLO5xntyRFYihC7ggd3297T/D`
+	Sha1CryptUnicodePass    = "táБℓə"
+	Sha1CryptSalt           = "iVdJqfSE"
+	Sha1CryptRounds         = 19703
+)
@@ -0,0 +1,13 @@
+package testvalues
+
+// Tomcat credentials of Password, generated with Go following
+// the MessageDigestCredentialHandler and SecretKeyCredentialHandler
+// of Apache Tomcat. Salted values use Salt and 1000 iterations.
+const (
+	TomcatSHA512Salted = `72616e646f6d73616c74697368617264$1000$382a04a37af9964b6479daefa254fc87d9d7668c1396b8a522132677a1ea7b61d5ff5d710fe60cb6614e263df598c72265486ccd11d208bb2cee4dd1042ab708`
+	TomcatSHA256Hex    = `5E884898DA28047151D0E56F8DC6292773603D0D6AABBDD62A11EF721D1542D8`
+	TomcatPBKDF2SHA512 = `72616e646f6d73616c74697368617264$1000$79d5f7d70fe47daca7602cb5d537ab9280895255a7649229ad626c21cb508453`
+	TomcatMD5          = `{MD5}X03MO1qnZdYdgyfeuILPmQ==`
+	TomcatSHA          = `{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=`
+	TomcatSSHA         = `{SSHA}yrht1iYXEIkejLVu42JWkadd80RzYWx0c2FsdA==`
+)
@@ -0,0 +1,7 @@
+package testvalues
+
+// WordPressEncoded is a `$wp$` hash of Password, generated with
+// x/crypto/bcrypt following WordPress's wp_hash_password:
+// bcrypt of the base64 encoded HMAC-SHA384 of Password,
+// with the `$2y$` identifier of PHP's password_hash.
+const WordPressEncoded = `$wp$2y$10$dKd5Zi8kI/J3ooHPf4UOReeG0bTh8RkwMvzG2ubawHFSz2P/S7RW.`
@@ -0,0 +1,255 @@
+// Package iteratedhash provides verification of iterated,
+// salted digests of a password, as stored by the firmware of
+// routers and other embedded devices. Such formats are often
+// one-off constructions of a single vendor, so instead of a package
+// per format, a Verifier is configured with a [Format] describing
+// the digest, the source of the iteration count and how the
+// password is mixed into every iteration.
+// Presets are provided for some formats, see [SHA256Rounds]
+// and [SHA1Chain].
+//
+// Note that iterated digests are much weaker than a
+// proper key derivation function.
+// This package is only provided for legacy applications
+// that wish to migrate to better methods.
+package iteratedhash
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"strconv"
+	"strings"
+
+	"github.com/zitadel/passwap/verifier"
+)
+
+// Algorithm is the name reported by the Verifier,
+// see verifier.Named.
+const Algorithm = "iteratedhash"
+
+// DefaultSeparator is used when Format.Separator is empty.
+const DefaultSeparator = "$"
+
+// DefaultMaxIterations is used when Format.MaxIterations is zero.
+const DefaultMaxIterations = 1_000_000
+
+// Mixing defines the input of every iteration after the first.
+type Mixing int
+
+const (
+	// DigestOnly digests the previous digest.
+	DigestOnly Mixing = iota
+	// DigestPassword digests the previous digest followed by the password.
+	DigestPassword
+	// PasswordDigest digests the password followed by the previous digest.
+	PasswordDigest
+)
+
+// Format describes the layout of the encoded strings
+// accepted by a Verifier and how they are derived.
+//
+// Encoded strings consist of the Prefix, followed by
+// `iterations$salt$digest` when Iterations is zero,
+// or `salt$digest` otherwise, with Separator in place of `$`.
+type Format struct {
+	// Hash creates the digest, for example sha256.New.
+	Hash func() hash.Hash
+
+	// Prefix of the encoded strings, for example `$sha256r$`.
+	// Encoded strings without the Prefix are skipped.
+	Prefix string
+
+	// Separator between the fields.
+	// DefaultSeparator is used when empty.
+	Separator string
+
+	// Iterations is the fixed number of iterations,
+	// including the first. When zero, the number of
+	// iterations is read from the encoded string.
+	Iterations int
+
+	// MaxIterations bounds the number of iterations read
+	// from encoded strings, so that crafted strings can't
+	// exhaust the CPU. DefaultMaxIterations is used when zero.
+	MaxIterations int
+
+	// Base64 encoding of the digest.
+	// The digest is hex encoded when nil.
+	Base64 *base64.Encoding
+
+	// PrependSalt indicates the first iteration digests salt + password,
+	// instead of password + salt.
+	PrependSalt bool
+
+	// Mixing of the password into the iterations after the first.
+	Mixing Mixing
+}
+
+var (
+	// SHA256Rounds is the Format of `$sha256r$rounds$salt$digest`,
+	// with a hex encoded digest. The first iteration digests
+	// salt + password, every next one the previous digest
+	// followed by the password.
+	SHA256Rounds = Format{
+		Hash:        sha256.New,
+		Prefix:      "$sha256r$",
+		PrependSalt: true,
+		Mixing:      DigestPassword,
+	}
+
+	// SHA1Chain is the Format of `salt:digest`, with a raw standard
+	// base64 encoded digest. The first of 1000 iterations digests
+	// password + salt, every next one the previous digest.
+	SHA1Chain = Format{
+		Hash:       sha1.New,
+		Separator:  ":",
+		Iterations: 1000,
+		Base64:     base64.RawStdEncoding,
+		Mixing:     DigestOnly,
+	}
+)
+
+// Verifier for iterated digests of a Format.
+type Verifier struct {
+	f    Format
+	size int
+}
+
+// New returns a Verifier for encoded strings of f.
+func New(f Format) *Verifier {
+	if f.Separator == "" {
+		f.Separator = DefaultSeparator
+	}
+	if f.MaxIterations == 0 {
+		f.MaxIterations = DefaultMaxIterations
+	}
+	return &Verifier{
+		f:    f,
+		size: f.Hash().Size(),
+	}
+}
+
+// Algorithm implements verifier.Named.
+func (v *Verifier) Algorithm() string {
+	return Algorithm
+}
+
+// HashPrefixes implements verifier.Prefixed.
+// Nil is returned when the Format has no Prefix.
+func (v *Verifier) HashPrefixes() []string {
+	if v.f.Prefix == "" {
+		return nil
+	}
+	return []string{v.f.Prefix}
+}
+
+// Prefixes returns nil, as the prefix depends on the Format,
+// see [Verifier.HashPrefixes] and verifier.Prefixed.
+func Prefixes() []string {
+	return nil
+}
+
+type checker struct {
+	iterations int
+	salt       string
+	digest     []byte
+}
+
+// parse encoded in the layout of the Format.
+// ok is false when encoded does not match the layout,
+// the iterations are not a positive integer or the digest
+// can't be decoded to the size of the Hash.
+func (v *Verifier) parse(encoded string) (c checker, ok bool) {
+	encoded, ok = strings.CutPrefix(encoded, v.f.Prefix)
+	if !ok {
+		return checker{}, false
+	}
+	fields := strings.Split(encoded, v.f.Separator)
+
+	c.iterations = v.f.Iterations
+	if c.iterations == 0 {
+		if len(fields) != 3 {
+			return checker{}, false
+		}
+		var err error
+		if c.iterations, err = strconv.Atoi(fields[0]); err != nil || c.iterations < 1 {
+			return checker{}, false
+		}
+		fields = fields[1:]
+	}
+	if len(fields) != 2 {
+		return checker{}, false
+	}
+	c.salt = fields[0]
+
+	var err error
+	if v.f.Base64 != nil {
+		c.digest, err = v.f.Base64.DecodeString(fields[1])
+	} else {
+		c.digest, err = hex.DecodeString(fields[1])
+	}
+	if err != nil || len(c.digest) != v.size {
+		return checker{}, false
+	}
+	return c, true
+}
+
+// derive the digest of password with the salt
+// and iterations of c.
+func (v *Verifier) derive(c checker, password string) []byte {
+	h := v.f.Hash()
+	if v.f.PrependSalt {
+		h.Write([]byte(c.salt))
+		h.Write([]byte(password))
+	} else {
+		h.Write([]byte(password))
+		h.Write([]byte(c.salt))
+	}
+	sum := h.Sum(nil)
+
+	for i := 1; i < c.iterations; i++ {
+		h.Reset()
+		switch v.f.Mixing {
+		case DigestPassword:
+			h.Write(sum)
+			h.Write([]byte(password))
+		case PasswordDigest:
+			h.Write([]byte(password))
+			h.Write(sum)
+		default:
+			h.Write(sum)
+		}
+		sum = h.Sum(sum[:0])
+	}
+	return sum
+}
+
+// Verify implements verifier.Verifier.
+// Skip is returned when encoded does not match the layout of the Format.
+// Formats without a Prefix might accept other strings of
+// the same layout, which then fail password verification.
+// Skip and a [verifier.BoundsError] are returned without derivation
+// when the iterations of encoded are above the MaxIterations of the Format.
+func (v *Verifier) Verify(encoded, password string) (verifier.Result, error) {
+	c, ok := v.parse(encoded)
+	if !ok {
+		return verifier.Skip, nil
+	}
+	if v.f.Iterations == 0 {
+		if err := verifier.CheckBounds(Algorithm, "iterations", int64(c.iterations), 0, int64(v.f.MaxIterations)); err != nil {
+			return verifier.Skip, err
+		}
+	}
+	return verifier.Result(subtle.ConstantTimeCompare(v.derive(c, password), c.digest)), nil
+}
+
+// SelfTestVector returns a fixed password and its encoded hash,
+// which the Verifier of New(SHA256Rounds) must accept. It guards
+// against regressions of the encoding format, see passwap.SelfTest.
+func SelfTestVector() (password, encoded string) {
+	return "password", `$sha256r$5000$pepper$60a1fb6bb4289cb7c3a1e4de352a3a8f4ba326467769580da47a52cd4da4bbfe`
+}
@@ -0,0 +1,195 @@
+package iteratedhash
+
+import (
+	"crypto/md5"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/verifier"
+)
+
+// Synthetic digests of "password" and the salt "pepper",
+// generated with Python's hashlib.
+const (
+	sha256RoundsEncoded = "$sha256r$5000$pepper$60a1fb6bb4289cb7c3a1e4de352a3a8f4ba326467769580da47a52cd4da4bbfe"
+	sha1ChainEncoded    = "pepper:dFK720IR2pezzE3QsFKT3U5mYSw"
+	// md5(password + md5(...md5(password + salt))), 10 iterations.
+	md5PasswordDigestEncoded = "10$pepper$4c0214f78f93ee47067bb7a1ac2d7e0e"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   Format
+		encoded  string
+		password string
+		want     verifier.Result
+	}{
+		{
+			name:     "SHA256Rounds",
+			format:   SHA256Rounds,
+			encoded:  sha256RoundsEncoded,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "SHA256Rounds wrong password",
+			format:   SHA256Rounds,
+			encoded:  sha256RoundsEncoded,
+			password: "foobar",
+			want:     verifier.Fail,
+		},
+		{
+			name:     "SHA256Rounds other rounds",
+			format:   SHA256Rounds,
+			encoded:  strings.Replace(sha256RoundsEncoded, "$5000$", "$4999$", 1),
+			password: tv.Password,
+			want:     verifier.Fail,
+		},
+		{
+			name:     "SHA256Rounds zero rounds",
+			format:   SHA256Rounds,
+			encoded:  strings.Replace(sha256RoundsEncoded, "$5000$", "$0$", 1),
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "SHA256Rounds no rounds",
+			format:   SHA256Rounds,
+			encoded:  strings.Replace(sha256RoundsEncoded, "$5000$", "$", 1),
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "SHA256Rounds wrong prefix",
+			format:   SHA256Rounds,
+			encoded:  strings.Replace(sha256RoundsEncoded, "sha256r", "sha512r", 1),
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "SHA256Rounds digest of wrong size",
+			format:   SHA256Rounds,
+			encoded:  "$sha256r$5000$pepper$4c0214f78f93ee47067bb7a1ac2d7e0e",
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "SHA1Chain",
+			format:   SHA1Chain,
+			encoded:  sha1ChainEncoded,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "SHA1Chain wrong password",
+			format:   SHA1Chain,
+			encoded:  sha1ChainEncoded,
+			password: "foobar",
+			want:     verifier.Fail,
+		},
+		{
+			name:     "SHA1Chain other iterations",
+			format:   Format{Hash: SHA1Chain.Hash, Separator: ":", Iterations: 999, Base64: SHA1Chain.Base64},
+			encoded:  sha1ChainEncoded,
+			password: tv.Password,
+			want:     verifier.Fail,
+		},
+		{
+			name:     "SHA1Chain hex digest",
+			format:   SHA1Chain,
+			encoded:  "pepper:5bc31e7e8e4ebb8f2b4ac2b56e0d7bba0b50ba3a",
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "SHA1Chain multiple separators",
+			format:   SHA1Chain,
+			encoded:  "1000:" + sha1ChainEncoded,
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "PasswordDigest",
+			format:   Format{Hash: md5.New, Mixing: PasswordDigest},
+			encoded:  md5PasswordDigestEncoded,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "PasswordDigest wrong mixing",
+			format:   Format{Hash: md5.New, Mixing: DigestPassword},
+			encoded:  md5PasswordDigestEncoded,
+			password: tv.Password,
+			want:     verifier.Fail,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.format).Verify(tt.encoded, tt.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Verifier.Verify() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifier_Verify_maxIterations(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		encoded string
+		want    verifier.Result
+	}{
+		{
+			name:    "default",
+			format:  SHA256Rounds,
+			encoded: strings.Replace(sha256RoundsEncoded, "$5000$", "$2147483647$", 1),
+			want:    verifier.Skip,
+		},
+		{
+			name:    "MaxIterations",
+			format:  Format{Hash: SHA256Rounds.Hash, Prefix: SHA256Rounds.Prefix, PrependSalt: true, Mixing: DigestPassword, MaxIterations: 4999},
+			encoded: sha256RoundsEncoded,
+			want:    verifier.Skip,
+		},
+		{
+			name:    "at MaxIterations",
+			format:  Format{Hash: SHA256Rounds.Hash, Prefix: SHA256Rounds.Prefix, PrependSalt: true, Mixing: DigestPassword, MaxIterations: 5000},
+			encoded: sha256RoundsEncoded,
+			want:    verifier.OK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			got, err := New(tt.format).Verify(tt.encoded, tv.Password)
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Verifier.Verify() took %v", elapsed)
+			}
+			var target *verifier.BoundsError
+			if wantErr := tt.want == verifier.Skip; errors.As(err, &target) != wantErr {
+				t.Errorf("Verifier.Verify() error = %v, want BoundsError %t", err, wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Verifier.Verify() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifier_HashPrefixes(t *testing.T) {
+	if got, want := New(SHA256Rounds).HashPrefixes(), []string{"$sha256r$"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Verifier.HashPrefixes() = %v, want %v", got, want)
+	}
+	if got := New(SHA1Chain).HashPrefixes(); got != nil {
+		t.Errorf("Verifier.HashPrefixes() = %v, want nil", got)
+	}
+}
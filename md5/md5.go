@@ -11,13 +11,16 @@
 package md5
 
 import (
+	"bytes"
 	"crypto/md5"
 	"crypto/rand"
 	"crypto/subtle"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
 
+	"github.com/zitadel/passwap/internal/encoding"
 	"github.com/zitadel/passwap/internal/salt"
 	"github.com/zitadel/passwap/verifier"
 )
@@ -31,26 +34,23 @@ const (
 	Format = Prefix + "%s$%s"
 
 	// Encoding is the character set used for encoding salt and checksum.
-	Encoding = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
-)
+	Encoding = encoding.Crypt3
 
-func encode(raw []byte) []byte {
-	dest := make([]byte, 0, (len(raw)*8+6-1)/6)
+	// Rounds is the fixed amount of digest iterations
+	// defined by md5-crypt. The encoded format has no
+	// rounds field, so it can't be configured.
+	Rounds = 1000
 
-	v := uint(0)
-	bits := uint(0)
+	// MaxSaltLen is the maximum amount of encoded salt characters.
+	MaxSaltLen = 8
 
-	for _, b := range raw {
-		v |= (uint(b) << bits)
+	// DefaultSaltLen is the amount of encoded salt characters
+	// used by a zero Hasher.
+	DefaultSaltLen = MaxSaltLen
+)
 
-		for bits = bits + 8; bits > 6; bits -= 6 {
-			dest = append(dest, Encoding[v&63])
-			v >>= 6
-		}
-	}
-	dest = append(dest, Encoding[v&63])
-	return dest
-}
+// ErrSaltLen is returned for salt lengths outside of 1 to MaxSaltLen.
+var ErrSaltLen = fmt.Errorf("md5: salt length must be between 1 and %d characters", MaxSaltLen)
 
 var swaps = [md5.Size]int{12, 6, 0, 13, 7, 1, 14, 8, 2, 15, 9, 3, 5, 10, 4, 11}
 
@@ -81,7 +81,7 @@ func checksum(password, salt []byte) []byte {
 
 	hash = digest.Sum(nil)
 
-	for i := 0; i < 1000; i++ {
+	for i := 0; i < Rounds; i++ {
 		digest.Reset()
 
 		if i&1 == 1 {
@@ -113,19 +113,20 @@ func checksum(password, salt []byte) []byte {
 		swapped[i] = hash[j]
 	}
 
-	return encode(swapped)
+	return encoding.EncodeCrypt3(swapped)
 }
 
 // 6 saltbytes result in 8 characters of encoded salt.
 const saltBytes = 6
 
-func hash(r io.Reader, password string) (string, error) {
+// hash password with a salt of saltLen encoded characters.
+func hash(r io.Reader, password string, saltLen int) (string, error) {
 	salt, err := salt.New(r, saltBytes)
 	if err != nil {
 		return "", fmt.Errorf("md5: %w", err)
 	}
 
-	encSalt := encode(salt)
+	encSalt := encoding.EncodeCrypt3(salt)[:saltLen]
 
 	checksum := checksum([]byte(password), encSalt)
 	return fmt.Sprintf(Format, encSalt, checksum), nil
@@ -151,6 +152,14 @@ func parse(encoded string) (*checker, error) {
 	if err != nil {
 		return nil, fmt.Errorf("md5 parse: %w", err)
 	}
+	// md5-crypt has no rounds field. Variants which
+	// put one in place of the salt are not supported.
+	if bytes.HasPrefix(c.salt, []byte("rounds=")) {
+		return nil, errors.New("md5 parse: rounds are fixed to 1000")
+	}
+	if len(c.salt) > MaxSaltLen {
+		return nil, fmt.Errorf("md5 parse: %w, got %d", ErrSaltLen, len(c.salt))
+	}
 
 	return &c, nil
 }
@@ -163,6 +172,19 @@ func (c *checker) verify(password string) verifier.Result {
 	)
 }
 
+// Identify parses encoded and returns its identifier
+// and salt length.
+func Identify(encoded string) (map[string]any, error) {
+	c, err := parse(encoded)
+	if err != nil || c == nil {
+		return nil, err
+	}
+	return map[string]any{
+		"identifier": Identifier,
+		"salt_len":   len(c.salt),
+	}, nil
+}
+
 // Verify parses encoded and verfies password against the checksum.
 func Verify(encoded, password string) (verifier.Result, error) {
 	c, err := parse(encoded)
@@ -173,17 +195,65 @@ func Verify(encoded, password string) (verifier.Result, error) {
 	return c.verify(password), nil
 }
 
-// Hasher provides an md5 hasher which always obtains
-// a salt of 6 random bytes, resulting in 8 encoded characters.
+// Hasher provides an md5 hasher.
+// The zero value obtains a salt of 6 random bytes,
+// resulting in 8 encoded characters.
+// Use New for shorter salts.
 // md5 is considered crypgraphically broken and this hasher
 // should not be used in new applications.
 // It is only provided for legacy applications that really
 // depend on md5.
-type Hasher struct{}
+type Hasher struct {
+	saltLen int
+
+	// rand is the source of salts, crypto/rand.Reader
+	// when nil. It must be safe for concurrent use.
+	rand io.Reader
+}
+
+// Option configures a Hasher.
+type Option func(*Hasher)
+
+// WithInsecureRand sets the source of salts to r, instead of
+// crypto/rand.Reader, so that the Hasher produces reproducible
+// hashes for known-answer test vectors, see package vectors.
+// Hashes with predictable salts must never be stored,
+// hence the name.
+// r is shared by concurrent calls to Hash.
+func WithInsecureRand(r io.Reader) Option {
+	return func(h *Hasher) {
+		h.rand = r
+	}
+}
+
+// New returns a Hasher which generates salts of saltLen
+// encoded characters. A saltLen of 0 results in DefaultSaltLen.
+// ErrSaltLen is returned when saltLen is not between 1 and MaxSaltLen.
+func New(saltLen int, opts ...Option) (Hasher, error) {
+	if saltLen == 0 {
+		saltLen = DefaultSaltLen
+	}
+	if saltLen < 1 || saltLen > MaxSaltLen {
+		return Hasher{}, fmt.Errorf("%w, got %d", ErrSaltLen, saltLen)
+	}
+	h := Hasher{saltLen: saltLen}
+	for _, opt := range opts {
+		opt(&h)
+	}
+	return h, nil
+}
 
 // Hash implements passwap.Hasher.
-func (Hasher) Hash(password string) (string, error) {
-	return hash(rand.Reader, password)
+func (h Hasher) Hash(password string) (string, error) {
+	saltLen := h.saltLen
+	if saltLen == 0 {
+		saltLen = DefaultSaltLen
+	}
+	r := h.rand
+	if r == nil {
+		r = rand.Reader
+	}
+	return hash(r, password, saltLen)
 }
 
 // Verify implements passwap.Verifier
@@ -191,5 +261,40 @@ func (Hasher) Verify(encoded, password string) (verifier.Result, error) {
 	return Verify(encoded, password)
 }
 
+// Identify implements verifier.Identifier.
+func (Hasher) Identify(encoded string) (map[string]any, error) {
+	return Identify(encoded)
+}
+
+// Algorithm implements verifier.Named.
+func (Hasher) Algorithm() string {
+	return Algorithm
+}
+
+// HashPrefixes implements verifier.Prefixed.
+func (Hasher) HashPrefixes() []string {
+	return prefixes
+}
+
+// Algorithm is the name reported by the Verifier and Hasher,
+// see verifier.Named.
+const Algorithm = "md5"
+
+// prefixes recognized by the Verifier and Hasher.
+var prefixes = []string{Prefix}
+
 // Verifier for md5.
-var Verifier = verifier.VerifyFunc(Verify)
+var Verifier = &verifier.NamedFunc{Name: Algorithm, VerifyFunc: Verify, Prefixes: prefixes}
+
+// Prefixes returns the prefixes of the hashes handled by this package,
+// as recognized by the Verifier, see verifier.Prefixed.
+func Prefixes() []string {
+	return append([]string(nil), prefixes...)
+}
+
+// SelfTestVector returns a fixed password and its encoded hash,
+// which [Verify] must accept. It guards against regressions
+// of the encoding format, see passwap.SelfTest.
+func SelfTestVector() (password, encoded string) {
+	return "password", `$1$kJ4QkJaQ$3EbD/pJddrq5HW3mpZ4KZ1`
+}
@@ -2,6 +2,8 @@ package md5
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"io"
 	"reflect"
 	"strings"
@@ -24,6 +26,7 @@ func Test_hash(t *testing.T) {
 	type args struct {
 		r        io.Reader
 		password string
+		saltLen  int
 	}
 	tests := []struct {
 		name    string
@@ -33,18 +36,23 @@ func Test_hash(t *testing.T) {
 	}{
 		{
 			name:    "salt error",
-			args:    args{salt.ErrReader{}, testvalues.Password},
+			args:    args{salt.ErrReader{}, testvalues.Password, DefaultSaltLen},
 			wantErr: true,
 		},
 		{
 			name: "success",
-			args: args{strings.NewReader(testvalues.MD5SaltRaw), testvalues.Password},
+			args: args{strings.NewReader(testvalues.MD5SaltRaw), testvalues.Password, DefaultSaltLen},
 			want: testvalues.MD5Encoded,
 		},
+		{
+			name: "short salt",
+			args: args{strings.NewReader(testvalues.MD5SaltRaw), testvalues.Password, 4},
+			want: "$1$kJ4Q$" + string(checksum([]byte(testvalues.Password), []byte("kJ4Q"))),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := hash(tt.args.r, tt.args.password)
+			got, err := hash(tt.args.r, tt.args.password, tt.args.saltLen)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("hash() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -56,6 +64,24 @@ func Test_hash(t *testing.T) {
 	}
 }
 
+func Test_hash_entropy(t *testing.T) {
+	readers := map[string]func() io.Reader{
+		"error":      func() io.Reader { return salt.ErrReader{} },
+		"short read": func() io.Reader { return strings.NewReader("short") },
+	}
+	for name, r := range readers {
+		t.Run(name, func(t *testing.T) {
+			got, err := hash(r(), testvalues.Password, DefaultSaltLen)
+			if !errors.Is(err, salt.ErrEntropy) {
+				t.Errorf("hash() error = %v, want %v", err, salt.ErrEntropy)
+			}
+			if got != "" {
+				t.Errorf("hash() = %s, want empty", got)
+			}
+		})
+	}
+}
+
 func Test_parse(t *testing.T) {
 	type args struct {
 		encoded string
@@ -75,6 +101,16 @@ func Test_parse(t *testing.T) {
 			args:    args{"$1$foo"},
 			wantErr: true,
 		},
+		{
+			name:    "rounds",
+			args:    args{"$1$rounds=1$kJ4QkJaQ$3EbD/pJddrq5HW3mpZ4KZ1"},
+			wantErr: true,
+		},
+		{
+			name:    "salt too long",
+			args:    args{"$1$kJ4QkJaQx$3EbD/pJddrq5HW3mpZ4KZ1"},
+			wantErr: true,
+		},
 		{
 			name: "success",
 			args: args{testvalues.MD5Encoded},
@@ -178,18 +214,117 @@ func TestVerify(t *testing.T) {
 	}
 }
 
+func TestNew(t *testing.T) {
+	tests := []struct {
+		saltLen int
+		want    Hasher
+		wantErr error
+	}{
+		{-1, Hasher{}, ErrSaltLen},
+		{0, Hasher{saltLen: DefaultSaltLen}, nil},
+		{1, Hasher{saltLen: 1}, nil},
+		{MaxSaltLen, Hasher{saltLen: MaxSaltLen}, nil},
+		{MaxSaltLen + 1, Hasher{}, ErrSaltLen},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.saltLen), func(t *testing.T) {
+			got, err := New(tt.saltLen)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("New() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("New() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestHasher(t *testing.T) {
-	var h Hasher
+	short, err := New(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		h        Hasher
+		wantSalt int
+	}{
+		{"zero", Hasher{}, DefaultSaltLen},
+		{"short salt", short, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := tt.h.Hash("foobar")
+			if err != nil {
+				t.Fatal(err)
+			}
+			params, err := Identify(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if params["salt_len"] != tt.wantSalt {
+				t.Errorf("Hasher.Hash() salt_len = %v, want %d", params["salt_len"], tt.wantSalt)
+			}
+			result, err := tt.h.Verify(encoded, "foobar")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != verifier.OK {
+				t.Errorf("Hasher.Verify() = %s, want %s", result, verifier.OK)
+			}
+		})
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		want    map[string]any
+		wantErr bool
+	}{
+		{
+			name:    "skip",
+			encoded: testvalues.ScryptEncoded,
+		},
+		{
+			name:    "parse error",
+			encoded: "$1$foo",
+			wantErr: true,
+		},
+		{
+			name:    "success",
+			encoded: testvalues.MD5Encoded,
+			want: map[string]any{
+				"identifier": Identifier,
+				"salt_len":   len(testvalues.MD5Salt),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Hasher{}.Identify(tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Identify() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Identify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
 
-	encoded, err := h.Hash("foobar")
+func TestWithInsecureRand(t *testing.T) {
+	h, err := New(DefaultSaltLen, WithInsecureRand(strings.NewReader(testvalues.MD5SaltRaw)))
 	if err != nil {
 		t.Fatal(err)
 	}
-	result, err := h.Verify(encoded, "foobar")
+	got, err := h.Hash(testvalues.Password)
 	if err != nil {
 		t.Fatal(err)
 	}
-	if result != verifier.OK {
-		t.Errorf("Hasher.Verify() = %s, want %s", result, verifier.OK)
+	if got != testvalues.MD5Encoded {
+		t.Errorf("Hasher.Hash() = %s, want %s", got, testvalues.MD5Encoded)
 	}
 }
@@ -11,21 +11,56 @@ import (
 	"crypto/md5"
 	"crypto/subtle"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/zitadel/passwap/verifier"
 )
 
+// Prefixes which some applications store in front
+// of the hex encoded digest, to mark it as md5.
+// They are unrelated to the `$1$` prefix of md5-crypt.
+const (
+	Prefix     = "$md5$"
+	PrefixBare = "md5$"
+)
+
+// ErrMalformed is returned with a Skip result for digests
+// with a Prefix, which are not 32 hex characters.
+var ErrMalformed = errors.New("md5plain: malformed digest")
+
 // Verify an plain md5 digest without salt.
-// Digest must be hex encoded.
+// Digest must be hex encoded, optionally
+// preceded by Prefix or PrefixBare.
+// Hex digits are accepted in lower and upper case,
+// see [Normalize] for systems which compare digests
+// case-sensitively.
+// Skip is returned when digest is not exactly
+// 32 hex characters, without an error. As md5 digests
+// do not have an identifier, other strings can't be
+// considered malformed md5 digests.
+// For prefixed digests [ErrMalformed] is returned instead,
+// unless the digest contains further `$` separated fields,
+// like the Sun MD5 crypt format, which also uses `$md5$`.
 //
-// Note that md5 digests do not have an identifier.
-// Therefore it might be that Verify accepts any hex encoded string
-// but fails password verification.
+// Note that it might be that Verify accepts any
+// 32 character hex encoded string but fails password verification.
+// In a Swapper, register the Verifier after all others,
+// or use passwap.WithLastResortVerifiers, so that it does not
+// shadow other formats of 32 hex characters.
 func Verify(digest, password string) (verifier.Result, error) {
+	digest, prefixed := cutPrefix(digest)
+	if prefixed && strings.Contains(digest, "$") {
+		return verifier.Skip, nil
+	}
+
 	decoded, err := hex.DecodeString(digest)
-	if err != nil {
-		return verifier.Skip, fmt.Errorf("md5plain parse: %w", err)
+	if err != nil || len(decoded) != md5.Size {
+		if prefixed {
+			return verifier.Skip, fmt.Errorf("%w: %q", ErrMalformed, digest)
+		}
+		return verifier.Skip, nil
 	}
 	sum := md5.Sum([]byte(password))
 	res := subtle.ConstantTimeCompare(sum[:], decoded)
@@ -33,4 +68,59 @@ func Verify(digest, password string) (verifier.Result, error) {
 	return verifier.Result(res), nil
 }
 
-var Verifier = verifier.VerifyFunc(Verify)
+// Case of the hex digits produced by [Normalize].
+type Case int
+
+const (
+	// LowerCase hex digits, as produced by most systems.
+	LowerCase Case = iota
+	// UpperCase hex digits.
+	UpperCase
+)
+
+// Normalize returns digest with its hex digits in case c,
+// keeping Prefix or PrefixBare when present.
+// This allows storing digests in the case expected by
+// systems which compare the encoded string case-sensitively.
+// [ErrMalformed] is returned when digest is not 32 hex characters.
+func Normalize(digest string, c Case) (string, error) {
+	raw, _ := cutPrefix(digest)
+	decoded, err := hex.DecodeString(raw)
+	if err != nil || len(decoded) != md5.Size {
+		return "", fmt.Errorf("%w: %q", ErrMalformed, raw)
+	}
+	encoded := hex.EncodeToString(decoded)
+	if c == UpperCase {
+		encoded = strings.ToUpper(encoded)
+	}
+	return digest[:len(digest)-len(raw)] + encoded, nil
+}
+
+// cutPrefix returns digest without Prefix or PrefixBare,
+// and reports if one of them was found.
+func cutPrefix(digest string) (string, bool) {
+	if after, ok := strings.CutPrefix(digest, Prefix); ok {
+		return after, true
+	}
+	return strings.CutPrefix(digest, PrefixBare)
+}
+
+// Algorithm is the name reported by the Verifier,
+// see verifier.Named.
+const Algorithm = "md5plain"
+
+var Verifier = &verifier.NamedFunc{Name: Algorithm, VerifyFunc: Verify}
+
+// Prefixes returns nil, as digests without Prefix or PrefixBare
+// are recognized as well,
+// see verifier.Prefixed.
+func Prefixes() []string {
+	return nil
+}
+
+// SelfTestVector returns a fixed password and its encoded hash,
+// which [Verify] must accept. It guards against regressions
+// of the encoding format, see passwap.SelfTest.
+func SelfTestVector() (password, encoded string) {
+	return "password", `5f4dcc3b5aa765d61d8327deb882cf99`
+}
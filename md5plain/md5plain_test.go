@@ -1,10 +1,13 @@
 package md5plain
 
 import (
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/zitadel/passwap/internal/testvalues"
+	md5crypt "github.com/zitadel/passwap/md5"
 	"github.com/zitadel/passwap/verifier"
 )
 
@@ -20,10 +23,14 @@ func TestVerify(t *testing.T) {
 		wantErr bool
 	}{
 		{
-			name:    "decode error",
-			args:    args{"!!!", testvalues.Password},
-			want:    verifier.Skip,
-			wantErr: true,
+			name: "not hex",
+			args: args{"!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!", testvalues.Password},
+			want: verifier.Skip,
+		},
+		{
+			name: "wrong length",
+			args: args{testvalues.MD5PlainHex + "00000000", testvalues.Password},
+			want: verifier.Skip,
 		},
 		{
 			name: "wrong password",
@@ -35,6 +42,52 @@ func TestVerify(t *testing.T) {
 			args: args{testvalues.MD5PlainHex, testvalues.Password},
 			want: verifier.OK,
 		},
+		{
+			name: "upper case",
+			args: args{strings.ToUpper(testvalues.MD5PlainHex), testvalues.Password},
+			want: verifier.OK,
+		},
+		{
+			name: "prefix",
+			args: args{Prefix + testvalues.MD5PlainHex, testvalues.Password},
+			want: verifier.OK,
+		},
+		{
+			name: "prefix upper case",
+			args: args{Prefix + strings.ToUpper(testvalues.MD5PlainHex), testvalues.Password},
+			want: verifier.OK,
+		},
+		{
+			name: "bare prefix",
+			args: args{PrefixBare + testvalues.MD5PlainHex, testvalues.Password},
+			want: verifier.OK,
+		},
+		{
+			name: "prefix wrong password",
+			args: args{Prefix + testvalues.MD5PlainHex, "foobar"},
+			want: verifier.Fail,
+		},
+		{
+			name:    "prefix malformed",
+			args:    args{Prefix + testvalues.MD5PlainHex[:31], testvalues.Password},
+			want:    verifier.Skip,
+			wantErr: true,
+		},
+		{
+			name: "sun md5 crypt",
+			args: args{"$md5,rounds=5000$GUBv0xjJ$$mSwgIswdjlTY0YxV7HBVm0", testvalues.Password},
+			want: verifier.Skip,
+		},
+		{
+			name: "sun md5 crypt without rounds",
+			args: args{"$md5$GUBv0xjJ$$mSwgIswdjlTY0YxV7HBVm0", testvalues.Password},
+			want: verifier.Skip,
+		},
+		{
+			name: "md5 crypt",
+			args: args{testvalues.MD5Encoded, testvalues.Password},
+			want: verifier.Skip,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -49,3 +102,63 @@ func TestVerify(t *testing.T) {
 		})
 	}
 }
+
+func TestVerify_md5crypt(t *testing.T) {
+	tests := []struct {
+		name         string
+		encoded      string
+		wantPlain    verifier.Result
+		wantMD5Crypt verifier.Result
+	}{
+		{"md5 crypt", testvalues.MD5Encoded, verifier.Skip, verifier.OK},
+		{"prefixed hex", Prefix + testvalues.MD5PlainHex, verifier.OK, verifier.Skip},
+		{"bare hex", testvalues.MD5PlainHex, verifier.OK, verifier.Skip},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := Verify(tt.encoded, testvalues.Password); got != tt.wantPlain || err != nil {
+				t.Errorf("md5plain.Verify() = %v, %v, want %v", got, err, tt.wantPlain)
+			}
+			if got, err := md5crypt.Verify(tt.encoded, testvalues.Password); got != tt.wantMD5Crypt || err != nil {
+				t.Errorf("md5.Verify() = %v, %v, want %v", got, err, tt.wantMD5Crypt)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	upper := strings.ToUpper(testvalues.MD5PlainHex)
+	tests := []struct {
+		name    string
+		digest  string
+		c       Case
+		want    string
+		wantErr error
+	}{
+		{"lower to upper", testvalues.MD5PlainHex, UpperCase, upper, nil},
+		{"upper to lower", upper, LowerCase, testvalues.MD5PlainHex, nil},
+		{"upper", upper, UpperCase, upper, nil},
+		{"mixed to lower", strings.ToUpper(testvalues.MD5PlainHex[:16]) + testvalues.MD5PlainHex[16:], LowerCase, testvalues.MD5PlainHex, nil},
+		{"prefix", Prefix + testvalues.MD5PlainHex, UpperCase, Prefix + upper, nil},
+		{"bare prefix", PrefixBare + upper, LowerCase, PrefixBare + testvalues.MD5PlainHex, nil},
+		{"malformed", testvalues.MD5PlainHex[:31], LowerCase, "", ErrMalformed},
+		{"md5 crypt", testvalues.MD5Encoded, LowerCase, "", ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.digest, tt.c)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Normalize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize() = %s, want %s", got, tt.want)
+			}
+			if err != nil {
+				return
+			}
+			if res, err := Verify(got, testvalues.Password); err != nil || res != verifier.OK {
+				t.Errorf("Verify() = %s, %v, want %s", res, err, verifier.OK)
+			}
+		})
+	}
+}
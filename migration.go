@@ -0,0 +1,35 @@
+package passwap
+
+import (
+	"github.com/zitadel/passwap/verifier"
+)
+
+// migrationVerifiers returns the Verifiers registered by
+// [NewMigrationSwapper], in the order of the algorithms.
+func migrationVerifiers() []verifier.Verifier {
+	var verifiers []verifier.Verifier
+	for _, a := range algorithms {
+		if a.migrate {
+			verifiers = append(verifiers, a.verifier)
+		}
+	}
+	return verifiers
+}
+
+// NewMigrationSwapper returns a Swapper which hashes with primary
+// and verifies the formats of all built-in Verifiers which don't
+// need configuration: argon2, bcrypt, scrypt, pbkdf2, sha1crypt,
+// md5crypt, dovecot, django, DES crypt and md5plain.
+// Passwords verified by any of them, except primary, are rehashed
+// with primary. This covers the common setup of migrating hashes
+// from other systems to a single algorithm.
+//
+// Note that md5plain accepts any string of 32 hex characters
+// and DES crypt any string of 13 characters of its alphabet,
+// as those formats have no identifier.
+// Plain md5 hashes are unsalted and fast to crack,
+// so they should be migrated quickly or reset.
+// Use [NewSwapper] to choose the verifiers explicitly.
+func NewMigrationSwapper(primary Hasher) *Swapper {
+	return NewSwapper(primary, migrationVerifiers()...)
+}
@@ -0,0 +1,51 @@
+package passwap
+
+import (
+	"errors"
+	"testing"
+
+	tv "github.com/zitadel/passwap/internal/testvalues"
+)
+
+func TestNewMigrationSwapper(t *testing.T) {
+	s := NewMigrationSwapper(testHasher)
+
+	tests := []struct {
+		name        string
+		encoded     string
+		password    string
+		wantUpdated bool
+	}{
+		{"argon2id primary", tv.Argon2idEncoded, tv.Password, false},
+		{"argon2i", tv.Argon2iEncoded, tv.Password, true},
+		{"bcrypt", tv.EncodedBcrypt2b, tv.Password, true},
+		{"scrypt", tv.ScryptEncoded, tv.Password, true},
+		{"pbkdf2", tv.Pbkdf2Sha256Encoded, tv.Password, true},
+		{"sha1crypt", tv.Sha1CryptEncoded, tv.Password, true},
+		{"md5crypt", tv.MD5Encoded, tv.Password, true},
+		{"dovecot", tv.DovecotSSHA256, tv.Password, true},
+		{"django", tv.DjangoBcryptSHA256Encoded, tv.Password, true},
+		{"descrypt", tv.DESCryptEncoded, tv.Password, true},
+		{"md5plain", tv.MD5PlainHex, tv.Password, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, err := s.Verify(tt.encoded, tt.password)
+			if err != nil {
+				t.Fatalf("Swapper.Verify() error = %v", err)
+			}
+			if (updated != "") != tt.wantUpdated {
+				t.Errorf("Swapper.Verify() updated = %q, want updated %t", updated, tt.wantUpdated)
+			}
+
+			_, err = s.Verify(tt.encoded, "spanac")
+			if !errors.Is(err, ErrPasswordMismatch) {
+				t.Errorf("Swapper.Verify() error = %v, want %v", err, ErrPasswordMismatch)
+			}
+		})
+	}
+
+	if _, err := s.Verify("foobar", tv.Password); !errors.Is(err, ErrNoVerifier) {
+		t.Errorf("Swapper.Verify() error = %v, want %v", err, ErrNoVerifier)
+	}
+}
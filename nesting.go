@@ -0,0 +1,125 @@
+package passwap
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// DetectNesting is a diagnostic heuristic for encoded strings
+// which wrap another encoded hash, as can result from botched
+// migrations. outer describes the outermost layer of encoded,
+// such as `$2b$`, `{CRYPT}` or `base64`, and is empty when
+// encoded doesn't look like a hash at all.
+// innerLooksLikeHash reports if the content of that layer
+// looks like an encoded hash itself.
+//
+// Only nesting that is visible in encoded can be detected:
+// hashes prefixed with a `{SCHEME}`, wrapped in base64,
+// or concatenated into a Modular Crypt Format string.
+// When an encoded hash was used as password, like
+// `bcrypt("$argon2id$...")`, the outer hash does not reveal
+// its input and innerLooksLikeHash is false.
+// Such rows can be confirmed by verifying the outer hash
+// with the original encoded hash as password, for example
+// from a backup. On success, restore the original hash.
+// Otherwise the password must be reset, as the original
+// hash is required to verify the user's password.
+func DetectNesting(encoded string) (outer string, innerLooksLikeHash bool) {
+	encoded = strings.TrimSpace(encoded)
+
+	if scheme, inner, ok := cutScheme(encoded); ok {
+		return scheme, looksLikeHash(inner) || looksLikeBase64Hash(inner)
+	}
+	if id, rest, ok := cutModularCrypt(encoded); ok {
+		return id, containsModularCrypt(rest)
+	}
+	if looksLikeBase64Hash(encoded) {
+		return "base64", true
+	}
+	if isHexDigest(encoded) {
+		return "hex", false
+	}
+	return "", false
+}
+
+// looksLikeHash reports if s starts like an encoded hash,
+// with a Modular Crypt Format or `{SCHEME}` prefix,
+// or is a hex encoded digest.
+func looksLikeHash(s string) bool {
+	if _, _, ok := cutModularCrypt(s); ok {
+		return true
+	}
+	if _, _, ok := cutScheme(s); ok {
+		return true
+	}
+	return isHexDigest(s)
+}
+
+// looksLikeBase64Hash reports if s is standard base64
+// of a string which looks like a hash.
+func looksLikeBase64Hash(s string) bool {
+	decoded, err := base64.StdEncoding.DecodeString(s)
+	return err == nil && looksLikeHash(string(decoded))
+}
+
+// cutModularCrypt cuts a `$id$` prefix from s,
+// where id consists of lower case letters, digits and dashes.
+func cutModularCrypt(s string) (id, rest string, ok bool) {
+	if len(s) < 3 || s[0] != '$' {
+		return "", "", false
+	}
+	end := strings.IndexByte(s[1:], '$') + 1
+	if end < 2 {
+		return "", "", false
+	}
+	for _, r := range s[1:end] {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+			return "", "", false
+		}
+	}
+	return s[:end+1], s[end+1:], true
+}
+
+// containsModularCrypt reports if a `$id$` prefix of a
+// known hash family occurs in s.
+// Only a fixed set of identifiers is searched,
+// as `$` separates fields in the Modular Crypt Format.
+func containsModularCrypt(s string) bool {
+	for _, id := range []string{"$argon2", "$2a$", "$2b$", "$2y$", "$1$", "$5$", "$6$", "$7$", "$scrypt$", "$pbkdf2", "$sha1$"} {
+		if strings.Contains(s, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// cutScheme cuts a `{SCHEME}` prefix from s,
+// where SCHEME consists of letters, digits, dots and dashes.
+func cutScheme(s string) (scheme, rest string, ok bool) {
+	if len(s) < 3 || s[0] != '{' {
+		return "", "", false
+	}
+	end := strings.IndexByte(s, '}')
+	if end < 2 {
+		return "", "", false
+	}
+	for _, r := range s[1:end] {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '.') {
+			return "", "", false
+		}
+	}
+	return s[:end+1], s[end+1:], true
+}
+
+// isHexDigest reports if s is a hex encoded
+// MD5, SHA-1, SHA-256 or SHA-512 digest.
+func isHexDigest(s string) bool {
+	switch len(s) {
+	case 32, 40, 64, 128:
+		_, err := hex.DecodeString(s)
+		return err == nil
+	default:
+		return false
+	}
+}
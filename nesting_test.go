@@ -0,0 +1,83 @@
+package passwap
+
+import (
+	"encoding/base64"
+	"testing"
+
+	tv "github.com/zitadel/passwap/internal/testvalues"
+)
+
+func TestDetectNesting(t *testing.T) {
+	tests := []struct {
+		name      string
+		encoded   string
+		wantOuter string
+		wantInner bool
+	}{
+		{
+			name:      "argon2",
+			encoded:   tv.Argon2idEncoded,
+			wantOuter: "$argon2id$",
+		},
+		{
+			name:      "bcrypt",
+			encoded:   tv.EncodedBcrypt2b,
+			wantOuter: "$2b$",
+		},
+		{
+			name:      "dovecot",
+			encoded:   tv.DovecotSSHA,
+			wantOuter: "{SSHA}",
+		},
+		{
+			name:      "md5 plain",
+			encoded:   tv.MD5PlainHex,
+			wantOuter: "hex",
+		},
+		{
+			name:    "not a hash",
+			encoded: tv.Password,
+		},
+		{
+			name:      "scheme wrapping crypt",
+			encoded:   "{CRYPT}" + tv.EncodedBcrypt2b,
+			wantOuter: "{CRYPT}",
+			wantInner: true,
+		},
+		{
+			name:      "scheme wrapping hex",
+			encoded:   "{MD5}" + tv.MD5PlainHex,
+			wantOuter: "{MD5}",
+			wantInner: true,
+		},
+		{
+			name:      "base64 wrapped",
+			encoded:   base64.StdEncoding.EncodeToString([]byte(tv.Argon2idEncoded)),
+			wantOuter: "base64",
+			wantInner: true,
+		},
+		{
+			name:      "concatenated",
+			encoded:   tv.EncodedBcrypt2b + tv.Argon2idEncoded,
+			wantOuter: "$2b$",
+			wantInner: true,
+		},
+		{
+			name:      "trimmed",
+			encoded:   " {CRYPT}" + tv.Argon2idEncoded + "\n",
+			wantOuter: "{CRYPT}",
+			wantInner: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOuter, gotInner := DetectNesting(tt.encoded)
+			if gotOuter != tt.wantOuter {
+				t.Errorf("DetectNesting() outer = %q, want %q", gotOuter, tt.wantOuter)
+			}
+			if gotInner != tt.wantInner {
+				t.Errorf("DetectNesting() innerLooksLikeHash = %t, want %t", gotInner, tt.wantInner)
+			}
+		})
+	}
+}
@@ -17,19 +17,66 @@ package passwap
 import (
 	"errors"
 	"fmt"
+	"io"
+	"reflect"
 	"strings"
+	"time"
 
+	"github.com/zitadel/passwap/internal/salt"
 	"github.com/zitadel/passwap/verifier"
 )
 
+// Errors returned by the Swapper.
+// A wrong password always results in ErrPasswordMismatch,
+// for all algorithms. Encoded strings which are recognized by
+// a Verifier but fail to parse result in a wrapped parse error instead,
+// so that corrupt hashes can be told apart from wrong passwords.
+// ErrNoVerifier is returned when no Verifier recognizes the encoded string.
 var (
 	ErrPasswordMismatch = errors.New("passwap: password does not match hash")
 	ErrPasswordNoChange = errors.New("passwap: new password same as old password")
 	ErrNoVerifier       = errors.New("passwap: no verifier found for encoded string")
+	ErrRehashRequired   = errors.New("passwap: hash is outdated and requires an update")
+	ErrPasswordReused   = errors.New("passwap: password was used before")
+	ErrLegacyAlgorithm  = errors.New("passwap: hash uses a legacy algorithm")
+	ErrStoredPrefix     = errors.New("passwap: encoded string is missing the stored prefix")
+	ErrVerifierPanic    = errors.New("passwap: verifier panicked")
+	ErrVerifyTimeout    = errors.New("passwap: verification timed out")
+	ErrHashParameters   = errors.New("passwap: Hasher does not report its hash parameters")
+
+	// ErrEntropy is wrapped by the errors of all Hashers
+	// in passwap, when a salt can't be read from their random
+	// source. No hash is returned in that case.
+	ErrEntropy = salt.ErrEntropy
 )
 
+// RehashRequiredError is returned by [Swapper.Verify] when the Swapper
+// was configured [WithRejectOutdated] and the password matches an
+// outdated hash. Updated contains the encoded hash that would have been
+// returned as update, so callers can decide to store it anyway or
+// to force a password reset.
+//
+// It wraps ErrRehashRequired and can be checked using [errors.Is]
+// or [errors.As].
+type RehashRequiredError struct {
+	Updated string
+}
+
+func (e *RehashRequiredError) Error() string {
+	return ErrRehashRequired.Error()
+}
+
+func (e *RehashRequiredError) Unwrap() error {
+	return ErrRehashRequired
+}
+
 // Hasher is capable of creating new hashes of passwords,
 // and verify passwords against existing hashes created by itself.
+// Implementations must be safe for concurrent use by multiple
+// goroutines. All Hashers provided by passwap are, as they only
+// read their configuration and obtain salts from crypto/rand.Reader,
+// unless a fixed source is explicitly set with their WithInsecureRand
+// option for generating test vectors.
 type Hasher interface {
 	verifier.Verifier
 	Hash(password string) (encoded string, err error)
@@ -40,9 +87,22 @@ type Hasher interface {
 // verifiers configured.
 // Swapper also updates hashes that are not created by
 // the main hasher or use outdated cost parameters.
+// A Swapper is safe for concurrent use by multiple goroutines,
+// as long as its Hasher and Verifiers are.
 type Swapper struct {
 	h         Hasher
 	verifiers []verifier.Verifier
+	index     prefixIndex
+
+	trimSpace      bool
+	storedPrefix   string
+	pwEncoding     func(password string) (string, error)
+	rejectOutdated bool
+	current        []bool
+	legacy         []bool
+	cache          *verifyCache
+	selfValidate   bool
+	verifyValidate bool
 }
 
 // NewSwapper with Hasher used for creating new hashes and
@@ -56,11 +116,314 @@ func NewSwapper(h Hasher, verifiers ...verifier.Verifier) *Swapper {
 	s := &Swapper{
 		h:         h,
 		verifiers: allV,
+		index:     newPrefixIndex(allV),
 	}
 
 	return s
 }
 
+// Option configures optional behavior of a Swapper.
+type Option func(*Swapper)
+
+// Apply opts to the Swapper and return it,
+// so it can be chained with [NewSwapper].
+// Options must be applied before the Swapper is used.
+func (s *Swapper) Apply(opts ...Option) *Swapper {
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithTrimSpace removes leading and trailing white space,
+// such as spaces and new lines, from encoded hash strings
+// before they are passed to the verifiers.
+// This is useful when hashes are imported from text files.
+// Passwords are never trimmed.
+func WithTrimSpace() Option {
+	return func(s *Swapper) {
+		s.trimSpace = true
+	}
+}
+
+// WithStoredPrefix configures a prefix, such as a product tag,
+// which is part of every stored hash. For example "app1:"
+// for hashes stored as "app1:$argon2id$...".
+// The prefix is removed from encoded strings before they
+// are passed to the verifiers, and added to the hashes
+// returned by the Swapper.
+// Encoded strings without the prefix are not verified
+// and result in [ErrStoredPrefix].
+//
+// When combined with [WithTrimSpace], white space is removed
+// before the prefix.
+func WithStoredPrefix(prefix string) Option {
+	return func(s *Swapper) {
+		s.storedPrefix = prefix
+	}
+}
+
+// decode prepares encoded for the verifiers, by removing
+// white space and the stored prefix, when configured.
+func (s *Swapper) decode(encoded string) (string, error) {
+	if s.trimSpace {
+		encoded = strings.TrimSpace(encoded)
+	}
+	if s.storedPrefix == "" {
+		return encoded, nil
+	}
+	encoded, ok := strings.CutPrefix(encoded, s.storedPrefix)
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrStoredPrefix, s.storedPrefix)
+	}
+	return encoded, nil
+}
+
+// WithPasswordEncoding transcodes passwords using enc,
+// before they are hashed or verified.
+// This allows verification of legacy hashes that were created
+// from non UTF-8 passwords, such as NTLM (UTF-16LE)
+// or some MD5 digests (Windows-1252).
+// The returned string may contain any bytes and is passed as-is
+// to the Hasher and Verifiers.
+//
+// enc must be safe for concurrent use.
+// For example, using golang.org/x/text/encoding/charmap:
+//
+//	passwap.WithPasswordEncoding(func(password string) (string, error) {
+//		return charmap.Windows1252.NewEncoder().String(password)
+//	})
+func WithPasswordEncoding(enc func(password string) (string, error)) Option {
+	return func(s *Swapper) {
+		s.pwEncoding = enc
+	}
+}
+
+// WithRejectOutdated makes [Swapper.Verify] return a [RehashRequiredError],
+// instead of an updated hash, when a matching password was verified
+// against a hash with an outdated algorithm or parameters.
+// This is meant for strict policies where outdated hashes
+// require a password reset instead of a silent upgrade.
+//
+// [Swapper.VerifyAndUpdate] is not affected, as it always
+// returns a new hash of a new password.
+func WithRejectOutdated() Option {
+	return func(s *Swapper) {
+		s.rejectOutdated = true
+	}
+}
+
+// WithCurrentVerifiers sets a predicate which reports if a Verifier,
+// passed to [NewSwapper], is considered current.
+// Passwords verified as OK by a current Verifier are not rehashed
+// with the Hasher, like it happens for any other Verifier.
+// A NeedUpdate result from a current Verifier
+// still triggers an update.
+// The Hasher is always current.
+//
+// current is called once for each Verifier when the option
+// is applied, and is not retained by the Swapper.
+// For example, to consider all configured Hashers current:
+//
+//	passwap.WithCurrentVerifiers(func(v verifier.Verifier) bool {
+//		_, ok := v.(passwap.Hasher)
+//		return ok
+//	})
+func WithCurrentVerifiers(current func(v verifier.Verifier) bool) Option {
+	return func(s *Swapper) {
+		if s.current == nil {
+			s.current = make([]bool, len(s.verifiers))
+		}
+		for i, v := range s.verifiers {
+			if current(v) {
+				s.current[i] = true
+			}
+		}
+	}
+}
+
+// WithAcceptableVerifiers marks verifiers, which were passed to
+// [NewSwapper], as current. See [WithCurrentVerifiers].
+// This avoids rehashing churn when multiple modern
+// algorithms are accepted side by side.
+//
+// Verifiers are matched by identity. Verifiers which are not
+// configured in the Swapper are ignored.
+func WithAcceptableVerifiers(verifiers ...verifier.Verifier) Option {
+	return WithCurrentVerifiers(func(v verifier.Verifier) bool {
+		for _, a := range verifiers {
+			if sameVerifier(v, a) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// sameVerifier reports if a and b are the same Verifier.
+// Function Verifiers, like [verifier.VerifyFunc],
+// are not comparable and are matched by their code pointer.
+func sameVerifier(a, b verifier.Verifier) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !va.IsValid() || !vb.IsValid() || va.Type() != vb.Type() {
+		return false
+	}
+	if va.Kind() == reflect.Func {
+		return va.Pointer() == vb.Pointer()
+	}
+	return va.Comparable() && va.Equal(vb)
+}
+
+// WithLegacyVerifiers marks the Hasher and Verifiers, which were
+// passed to [NewSwapper], as legacy by their algorithm name,
+// as reported by [verifier.Named].
+// Passwords verified by a legacy Verifier are always rehashed,
+// even when the Verifier is also marked current.
+// This allows verifying hashes of algorithms like md5 for migration,
+// while policy requires them to be replaced on the next login.
+// [Swapper.VerifyWithInfo] reports such verifications with
+// [ErrLegacyAlgorithm].
+//
+// Verifiers which don't implement verifier.Named can't be
+// marked legacy.
+func WithLegacyVerifiers(names ...string) Option {
+	return func(s *Swapper) {
+		if s.legacy == nil {
+			s.legacy = make([]bool, len(s.verifiers))
+		}
+		for i, v := range s.verifiers {
+			if hasAlgorithm(v, names) {
+				s.legacy[i] = true
+			}
+		}
+	}
+}
+
+// hasAlgorithm reports if v implements [verifier.Named]
+// with one of the algorithm names.
+func hasAlgorithm(v verifier.Verifier, names []string) bool {
+	n, ok := v.(verifier.Named)
+	if !ok {
+		return false
+	}
+	for _, name := range names {
+		if n.Algorithm() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// WithLastResortVerifiers moves Verifiers, which were passed to
+// [NewSwapper], to the end by their algorithm name, as reported
+// by [verifier.Named]. They are only tried after all other
+// Verifiers skipped an encoded string.
+//
+// This is meant for formats without an identifier, like md5plain,
+// which recognizes any string of 32 hex characters. Such a Verifier
+// fails instead of skipping hashes of other formats which look
+// alike, shadowing the Verifiers registered after it:
+//
+//	passwap.WithLastResortVerifiers(md5plain.Algorithm)
+//
+// The Hasher is always tried first. The order of the moved
+// Verifiers among themselves is kept.
+func WithLastResortVerifiers(names ...string) Option {
+	return func(s *Swapper) {
+		order := make([]int, 0, len(s.verifiers))
+		var last []int
+		for i, v := range s.verifiers {
+			if i > 0 && hasAlgorithm(v, names) {
+				last = append(last, i)
+			} else {
+				order = append(order, i)
+			}
+		}
+		order = append(order, last...)
+
+		s.verifiers = permute(s.verifiers, order)
+		s.current = permute(s.current, order)
+		s.legacy = permute(s.legacy, order)
+		s.index = newPrefixIndex(s.verifiers)
+	}
+}
+
+// permute returns the elements of s in order of the indices.
+// Nil is returned for a nil s.
+func permute[T any](s []T, order []int) []T {
+	if s == nil {
+		return nil
+	}
+	out := make([]T, len(order))
+	for i, j := range order {
+		out[i] = s[j]
+	}
+	return out
+}
+
+// isLegacy reports if the Verifier at index i is marked as legacy.
+func (s *Swapper) isLegacy(i int) bool {
+	return i < len(s.legacy) && s.legacy[i]
+}
+
+// isCurrent reports if the Verifier at index i
+// is the Hasher or marked as current,
+// and is not marked as legacy.
+func (s *Swapper) isCurrent(i int) bool {
+	if s.isLegacy(i) {
+		return false
+	}
+	return i == 0 || (i < len(s.current) && s.current[i])
+}
+
+// WithHashSelfValidation makes the Swapper check every hash it
+// creates with [Swapper.Validate], before it is returned.
+// An error is returned instead of a hash which would not pass
+// validation, which is a sign that the parameters of the Hasher
+// are outside of the bounds of its validation options.
+// This applies to [Swapper.Hash] and to updated hashes.
+func WithHashSelfValidation() Option {
+	return func(s *Swapper) {
+		s.selfValidate = true
+	}
+}
+
+// WithValidateOnVerify makes the Swapper validate a hash after
+// the password was verified, when the matching Verifier implements
+// [verifier.Validator]. A hash which does not pass validation is
+// treated as outdated, like a NeedUpdate result, so that weak hashes
+// are updated on login, even when their parameters match the Hasher.
+// Hashes of Verifiers which don't implement verifier.Validator
+// are not affected.
+func WithValidateOnVerify() Option {
+	return func(s *Swapper) {
+		s.verifyValidate = true
+	}
+}
+
+// invalid reports whether encoded, matched by the Verifier at index i,
+// does not pass its validation. It is always false unless
+// the Swapper was configured [WithValidateOnVerify].
+func (s *Swapper) invalid(i int, encoded string) bool {
+	if !s.verifyValidate {
+		return false
+	}
+	validator, ok := s.verifiers[i].(verifier.Validator)
+	return ok && validator.Validate(encoded) != nil
+}
+
+// encodePassword using the configured password encoding, if any.
+func (s *Swapper) encodePassword(password string) (string, error) {
+	if s.pwEncoding == nil {
+		return password, nil
+	}
+	encoded, err := s.pwEncoding(password)
+	if err != nil {
+		return "", fmt.Errorf("passwap: password encoding: %w", err)
+	}
+	return encoded, nil
+}
+
 // SkipErrors is only returned when multiple
 // Verifiers matched an encoding string,
 // but encountered an error decoding it.
@@ -88,10 +451,124 @@ func (e SkipErrors) Error() string {
 // If the used Verifier is different from the the current
 // Hasher or the cost parameters differ, an updated encoded hash
 // string is returned for the same (valid) password.
+// See [WithCurrentVerifiers] to consider other Verifiers current.
 // In all other cases updated remains empty.
 // When updated is not empty, it must be stored until next use.
 func (s *Swapper) Verify(encoded, password string) (updated string, err error) {
-	return s.verifyAndUpdate(encoded, password, password)
+	if s.cache == nil {
+		return s.verifyAndUpdate(encoded, password, password)
+	}
+	if s.cache.get(encoded, password) {
+		return "", nil
+	}
+	updated, err = s.verifyAndUpdate(encoded, password, password)
+	if err == nil && updated == "" {
+		s.cache.add(encoded, password)
+	}
+	return updated, err
+}
+
+// Matches is a convenience wrapper around [Swapper.Verify],
+// for callers that only need to know if password is correct.
+// ok is true when password matches the encoded hash,
+// including when an updated hash is returned.
+// A wrong password results in ok being false and a nil error.
+// err is only set when the encoded string could not be verified,
+// for example because it couldn't be parsed or no Verifier was found.
+// When updated is not empty, it must be stored until next use.
+func (s *Swapper) Matches(encoded, password string) (ok bool, updated string, err error) {
+	updated, err = s.Verify(encoded, password)
+	if errors.Is(err, ErrPasswordMismatch) {
+		return false, "", nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	return true, updated, nil
+}
+
+// VerifyWithTimeout operates like [Swapper.Verify], only
+// verification runs on a separate goroutine and ErrVerifyTimeout
+// is returned when it takes longer than d.
+// This allows endpoints with strict latency requirements to
+// abandon an overrunning key derivation.
+//
+// The key derivation can't be interrupted: after a timeout
+// it keeps running in the background until it completes,
+// so the CPU and memory are still spent. Its result,
+// including any updated hash, is discarded.
+func (s *Swapper) VerifyWithTimeout(encoded, password string, d time.Duration) (updated string, err error) {
+	type result struct {
+		updated string
+		err     error
+	}
+	// buffered, so the goroutine doesn't block after a timeout.
+	done := make(chan result, 1)
+	go func() {
+		updated, err := s.Verify(encoded, password)
+		done <- result{updated, err}
+	}()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case r := <-done:
+		return r.updated, r.err
+	case <-timer.C:
+		return "", ErrVerifyTimeout
+	}
+}
+
+// VerifyShadow operates like [Swapper.Verify], and also hashes password
+// with the shadow Hasher when it matches encoded, for example to compare
+// the results of a new algorithm offline before rolling it out.
+// The result of the verification is not affected by the shadow Hasher:
+// shadowEncoded remains empty when verification fails
+// or the shadow Hasher returns an error.
+// shadowEncoded does not contain the stored prefix, see [WithStoredPrefix].
+func (s *Swapper) VerifyShadow(encoded, password string, shadow Hasher) (updated, shadowEncoded string, err error) {
+	updated, err = s.Verify(encoded, password)
+	if err != nil {
+		return "", "", err
+	}
+	if password, err = s.encodePassword(password); err != nil {
+		return updated, "", nil
+	}
+	shadowEncoded, err = shadow.Hash(password)
+	if err != nil {
+		return updated, "", nil
+	}
+	return updated, shadowEncoded, nil
+}
+
+// VerifyInfo describes the Verifier used by [Swapper.VerifyWithInfo].
+type VerifyInfo struct {
+	// Algorithm of the Verifier, as reported by [verifier.Named].
+	// Empty when the Verifier does not implement verifier.Named.
+	Algorithm string
+	// Warning is set when the password matched, but the hash
+	// needs attention. It wraps [ErrLegacyAlgorithm] for Verifiers
+	// marked as legacy, see [WithLegacyVerifiers].
+	Warning error
+}
+
+// VerifyWithInfo operates like [Swapper.Verify], and also
+// returns info about the Verifier that verified the password.
+// Info is only set when the password matched.
+// The verification cache is not used, see [WithVerificationCache].
+func (s *Swapper) VerifyWithInfo(encoded, password string) (updated string, info VerifyInfo, err error) {
+	updated, i, err := s.verifyAndUpdateIndex(encoded, password, password)
+	if err != nil && !errors.As(err, new(*RehashRequiredError)) {
+		return "", VerifyInfo{}, err
+	}
+	if n, ok := s.verifiers[i].(verifier.Named); ok {
+		info.Algorithm = n.Algorithm()
+	}
+	if s.isLegacy(i) {
+		info.Warning = fmt.Errorf("%w %q", ErrLegacyAlgorithm, info.Algorithm)
+	}
+	return updated, info, err
 }
 
 // VerifyAndUpdate operates like [Verify], only it always returns a new encoded
@@ -104,33 +581,109 @@ func (s *Swapper) VerifyAndUpdate(encoded, oldPassword, newPassword string) (upd
 	return s.verifyAndUpdate(encoded, oldPassword, newPassword)
 }
 
+// VerifyNotReused checks newPassword against a history of
+// previously stored encoded hashes, to enforce policies
+// which forbid reuse of the last N passwords.
+// ErrPasswordReused is returned when newPassword matches
+// any of the hashes in history.
+// Entries which can't be verified, for example because
+// no Verifier was found, result in an error, so that
+// the policy is not silently bypassed.
+func (s *Swapper) VerifyNotReused(newPassword string, history []string) error {
+	password, err := s.encodePassword(newPassword)
+	if err != nil {
+		return err
+	}
+	for _, encoded := range history {
+		encoded, err := s.decode(encoded)
+		if err != nil {
+			return err
+		}
+		result, _, err := s.verify(encoded, password)
+		if err != nil {
+			return err
+		}
+		if result != verifier.Fail {
+			return ErrPasswordReused
+		}
+	}
+	return nil
+}
+
 // verifyAndUpdate operates like documented for [Verify].
 // When oldPassword and newPassword are not equal, an update is
 // always triggered.
 func (s *Swapper) verifyAndUpdate(encoded, oldPassword, newPassword string) (updated string, err error) {
+	updated, _, err = s.verifyAndUpdateIndex(encoded, oldPassword, newPassword)
+	return updated, err
+}
+
+// verifyAndUpdateIndex operates like [Swapper.verifyAndUpdate],
+// and also returns the index of the Verifier that matched.
+func (s *Swapper) verifyAndUpdateIndex(encoded, oldPassword, newPassword string) (updated string, i int, err error) {
+	if encoded, err = s.decode(encoded); err != nil {
+		return "", -1, err
+	}
+	if oldPassword, err = s.encodePassword(oldPassword); err != nil {
+		return "", -1, err
+	}
+	if newPassword, err = s.encodePassword(newPassword); err != nil {
+		return "", -1, err
+	}
+	result, i, err := s.verify(encoded, oldPassword)
+	if err != nil {
+		return "", i, err
+	}
+
+	if result == verifier.OK && s.invalid(i, encoded) {
+		result = verifier.NeedUpdate
+	}
+
+	switch result {
+	case verifier.OK:
+		if s.isCurrent(i) && oldPassword == newPassword {
+			return "", i, nil
+		}
+
+		// the first Verifier is the Hasher.
+		// Any other Verifier, which is not current
+		// or is legacy, should trigger an update.
+		updated, err = s.update(newPassword, oldPassword == newPassword)
+		return updated, i, err
+
+	case verifier.NeedUpdate:
+		updated, err = s.update(newPassword, oldPassword == newPassword)
+		return updated, i, err
+
+	default:
+		return "", i, ErrPasswordMismatch
+	}
+}
+
+// verify password against encoded, using the first Verifier
+// that does not Skip. Verifiers which don't recognize the
+// prefix of encoded are not called, see [verifier.Prefixed].
+// The Result and index of that Verifier are returned. A Fail result is only returned without error,
+// Fail with an error from the Verifier is returned as error.
+// ErrNoVerifier or the Skip errors are returned
+// when all Verifiers Skip.
+// A Verifier which panics is treated as Skip,
+// with an error wrapping ErrVerifierPanic.
+func (s *Swapper) verify(encoded, password string) (result verifier.Result, i int, err error) {
 	var errs SkipErrors
 
-	for i, v := range s.verifiers {
-		result, err := v.Verify(encoded, oldPassword)
+	for _, i := range s.index.candidates(encoded) {
+		result, err := s.callVerifier(i, encoded, password)
 
 		switch result {
 		case verifier.Fail:
 			if err != nil {
-				return "", fmt.Errorf("passwap: %w", err)
+				return result, i, fmt.Errorf("passwap: %w", err)
 			}
-			return "", ErrPasswordMismatch
+			return result, i, nil
 
-		case verifier.OK:
-			if i == 0 && oldPassword == newPassword {
-				return "", nil
-			}
-
-			// the first Verifier is the Hasher.
-			// Any other Verifier should trigger an update.
-			return s.Hash(newPassword)
-
-		case verifier.NeedUpdate:
-			return s.Hash(newPassword)
+		case verifier.OK, verifier.NeedUpdate:
+			return result, i, nil
 
 		case verifier.Skip:
 			if err != nil {
@@ -139,24 +692,351 @@ func (s *Swapper) verifyAndUpdate(encoded, oldPassword, newPassword string) (upd
 			continue
 
 		default:
-			return "", fmt.Errorf("passwap: (BUG) verifier %d returned invalid result N %d", i, result)
+			return result, i, fmt.Errorf("passwap: (BUG) verifier %d returned invalid result N %d", i, result)
 		}
 	}
 
 	switch len(errs) {
 	case 0:
-		return "", ErrNoVerifier
+		return verifier.Skip, -1, ErrNoVerifier
 
 	case 1:
-		return "", fmt.Errorf("passwap: %w", errs[0])
+		return verifier.Skip, -1, wrapSkipError(errs[0])
 
 	default:
-		return "", errs
+		return verifier.Skip, -1, errs
+	}
+}
+
+// wrapSkipError prefixes the error of a skipping Verifier with the
+// package name. Errors wrapping ErrVerifierPanic are created by
+// the Swapper and already carry the prefix.
+func wrapSkipError(err error) error {
+	if errors.Is(err, ErrVerifierPanic) {
+		return err
+	}
+	return fmt.Errorf("passwap: %w", err)
+}
+
+// callVerifier calls Verify of the Verifier at index i,
+// recovering from a panic, so that a single faulty
+// Verifier can't crash the calling process.
+func (s *Swapper) callVerifier(i int, encoded, password string) (result verifier.Result, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = verifier.Skip, fmt.Errorf("%w: verifier %d: %v", ErrVerifierPanic, i, r)
+		}
+	}()
+	return s.verifiers[i].Verify(encoded, password)
+}
+
+// update returns a new hash of password.
+// When outdated is true and the Swapper rejects outdated hashes,
+// the new hash is returned in a RehashRequiredError instead.
+func (s *Swapper) update(password string, outdated bool) (updated string, err error) {
+	updated, err = s.hash(password)
+	if err != nil || !outdated || !s.rejectOutdated {
+		return updated, err
 	}
+	return "", &RehashRequiredError{Updated: updated}
+}
+
+// SupportedAlgorithms returns the names of the algorithms
+// the Swapper can verify, for example to report them from a
+// health endpoint. The names are reported by the Hasher and
+// Verifiers which implement [verifier.Named], in the order
+// they are configured and without duplicates.
+// Verifiers that don't implement verifier.Named are omitted.
+func (s *Swapper) SupportedAlgorithms() []string {
+	names := make([]string, 0, len(s.verifiers))
+	seen := make(map[string]bool, len(s.verifiers))
+
+	for _, v := range s.verifiers {
+		n, ok := v.(verifier.Named)
+		if !ok || seen[n.Algorithm()] {
+			continue
+		}
+		seen[n.Algorithm()] = true
+		names = append(names, n.Algorithm())
+	}
+	return names
 }
 
 // Hash returns a new encoded password hash using the
 // configured Hasher.
 func (s *Swapper) Hash(password string) (encoded string, err error) {
-	return s.h.Hash(password)
+	if password, err = s.encodePassword(password); err != nil {
+		return "", err
+	}
+	return s.hash(password)
+}
+
+// Rehash returns a new encoded hash of password using the
+// configured Hasher, without verifying it against a stored hash.
+// It is equivalent to [Swapper.Hash] and meant for forced upgrades,
+// for example when an administrator resets the hashes of users
+// while the plaintext password is known from an authenticated session.
+func (s *Swapper) Rehash(password string) (encoded string, err error) {
+	return s.Hash(password)
+}
+
+// RehashIfNeeded operates like [Swapper.Verify], only it
+// returns encoded unchanged when no update is needed,
+// instead of an empty string. The returned string
+// can therefore always be stored.
+func (s *Swapper) RehashIfNeeded(encoded, password string) (string, error) {
+	updated, err := s.Verify(encoded, password)
+	if err != nil {
+		return "", err
+	}
+	if updated == "" {
+		return encoded, nil
+	}
+	return updated, nil
+}
+
+// hash an encoded password with the Hasher,
+// and validate the result when configured.
+// The stored prefix is added to the result.
+func (s *Swapper) hash(password string) (encoded string, err error) {
+	encoded, err = s.h.Hash(password)
+	if err != nil {
+		return "", err
+	}
+	if s.selfValidate {
+		if err = s.validate(encoded); err != nil {
+			return "", fmt.Errorf("passwap: hash self validation: %w", err)
+		}
+	}
+	return s.storedPrefix + encoded, nil
+}
+
+// Validate checks the parameters of encoded, using the first
+// Verifier which recognizes encoded through [verifier.Identifier]
+// and implements [verifier.Validator].
+// Verifiers which implement only one of both interfaces are
+// not used. ErrNoVerifier is returned when no Verifier
+// recognizes encoded, and a parse error when encoded is
+// recognized, but malformed.
+func (s *Swapper) Validate(encoded string) error {
+	encoded, err := s.decode(encoded)
+	if err != nil {
+		return err
+	}
+	return s.validate(encoded)
+}
+
+func (s *Swapper) validate(encoded string) error {
+	for _, v := range s.verifiers {
+		identifier, ok := v.(verifier.Identifier)
+		if !ok {
+			continue
+		}
+		validator, ok := v.(verifier.Validator)
+		if !ok {
+			continue
+		}
+		params, err := identifier.Identify(encoded)
+		if err != nil {
+			return fmt.Errorf("passwap: %w", err)
+		}
+		if params == nil {
+			continue
+		}
+		return validator.Validate(encoded)
+	}
+	return ErrNoVerifier
+}
+
+// HashPreview returns a new encoded password hash using the
+// configured Hasher, like [Swapper.Hash].
+// It also returns the parameters of the resulting hash,
+// as identified by the Hasher. This allows showing the
+// algorithm and cost parameters in admin interfaces,
+// before the hash is stored.
+// Params is nil if the Hasher does not implement
+// [verifier.Identifier].
+func (s *Swapper) HashPreview(password string) (encoded string, params map[string]any, err error) {
+	encoded, err = s.Hash(password)
+	if err != nil {
+		return "", nil, err
+	}
+	identifier, ok := s.h.(verifier.Identifier)
+	if !ok {
+		return encoded, nil, nil
+	}
+	params, err = identifier.Identify(strings.TrimPrefix(encoded, s.storedPrefix))
+	if err != nil {
+		return "", nil, fmt.Errorf("passwap: %w", err)
+	}
+	return encoded, params, nil
+}
+
+// estimatePassword is verified against encoded hashes by
+// [Swapper.EstimateCost]. It is not expected to match.
+const estimatePassword = "passwap-estimate-cost"
+
+// EstimateCost returns a rough estimate of the amount of password
+// guesses per second an attacker could try against encoded,
+// with hardware comparable to the current machine.
+// A single verification is timed, using the first Verifier
+// that recognizes encoded, selected like in [Swapper.Verify].
+// A Verifier which panics is treated as Skip. The result is noisy and should only
+// be used to compare the strength of parameters, for example
+// by security teams auditing stored hashes.
+// Note that dedicated hardware may be orders of magnitude faster.
+func (s *Swapper) EstimateCost(encoded string) (hashesPerSecond float64, err error) {
+	if encoded, err = s.decode(encoded); err != nil {
+		return 0, err
+	}
+	return s.estimateCost(encoded)
+}
+
+func (s *Swapper) estimateCost(encoded string) (hashesPerSecond float64, err error) {
+	var errs SkipErrors
+
+	for _, i := range s.index.candidates(encoded) {
+		start := time.Now()
+		result, err := s.callVerifier(i, encoded, estimatePassword)
+		elapsed := time.Since(start)
+
+		if result == verifier.Skip {
+			if err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("passwap: %w", err)
+		}
+		return float64(time.Second) / float64(elapsed), nil
+	}
+
+	switch len(errs) {
+	case 0:
+		return 0, ErrNoVerifier
+	case 1:
+		return 0, wrapSkipError(errs[0])
+	default:
+		return 0, errs
+	}
+}
+
+// CostGap returns how far the cost of encoded is below the cost
+// of the Hasher's configuration, to prioritize migrations during
+// gradual cost increases. The gap is the difference of the
+// [verifier.Parameters] Strength, which is the number of cost
+// doublings missing, so a bcrypt hash of cost 10 has a gap
+// of 2 against a Hasher with cost 12.
+// Zero is returned when encoded is at or above the target.
+//
+// encoded is parsed by the first Verifier that implements
+// [verifier.Parser] and recognizes it, selected like in [Swapper.Verify].
+// The target is reported by the Hasher, without hashing,
+// which must implement [verifier.Configured]. As Strength is calibrated
+// across algorithms, the gap can be compared across algorithms.
+// ErrNoVerifier is returned when no Parser recognizes encoded
+// and ErrHashParameters when the Hasher doesn't report its parameters.
+func (s *Swapper) CostGap(encoded string) (gap float64, err error) {
+	if encoded, err = s.decode(encoded); err != nil {
+		return 0, err
+	}
+	stored, err := s.parse(encoded)
+	if err != nil {
+		return 0, err
+	}
+
+	target := s.hashParameters()
+	if target == nil {
+		return 0, ErrHashParameters
+	}
+
+	if gap := target.Strength() - stored.Strength(); gap > 0 {
+		return float64(gap), nil
+	}
+	return 0, nil
+}
+
+// hashParameters returns the Parameters of new hashes,
+// or nil when the Hasher doesn't implement verifier.Configured.
+func (s *Swapper) hashParameters() verifier.Parameters {
+	if c, ok := s.h.(verifier.Configured); ok {
+		return c.HashParameters()
+	}
+	return nil
+}
+
+// parse encoded with the first candidate Verifier
+// that implements verifier.Parser and recognizes it.
+func (s *Swapper) parse(encoded string) (verifier.Parameters, error) {
+	for _, i := range s.index.candidates(encoded) {
+		parser, ok := s.verifiers[i].(verifier.Parser)
+		if !ok {
+			continue
+		}
+		params, err := parser.Parse(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("passwap: %w", err)
+		}
+		if params != nil {
+			return params, nil
+		}
+	}
+	return nil, ErrNoVerifier
+}
+
+// selfCheckPassword is hashed and verified by [Swapper.SelfCheck].
+const selfCheckPassword = "passwap-self-check"
+
+// SelfCheck hashes a throwaway password with the Hasher and
+// verifies that the result round-trips. When the Hasher implements
+// [verifier.Validator], the new hash must also pass validation.
+// This allows applications to fail fast at startup on
+// misconfigured parameters, instead of at the first request.
+// Note that this performs a full hash and verification,
+// so it takes as long as two logins.
+func (s *Swapper) SelfCheck() error {
+	encoded, err := s.Hash(selfCheckPassword)
+	if err != nil {
+		return fmt.Errorf("passwap: self check: %w", err)
+	}
+	encoded = strings.TrimPrefix(encoded, s.storedPrefix)
+	if v, ok := s.h.(verifier.Validator); ok {
+		if err = v.Validate(encoded); err != nil {
+			return fmt.Errorf("passwap: self check: %w", err)
+		}
+	}
+	password, err := s.encodePassword(selfCheckPassword)
+	if err != nil {
+		return err
+	}
+	result, err := s.h.Verify(encoded, password)
+	if err != nil {
+		return fmt.Errorf("passwap: self check: %w", err)
+	}
+	if result != verifier.OK {
+		return fmt.Errorf("passwap: self check: Hasher returned %s for its own hash", result)
+	}
+	return nil
+}
+
+// Close releases resources held by the Hasher and Verifiers,
+// for those that implement [io.Closer].
+// For example remote or hardware backed key derivation functions
+// may hold connections. The verifiers provided by passwap don't
+// hold resources, so closing them is a no-op.
+// All Closers are closed and any errors are joined.
+func (s *Swapper) Close() error {
+	var errs []error
+	for _, v := range s.verifiers {
+		if c, ok := v.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("passwap: close: %w", err)
+	}
+	return nil
 }
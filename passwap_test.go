@@ -1,14 +1,96 @@
 package passwap
 
 import (
+	"crypto/md5"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/zitadel/passwap/argon2"
+	"github.com/zitadel/passwap/bcrypt"
+	"github.com/zitadel/passwap/descrypt"
+	"github.com/zitadel/passwap/devise"
+	"github.com/zitadel/passwap/django"
+	"github.com/zitadel/passwap/dovecot"
 	tv "github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/iteratedhash"
+	md5crypt "github.com/zitadel/passwap/md5"
+	"github.com/zitadel/passwap/md5plain"
+	"github.com/zitadel/passwap/pbkdf2"
+	"github.com/zitadel/passwap/saltedhex"
 	"github.com/zitadel/passwap/scrypt"
+	"github.com/zitadel/passwap/sha1crypt"
+	"github.com/zitadel/passwap/tomcat"
 	"github.com/zitadel/passwap/verifier"
+	"github.com/zitadel/passwap/wordpress"
+)
+
+// Compile time checks that all hashers and verifiers
+// implement the interfaces, as the algorithm packages
+// can't import passwap themselves.
+var (
+	_ Hasher = (*argon2.Hasher)(nil)
+	_ Hasher = (*bcrypt.Hasher)(nil)
+	_ Hasher = md5crypt.Hasher{}
+	_ Hasher = (*pbkdf2.Hasher)(nil)
+	_ Hasher = (*scrypt.Hasher)(nil)
+	_ Hasher = (*sha1crypt.Hasher)(nil)
+
+	_ verifier.Identifier = (*argon2.Hasher)(nil)
+	_ verifier.Identifier = (*bcrypt.Hasher)(nil)
+	_ verifier.Identifier = md5crypt.Hasher{}
+	_ verifier.Identifier = (*pbkdf2.Hasher)(nil)
+	_ verifier.Identifier = (*scrypt.Hasher)(nil)
+	_ verifier.Identifier = (*sha1crypt.Hasher)(nil)
+
+	_ verifier.Prefixed = (*argon2.Hasher)(nil)
+	_ verifier.Prefixed = (*bcrypt.Hasher)(nil)
+	_ verifier.Prefixed = md5crypt.Hasher{}
+	_ verifier.Prefixed = (*pbkdf2.Hasher)(nil)
+	_ verifier.Prefixed = (*scrypt.Hasher)(nil)
+	_ verifier.Prefixed = (*sha1crypt.Hasher)(nil)
+
+	_ verifier.Parser = (*argon2.Hasher)(nil)
+	_ verifier.Parser = (*bcrypt.Hasher)(nil)
+	_ verifier.Parser = (*pbkdf2.Hasher)(nil)
+	_ verifier.Parser = (*scrypt.Hasher)(nil)
+
+	_ verifier.Validator = (*argon2.Hasher)(nil)
+	_ verifier.Validator = (*bcrypt.Hasher)(nil)
+	_ verifier.Validator = (*pbkdf2.Hasher)(nil)
+	_ verifier.Validator = (*scrypt.Hasher)(nil)
+
+	_ verifier.Named = (*argon2.Hasher)(nil)
+	_ verifier.Named = (*bcrypt.Hasher)(nil)
+	_ verifier.Named = md5crypt.Hasher{}
+	_ verifier.Named = (*pbkdf2.Hasher)(nil)
+	_ verifier.Named = (*scrypt.Hasher)(nil)
+	_ verifier.Named = (*sha1crypt.Hasher)(nil)
+	_ verifier.Named = (*devise.Verifier)(nil)
+	_ verifier.Named = (*iteratedhash.Verifier)(nil)
+	_ verifier.Named = (*saltedhex.Verifier)(nil)
+	_ verifier.Named = (*tomcat.Verifier)(nil)
+
+	_ verifier.Verifier = argon2.Verifier
+	_ verifier.Verifier = bcrypt.Verifier
+	_ verifier.Verifier = descrypt.Verifier
+	_ verifier.Verifier = (*devise.Verifier)(nil)
+	_ verifier.Verifier = django.Verifier
+	_ verifier.Verifier = dovecot.Verifier
+	_ verifier.Verifier = (*iteratedhash.Verifier)(nil)
+	_ verifier.Verifier = md5crypt.Verifier
+	_ verifier.Verifier = md5plain.Verifier
+	_ verifier.Verifier = pbkdf2.Verifier
+	_ verifier.Verifier = (*saltedhex.Verifier)(nil)
+	_ verifier.Verifier = scrypt.Verifier
+	_ verifier.Verifier = sha1crypt.Verifier
+	_ verifier.Verifier = (*tomcat.Verifier)(nil)
+	_ verifier.Verifier = wordpress.Verifier
 )
 
 var (
@@ -36,6 +118,29 @@ var (
 	testSwapper = NewSwapper(testHasher, mockV, scrypt.Verifier)
 )
 
+// hasherFunc is a Hasher which only implements Hash,
+// its Verify method always skips.
+type hasherFunc func(password string) (string, error)
+
+func (h hasherFunc) Hash(password string) (string, error) {
+	return h(password)
+}
+
+func (hasherFunc) Verify(string, string) (verifier.Result, error) {
+	return verifier.Skip, nil
+}
+
+// validatingHasher is a Hasher which
+// implements verifier.Validator.
+type validatingHasher struct {
+	Hasher
+	err error
+}
+
+func (h validatingHasher) Validate(string) error {
+	return h.err
+}
+
 func TestNewSwapper(t *testing.T) {
 	want := &Swapper{
 		h:         testHasher,
@@ -46,6 +151,16 @@ func TestNewSwapper(t *testing.T) {
 	}
 }
 
+func TestSwapper_Apply(t *testing.T) {
+	s := NewSwapper(testHasher)
+	if got := s.Apply(WithTrimSpace()); got != s {
+		t.Errorf("Swapper.Apply() = %p, want %p", got, s)
+	}
+	if !s.trimSpace {
+		t.Error("Swapper.Apply() did not apply option")
+	}
+}
+
 func TestMultiError(t *testing.T) {
 	const (
 		want = "passwap multiple parse errors: foo; bar"
@@ -68,6 +183,270 @@ func TestSwapper_Verify(t *testing.T) {
 	}
 }
 
+func TestSwapper_Matches(t *testing.T) {
+	tests := []struct {
+		name        string
+		encoded     string
+		password    string
+		wantOK      bool
+		wantUpdated bool
+		wantErr     bool
+	}{
+		{
+			name:     "ok",
+			encoded:  tv.Argon2idEncoded,
+			password: tv.Password,
+			wantOK:   true,
+		},
+		{
+			name:        "ok with update",
+			encoded:     tv.Argon2iEncoded,
+			password:    tv.Password,
+			wantOK:      true,
+			wantUpdated: true,
+		},
+		{
+			name:     "wrong password",
+			encoded:  tv.Argon2idEncoded,
+			password: "foobar",
+		},
+		{
+			name:     "unparseable",
+			encoded:  "$argon2id$foo",
+			password: tv.Password,
+			wantErr:  true,
+		},
+		{
+			name:     "no verifier",
+			encoded:  "foobar",
+			password: tv.Password,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOK, gotUpdated, err := testSwapper.Matches(tt.encoded, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Swapper.Matches() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotOK != tt.wantOK {
+				t.Errorf("Swapper.Matches() ok = %v, want %v", gotOK, tt.wantOK)
+			}
+			if (gotUpdated != "") != tt.wantUpdated {
+				t.Errorf("Swapper.Matches() updated = %v, want %v", gotUpdated, tt.wantUpdated)
+			}
+		})
+	}
+}
+
+func TestSwapper_VerifyWithTimeout(t *testing.T) {
+	if _, err := testSwapper.VerifyWithTimeout(tv.ScryptEncoded, tv.Password, time.Nanosecond); !errors.Is(err, ErrVerifyTimeout) {
+		t.Errorf("Swapper.VerifyWithTimeout() error = %v, want %v", err, ErrVerifyTimeout)
+	}
+
+	updated, err := testSwapper.VerifyWithTimeout(tv.ScryptEncoded, tv.Password, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated == "" {
+		t.Error("Swapper.VerifyWithTimeout() did not return an updated hash")
+	}
+	if _, err := testSwapper.VerifyWithTimeout(tv.ScryptEncoded, "foobar", time.Minute); !errors.Is(err, ErrPasswordMismatch) {
+		t.Errorf("Swapper.VerifyWithTimeout() error = %v, want %v", err, ErrPasswordMismatch)
+	}
+}
+
+func TestSwapper_VerifyShadow(t *testing.T) {
+	shadow := bcrypt.New(bcrypt.MinCost)
+	tests := []struct {
+		name        string
+		encoded     string
+		password    string
+		shadow      Hasher
+		wantUpdated bool
+		wantShadow  bool
+		wantErr     error
+	}{
+		{
+			name:       "ok",
+			encoded:    tv.Argon2idEncoded,
+			password:   tv.Password,
+			shadow:     shadow,
+			wantShadow: true,
+		},
+		{
+			name:        "ok with update",
+			encoded:     tv.Argon2iEncoded,
+			password:    tv.Password,
+			shadow:      shadow,
+			wantUpdated: true,
+			wantShadow:  true,
+		},
+		{
+			name:     "wrong password",
+			encoded:  tv.Argon2idEncoded,
+			password: "foobar",
+			shadow:   shadow,
+			wantErr:  ErrPasswordMismatch,
+		},
+		{
+			name:     "no verifier",
+			encoded:  "foobar",
+			password: tv.Password,
+			shadow:   shadow,
+			wantErr:  ErrNoVerifier,
+		},
+		{
+			name:     "shadow error",
+			encoded:  tv.Argon2idEncoded,
+			password: tv.Password,
+			shadow: hasherFunc(func(string) (string, error) {
+				return "", errors.New("oops!")
+			}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotUpdated, gotShadow, err := testSwapper.VerifyShadow(tt.encoded, tt.password, tt.shadow)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Swapper.VerifyShadow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (gotUpdated != "") != tt.wantUpdated {
+				t.Errorf("Swapper.VerifyShadow() updated = %v, want %v", gotUpdated, tt.wantUpdated)
+			}
+			if (gotShadow != "") != tt.wantShadow {
+				t.Errorf("Swapper.VerifyShadow() shadowEncoded = %v, want %v", gotShadow, tt.wantShadow)
+			}
+			if tt.wantShadow {
+				if res, err := shadow.Verify(gotShadow, tt.password); err != nil || res != verifier.OK {
+					t.Errorf("bcrypt.Hasher.Verify() = %s, %v, want %s", res, err, verifier.OK)
+				}
+			}
+		})
+	}
+}
+
+func TestSwapper_Verify_errors(t *testing.T) {
+	s := NewSwapper(argon2.NewArgon2id(argon2.RecommendedIDParams),
+		argon2.Verifier,
+		bcrypt.Verifier,
+		md5crypt.Verifier,
+		md5plain.Verifier,
+		pbkdf2.Verifier,
+		scrypt.Verifier,
+		sha1crypt.Verifier,
+		descrypt.Verifier,
+	)
+	tests := []struct {
+		name      string
+		encoded   string
+		malformed string
+	}{
+		{"argon2", tv.Argon2idEncoded, "$argon2id$v=19$m=foo"},
+		{"bcrypt", tv.EncodedBcrypt2b, "$2b$foo"},
+		{"md5", tv.MD5Encoded, "$1$foo"},
+		{"md5plain", tv.MD5PlainHex, ""},
+		{"pbkdf2", tv.Pbkdf2Sha1Encoded, "$pbkdf2$foo"},
+		{"scrypt", tv.ScryptEncoded, "$scrypt$foo"},
+		{"sha1crypt", tv.Sha1CryptEncoded, "$sha1$foo"},
+		{"descrypt", tv.DESCryptEncoded, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Verify(tt.encoded, tv.Password); err != nil {
+				t.Errorf("Swapper.Verify() error = %v", err)
+			}
+			if _, err := s.Verify(tt.encoded, "wrong"); !errors.Is(err, ErrPasswordMismatch) {
+				t.Errorf("Swapper.Verify() wrong password error = %v, want %v", err, ErrPasswordMismatch)
+			}
+			if tt.malformed == "" {
+				return
+			}
+			_, err := s.Verify(tt.malformed, tv.Password)
+			if err == nil || errors.Is(err, ErrPasswordMismatch) || errors.Is(err, ErrNoVerifier) {
+				t.Errorf("Swapper.Verify() malformed error = %v, want parse error", err)
+			}
+		})
+	}
+
+	if _, err := s.Verify("foobar", tv.Password); !errors.Is(err, ErrNoVerifier) {
+		t.Errorf("Swapper.Verify() unknown error = %v, want %v", err, ErrNoVerifier)
+	}
+}
+
+func TestSwapper_Verify_panic(t *testing.T) {
+	panics := verifier.VerifyFunc(func(encoded, password string) (verifier.Result, error) {
+		panic("malformed input")
+	})
+
+	s := NewSwapper(testHasher, panics)
+	_, err := s.Verify("foobar", tv.Password)
+	if !errors.Is(err, ErrVerifierPanic) {
+		t.Errorf("Swapper.Verify() error = %v, want %v", err, ErrVerifierPanic)
+	}
+	if want := "passwap: verifier panicked: verifier 1: malformed input"; err == nil || err.Error() != want {
+		t.Errorf("Swapper.Verify() error = %v, want %s", err, want)
+	}
+
+	s = NewSwapper(testHasher, panics, md5crypt.Verifier)
+	if _, err := s.Verify(tv.MD5Encoded, tv.Password); err != nil {
+		t.Errorf("Swapper.Verify() error = %v", err)
+	}
+}
+
+// TestHasher_concurrent runs concurrent Hash and Verify
+// calls on a single Hasher with the default random reader.
+// Run with -race to detect data races.
+func TestHasher_concurrent(t *testing.T) {
+	const goroutines = 8
+
+	hashers := map[string]Hasher{
+		"argon2":    testHasher,
+		"bcrypt":    bcrypt.New(bcrypt.MinCost),
+		"md5":       md5crypt.Hasher{},
+		"pbkdf2":    pbkdf2.NewSHA256(pbkdf2.Params{Rounds: tv.Pbkdf2Rounds, KeyLen: tv.KeyLen, SaltLen: tv.SaltLen}),
+		"scrypt":    scrypt.New(scrypt.Params{N: 1024, R: 8, P: 1, KeyLen: tv.KeyLen, SaltLen: tv.SaltLen}),
+		"sha1crypt": sha1crypt.New(sha1crypt.Params{Rounds: 1000, SaltLen: 8}),
+	}
+	for name, h := range hashers {
+		h := h
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var wg sync.WaitGroup
+			encoded := make([]string, goroutines)
+			errs := make([]error, goroutines)
+			for i := 0; i < goroutines; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					if encoded[i], errs[i] = h.Hash(tv.Password); errs[i] != nil {
+						return
+					}
+					res, err := h.Verify(encoded[i], tv.Password)
+					if err == nil && res != verifier.OK {
+						err = fmt.Errorf("Verify() = %s, want %s", res, verifier.OK)
+					}
+					errs[i] = err
+				}(i)
+			}
+			wg.Wait()
+
+			seen := make(map[string]bool, goroutines)
+			for i, err := range errs {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if seen[encoded[i]] {
+					t.Errorf("Hash() returned duplicate %s", encoded[i])
+				}
+				seen[encoded[i]] = true
+			}
+		})
+	}
+}
+
 func TestSwapper_VerifyAndUpdate(t *testing.T) {
 	type args struct {
 		encoded     string
@@ -105,6 +484,61 @@ func TestSwapper_VerifyAndUpdate(t *testing.T) {
 	}
 }
 
+func TestSwapper_Rehash(t *testing.T) {
+	encoded, err := testSwapper.Rehash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, err := testHasher.Verify(encoded, tv.Password); err != nil || res != verifier.OK {
+		t.Errorf("Hasher.Verify() = %s, %v, want %s", res, err, verifier.OK)
+	}
+}
+
+func TestSwapper_RehashIfNeeded(t *testing.T) {
+	tests := []struct {
+		name        string
+		encoded     string
+		password    string
+		wantChanged bool
+		wantErr     error
+	}{
+		{
+			name:     "no change",
+			encoded:  tv.Argon2idEncoded,
+			password: tv.Password,
+		},
+		{
+			name:        "change",
+			encoded:     tv.Argon2iEncoded,
+			password:    tv.Password,
+			wantChanged: true,
+		},
+		{
+			name:     "wrong password",
+			encoded:  tv.Argon2idEncoded,
+			password: "foobar",
+			wantErr:  ErrPasswordMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testSwapper.RehashIfNeeded(tt.encoded, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Swapper.RehashIfNeeded() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if (got != tt.encoded) != tt.wantChanged {
+				t.Errorf("Swapper.RehashIfNeeded() = %s, want changed %v", got, tt.wantChanged)
+			}
+			if res, err := testHasher.Verify(got, tt.password); tt.wantChanged && (err != nil || res != verifier.OK) {
+				t.Errorf("Hasher.Verify() = %s, %v, want %s", res, err, verifier.OK)
+			}
+		})
+	}
+}
+
 func TestSwapper_verifyAndUpdate(t *testing.T) {
 	type args struct {
 		encoded     string
@@ -181,31 +615,1048 @@ func TestSwapper_verifyAndUpdate(t *testing.T) {
 	}
 }
 
-func TestSwapper(t *testing.T) {
-	var (
-		updated string
-		err     error
-	)
+func TestSwapper_VerifyNotReused(t *testing.T) {
+	s := NewSwapper(argon2.NewArgon2id(argon2.RecommendedIDParams), scrypt.Verifier, md5plain.Verifier)
+	history := []string{tv.ScryptEncoded, tv.Argon2idEncoded, tv.MD5PlainHex}
 
-	// Use "outdated" argon2i to trigger update
-	t.Run("update", func(t *testing.T) {
-		updated, err = testSwapper.Verify(tv.Argon2iEncoded, tv.Password)
-		if err != nil {
-			t.Fatal(err)
-		}
-		if updated == "" {
-			t.Fatal("Swapp.Verify: updated empty")
+	tests := []struct {
+		name     string
+		password string
+		history  []string
+		wantErr  error
+	}{
+		{
+			name:     "empty history",
+			password: tv.Password,
+		},
+		{
+			name:     "fresh password",
+			password: "fresh",
+			history:  history,
+		},
+		{
+			name:     "reused password",
+			password: tv.Password,
+			history:  history,
+			wantErr:  ErrPasswordReused,
+		},
+		{
+			name:     "reused from other verifier",
+			password: tv.Password,
+			history:  []string{tv.MD5PlainHex},
+			wantErr:  ErrPasswordReused,
+		},
+		{
+			name:     "unknown entry",
+			password: "fresh",
+			history:  []string{tv.Argon2idEncoded, "foobar"},
+			wantErr:  ErrNoVerifier,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.VerifyNotReused(tt.password, tt.history)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Swapper.VerifyNotReused() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithTrimSpace(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoded  string
+		password string
+		wantErr  error
+	}{
+		{
+			name:     "trailing new line",
+			encoded:  tv.Argon2idEncoded + "\n",
+			password: tv.Password,
+		},
+		{
+			name:     "surrounding spaces",
+			encoded:  "  " + tv.Argon2idEncoded + "  ",
+			password: tv.Password,
+		},
+		{
+			name:     "password not trimmed",
+			encoded:  tv.Argon2idEncoded,
+			password: " " + tv.Password + "\n",
+			wantErr:  ErrPasswordMismatch,
+		},
+	}
+	s := NewSwapper(testHasher).Apply(WithTrimSpace())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, err := s.Verify(tt.encoded, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Swapper.Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if updated != "" {
+				t.Errorf("Swapper.Verify() updated = %s, want empty", updated)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		_, err := testSwapper.Verify("  "+tv.Argon2idEncoded, tv.Password)
+		if !errors.Is(err, ErrNoVerifier) {
+			t.Errorf("Swapper.Verify() error = %v, want %v", err, ErrNoVerifier)
 		}
 	})
+}
 
-	// Verify updated hash again, should be valid and without update
-	t.Run("no update", func(t *testing.T) {
-		updated, err = testSwapper.Verify(updated, tv.Password)
-		if err != nil {
-			t.Fatal(err)
+func TestWithStoredPrefix(t *testing.T) {
+	const prefix = "app1:"
+	s := NewSwapper(testHasher, bcrypt.Verifier).Apply(WithStoredPrefix(prefix), WithTrimSpace())
+
+	encoded, err := s.Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encoded, prefix+argon2.Prefix) {
+		t.Errorf("Swapper.Hash() = %s, want prefix %s", encoded, prefix+argon2.Prefix)
+	}
+
+	tests := []struct {
+		name        string
+		encoded     string
+		password    string
+		wantUpdated bool
+		wantErr     error
+	}{
+		{
+			name:     "round trip",
+			encoded:  encoded,
+			password: tv.Password,
+		},
+		{
+			name:     "trimmed",
+			encoded:  " " + prefix + tv.Argon2idEncoded + "\n",
+			password: tv.Password,
+		},
+		{
+			name:        "update",
+			encoded:     prefix + tv.EncodedBcrypt2b,
+			password:    tv.Password,
+			wantUpdated: true,
+		},
+		{
+			name:     "wrong password",
+			encoded:  encoded,
+			password: "spanac",
+			wantErr:  ErrPasswordMismatch,
+		},
+		{
+			name:     "missing prefix",
+			encoded:  tv.Argon2idEncoded,
+			password: tv.Password,
+			wantErr:  ErrStoredPrefix,
+		},
+		{
+			name:     "other prefix",
+			encoded:  "app2:" + tv.Argon2idEncoded,
+			password: tv.Password,
+			wantErr:  ErrStoredPrefix,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, err := s.Verify(tt.encoded, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Swapper.Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (updated != "") != tt.wantUpdated {
+				t.Fatalf("Swapper.Verify() updated = %q, want updated %t", updated, tt.wantUpdated)
+			}
+			if updated == "" {
+				return
+			}
+			if !strings.HasPrefix(updated, prefix) {
+				t.Errorf("Swapper.Verify() updated = %s, want prefix %s", updated, prefix)
+			}
+			if updated, err = s.Verify(updated, tt.password); err != nil || updated != "" {
+				t.Errorf("Swapper.Verify() of updated = %q, %v", updated, err)
+			}
+		})
+	}
+
+	if err = s.Apply(WithHashSelfValidation()).SelfCheck(); err != nil {
+		t.Errorf("Swapper.SelfCheck() error = %v", err)
+	}
+	if _, params, err := s.HashPreview(tv.Password); err != nil || params == nil {
+		t.Errorf("Swapper.HashPreview() = %v, %v", params, err)
+	}
+	if _, err = s.EstimateCost(tv.Argon2idEncoded); !errors.Is(err, ErrStoredPrefix) {
+		t.Errorf("Swapper.EstimateCost() error = %v, want %v", err, ErrStoredPrefix)
+	}
+}
+
+// windows1252 encodes the Latin-1 subset of Windows-1252,
+// which is sufficient for testing.
+func windows1252(password string) (string, error) {
+	out := make([]byte, 0, len(password))
+	for _, r := range password {
+		if r > 0xff {
+			return "", fmt.Errorf("rune %q not in Windows-1252", r)
 		}
-		if updated != "" {
+		out = append(out, byte(r))
+	}
+	return string(out), nil
+}
+
+func TestWithPasswordEncoding(t *testing.T) {
+	const password = "pässwörd"
+
+	cp1252, err := windows1252(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := md5.Sum([]byte(cp1252))
+	digest := hex.EncodeToString(sum[:])
+	if utf8Sum := md5.Sum([]byte(password)); utf8Sum == sum {
+		t.Fatal("UTF-8 and Windows-1252 digests are equal")
+	}
+
+	plain := NewSwapper(testHasher, md5plain.Verifier)
+	encoding := NewSwapper(testHasher, md5plain.Verifier).Apply(WithPasswordEncoding(windows1252))
+
+	t.Run("UTF-8 mismatch", func(t *testing.T) {
+		_, err := plain.Verify(digest, password)
+		if !errors.Is(err, ErrPasswordMismatch) {
+			t.Errorf("Swapper.Verify() error = %v, want %v", err, ErrPasswordMismatch)
+		}
+	})
+	t.Run("Windows-1252 match", func(t *testing.T) {
+		updated, err := encoding.Verify(digest, password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated == "" {
+			t.Fatal("Swapper.Verify() did not return updated")
+		}
+		// updated hash is created from the encoded password as well.
+		if res, err := testHasher.Verify(updated, cp1252); err != nil || res != verifier.OK {
+			t.Errorf("Hasher.Verify() = %s, %v, want %s", res, err, verifier.OK)
+		}
+		if _, err = encoding.Verify(updated, password); err != nil {
+			t.Errorf("Swapper.Verify() updated error = %v", err)
+		}
+	})
+	t.Run("encoding error", func(t *testing.T) {
+		_, err := encoding.Verify(digest, "ŝtrange")
+		if err == nil {
+			t.Error("Swapper.Verify() did not return error")
+		}
+		_, err = encoding.Hash("ŝtrange")
+		if err == nil {
+			t.Error("Swapper.Hash() did not return error")
+		}
+	})
+}
+
+func TestWithRejectOutdated(t *testing.T) {
+	s := NewSwapper(testHasher, scrypt.Verifier).Apply(WithRejectOutdated())
+
+	tests := []struct {
+		name    string
+		encoded string
+		wantErr error
+	}{
+		{
+			name:    "current",
+			encoded: tv.Argon2idEncoded,
+		},
+		{
+			name:    "argon2i under argon2id",
+			encoded: tv.Argon2iEncoded,
+			wantErr: ErrRehashRequired,
+		},
+		{
+			name:    "other verifier",
+			encoded: tv.ScryptEncoded,
+			wantErr: ErrRehashRequired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, err := s.Verify(tt.encoded, tv.Password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Swapper.Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if updated != "" {
+				t.Errorf("Swapper.Verify() updated = %s, want empty", updated)
+			}
+			if tt.wantErr == nil {
+				return
+			}
+			var target *RehashRequiredError
+			if !errors.As(err, &target) {
+				t.Fatalf("Swapper.Verify() error = %T, want %T", err, target)
+			}
+			if res, err := testHasher.Verify(target.Updated, tv.Password); err != nil || res != verifier.OK {
+				t.Errorf("Hasher.Verify(RehashRequiredError.Updated) = %s, %v, want %s", res, err, verifier.OK)
+			}
+		})
+	}
+
+	t.Run("password change", func(t *testing.T) {
+		updated, err := s.VerifyAndUpdate(tv.Argon2iEncoded, tv.Password, "newpassword")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated == "" {
+			t.Error("Swapper.VerifyAndUpdate() did not return updated")
+		}
+	})
+}
+
+func TestSwapper_HashPreview(t *testing.T) {
+	encoded, params, err := testSwapper.HashPreview(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, err := testHasher.Verify(encoded, tv.Password); err != nil || res != verifier.OK {
+		t.Errorf("Hasher.Verify() = %s, %v, want %s", res, err, verifier.OK)
+	}
+	want := map[string]any{
+		"identifier": argon2.Identifier_id,
+		"version":    19,
+		"memory":     testArgon2Params.Memory,
+		"time":       testArgon2Params.Time,
+		"threads":    testArgon2Params.Threads,
+		"key_len":    testArgon2Params.KeyLen,
+		"salt_len":   testArgon2Params.SaltLen,
+	}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("Swapper.HashPreview() params =\n%v\nwant\n%v", params, want)
+	}
+
+	t.Run("no identifier", func(t *testing.T) {
+		s := NewSwapper(hasherFunc(func(password string) (string, error) {
+			return "$mock$" + password, nil
+		}))
+		encoded, params, err := s.HashPreview(tv.Password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if encoded != "$mock$"+tv.Password || params != nil {
+			t.Errorf("Swapper.HashPreview() = %s, %v", encoded, params)
+		}
+	})
+}
+
+func TestSwapper(t *testing.T) {
+	var (
+		updated string
+		err     error
+	)
+
+	// Use "outdated" argon2i to trigger update
+	t.Run("update", func(t *testing.T) {
+		updated, err = testSwapper.Verify(tv.Argon2iEncoded, tv.Password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated == "" {
+			t.Fatal("Swapp.Verify: updated empty")
+		}
+	})
+
+	// Verify updated hash again, should be valid and without update
+	t.Run("no update", func(t *testing.T) {
+		updated, err = testSwapper.Verify(updated, tv.Password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated != "" {
 			t.Fatalf("Swapp.Verify: updated = %s", updated)
 		}
 	})
 }
+
+type closerVerifier struct {
+	verifier.VerifyFunc
+	closed int
+	err    error
+}
+
+func (c *closerVerifier) Close() error {
+	c.closed++
+	return c.err
+}
+
+func TestSwapper_Close(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr bool
+	}{
+		{
+			name: "success",
+		},
+		{
+			name:    "error",
+			err:     errors.New("oops!"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			closer := &closerVerifier{VerifyFunc: mockV, err: tt.err}
+			s := NewSwapper(testHasher, closer, scrypt.Verifier)
+
+			err := s.Close()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Swapper.Close() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Swapper.Close() error = %v, want %v", err, tt.err)
+			}
+			if closer.closed != 1 {
+				t.Errorf("Close called %d times, want 1", closer.closed)
+			}
+		})
+	}
+}
+
+func TestSwapper_EstimateCost(t *testing.T) {
+	low := argon2.Params{Time: 1, Memory: 1024, Threads: 1, KeyLen: 32, SaltLen: 16}
+	high := argon2.Params{Time: 4, Memory: 16 * 1024, Threads: 1, KeyLen: 32, SaltLen: 16}
+
+	lowEncoded, err := argon2.NewArgon2id(low).Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	highEncoded, err := argon2.NewArgon2id(high).Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewSwapper(argon2.NewArgon2id(low), scrypt.Verifier)
+
+	lowRate, err := s.EstimateCost(lowEncoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	highRate, err := s.EstimateCost(highEncoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lowRate <= 0 || highRate <= 0 {
+		t.Errorf("Swapper.EstimateCost() = %f, %f, want positive", lowRate, highRate)
+	}
+	if highRate >= lowRate {
+		t.Errorf("Swapper.EstimateCost() high cost = %f, want lower than %f", highRate, lowRate)
+	}
+
+	if _, err = s.EstimateCost("foobar"); !errors.Is(err, ErrNoVerifier) {
+		t.Errorf("Swapper.EstimateCost() error = %v, want %v", err, ErrNoVerifier)
+	}
+	if _, err = s.EstimateCost("$argon2id$foo"); err == nil {
+		t.Error("Swapper.EstimateCost() expected parse error")
+	}
+
+	panics := verifier.VerifyFunc(func(encoded, password string) (verifier.Result, error) {
+		panic("malformed input")
+	})
+	if _, err = NewSwapper(testHasher, panics).EstimateCost("foobar"); !errors.Is(err, ErrVerifierPanic) {
+		t.Errorf("Swapper.EstimateCost() error = %v, want %v", err, ErrVerifierPanic)
+	}
+	prefixed := &verifier.NamedFunc{Name: "mock", VerifyFunc: panics, Prefixes: []string{"$mock$"}}
+	if _, err = NewSwapper(testHasher, prefixed, md5crypt.Verifier).EstimateCost(tv.MD5Encoded); err != nil {
+		t.Errorf("Swapper.EstimateCost() error = %v, want other prefixes not called", err)
+	}
+}
+
+func TestSwapper_CostGap(t *testing.T) {
+	cost10, cost12 := bcrypt.New(10), bcrypt.New(12)
+
+	encoded10, err := cost10.Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded12, err := cost12.Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gap, err := NewSwapper(cost12).CostGap(encoded10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gap != 2 {
+		t.Errorf("Swapper.CostGap() = %f, want 2", gap)
+	}
+
+	gap, err = NewSwapper(cost10).CostGap(encoded12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gap != 0 {
+		t.Errorf("Swapper.CostGap() above target = %f, want 0", gap)
+	}
+
+	if _, err = NewSwapper(cost10).CostGap("foobar"); !errors.Is(err, ErrNoVerifier) {
+		t.Errorf("Swapper.CostGap() error = %v, want %v", err, ErrNoVerifier)
+	}
+	if _, err = NewSwapper(cost10).CostGap("$2a$10$foo"); err == nil || errors.Is(err, ErrNoVerifier) {
+		t.Errorf("Swapper.CostGap() error = %v, want parse error", err)
+	}
+	if _, err = NewSwapper(md5crypt.Hasher{}, cost10).CostGap(encoded10); !errors.Is(err, ErrHashParameters) {
+		t.Errorf("Swapper.CostGap() error = %v, want %v", err, ErrHashParameters)
+	}
+
+	gap, err = NewSwapper(noHashHasher{cost12, t}).CostGap(encoded10)
+	if err != nil || gap != 2 {
+		t.Errorf("Swapper.CostGap() = %f, %v, want 2", gap, err)
+	}
+}
+
+// noHashHasher fails the test when Hash is called.
+type noHashHasher struct {
+	*bcrypt.Hasher
+	t *testing.T
+}
+
+func (h noHashHasher) Hash(string) (string, error) {
+	h.t.Error("Hash called")
+	return h.Hasher.Hash(tv.Password)
+}
+
+func TestSwapper_SelfCheck(t *testing.T) {
+	errInvalid := errors.New("invalid")
+	hasher := argon2.NewArgon2id(argon2.Params{Time: 1, Memory: 1024, Threads: 1, KeyLen: 32, SaltLen: 16})
+
+	tests := []struct {
+		name    string
+		s       *Swapper
+		wantErr bool
+		errIs   error
+	}{
+		{
+			name: "ok",
+			s:    NewSwapper(hasher),
+		},
+		{
+			name: "validation ok",
+			s:    NewSwapper(validatingHasher{Hasher: hasher}),
+		},
+		{
+			name:    "validation error",
+			s:       NewSwapper(validatingHasher{Hasher: hasher, err: errInvalid}),
+			wantErr: true,
+			errIs:   errInvalid,
+		},
+		{
+			name: "hash error",
+			s: NewSwapper(hasherFunc(func(string) (string, error) {
+				return "", errInvalid
+			})),
+			wantErr: true,
+			errIs:   errInvalid,
+		},
+		{
+			name: "no round trip",
+			s: NewSwapper(hasherFunc(func(password string) (string, error) {
+				return "$mock$" + password, nil
+			})),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.s.SelfCheck()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Swapper.SelfCheck() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.errIs != nil && !errors.Is(err, tt.errIs) {
+				t.Errorf("Swapper.SelfCheck() error = %v, want %v", err, tt.errIs)
+			}
+		})
+	}
+}
+
+func TestWithAcceptableVerifiers(t *testing.T) {
+	scryptHasher := scrypt.New(scrypt.Params{
+		N:       tv.ScryptN,
+		R:       tv.ScryptR,
+		P:       tv.ScryptP,
+		KeyLen:  tv.KeyLen,
+		SaltLen: tv.SaltLen,
+	})
+	s := NewSwapper(testHasher, scryptHasher, bcrypt.Verifier, md5crypt.Verifier).
+		Apply(WithAcceptableVerifiers(scryptHasher, md5crypt.Verifier, sha1crypt.Verifier))
+
+	tests := []struct {
+		name        string
+		encoded     string
+		newPassword string
+		wantUpdated bool
+	}{
+		{
+			name:    "hasher",
+			encoded: tv.Argon2idEncoded,
+		},
+		{
+			name:        "hasher outdated",
+			encoded:     tv.Argon2iEncoded,
+			wantUpdated: true,
+		},
+		{
+			name:    "acceptable hasher",
+			encoded: tv.ScryptEncoded,
+		},
+		{
+			name:    "acceptable verifier func",
+			encoded: tv.MD5Encoded,
+		},
+		{
+			name:        "other verifier",
+			encoded:     tv.EncodedBcrypt2b,
+			wantUpdated: true,
+		},
+		{
+			name:        "acceptable with new password",
+			encoded:     tv.MD5Encoded,
+			newPassword: "foobar",
+			wantUpdated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				updated string
+				err     error
+			)
+			if tt.newPassword == "" {
+				updated, err = s.Verify(tt.encoded, tv.Password)
+			} else {
+				updated, err = s.VerifyAndUpdate(tt.encoded, tv.Password, tt.newPassword)
+			}
+			if err != nil {
+				t.Fatalf("Swapper.Verify() error = %v", err)
+			}
+			if (updated != "") != tt.wantUpdated {
+				t.Errorf("Swapper.Verify() updated = %q, want updated %t", updated, tt.wantUpdated)
+			}
+		})
+	}
+}
+
+func Test_sameVerifier(t *testing.T) {
+	scryptHasher := scrypt.New(scrypt.RecommendedParams)
+	tests := []struct {
+		name string
+		a, b verifier.Verifier
+		want bool
+	}{
+		{"same func", md5crypt.Verifier, md5crypt.Verifier, true},
+		{"other func", md5crypt.Verifier, bcrypt.Verifier, false},
+		{"same pointer", scryptHasher, scryptHasher, true},
+		{"other pointer", scryptHasher, scrypt.New(scrypt.RecommendedParams), false},
+		{"same value", md5crypt.Hasher{}, md5crypt.Hasher{}, true},
+		{"other type", scryptHasher, scrypt.Verifier, false},
+		{"nil", nil, scrypt.Verifier, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameVerifier(tt.a, tt.b); got != tt.want {
+				t.Errorf("sameVerifier() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithCurrentVerifiers(t *testing.T) {
+	scryptHasher := scrypt.New(scrypt.Params{
+		N:       tv.ScryptN,
+		R:       tv.ScryptR,
+		P:       tv.ScryptP,
+		KeyLen:  tv.KeyLen,
+		SaltLen: tv.SaltLen,
+	})
+	s := NewSwapper(testHasher, scryptHasher, scrypt.Verifier, bcrypt.Verifier).
+		Apply(WithCurrentVerifiers(func(v verifier.Verifier) bool {
+			_, ok := v.(Hasher)
+			return ok
+		}))
+	if want := []bool{true, true, false, false}; !reflect.DeepEqual(s.current, want) {
+		t.Errorf("Swapper.current = %v, want %v", s.current, want)
+	}
+
+	tests := []struct {
+		name        string
+		encoded     string
+		wantUpdated bool
+	}{
+		{
+			name:    "hasher",
+			encoded: tv.Argon2idEncoded,
+		},
+		{
+			name:    "equivalent hasher",
+			encoded: tv.ScryptEncoded,
+		},
+		{
+			name:        "other verifier",
+			encoded:     tv.EncodedBcrypt2b,
+			wantUpdated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, err := s.Verify(tt.encoded, tv.Password)
+			if err != nil {
+				t.Fatalf("Swapper.Verify() error = %v", err)
+			}
+			if (updated != "") != tt.wantUpdated {
+				t.Errorf("Swapper.Verify() updated = %q, want updated %t", updated, tt.wantUpdated)
+			}
+		})
+	}
+}
+
+func TestWithLastResortVerifiers(t *testing.T) {
+	// keyedHex recognizes 32 hex characters, like md5plain,
+	// as md5 digests of a keyed password.
+	keyedHex := &verifier.NamedFunc{Name: "keyedhex", VerifyFunc: func(encoded, password string) (verifier.Result, error) {
+		if _, err := hex.DecodeString(encoded); err != nil || len(encoded) != 32 {
+			return verifier.Skip, nil
+		}
+		sum := md5.Sum([]byte("key" + password))
+		if hex.EncodeToString(sum[:]) != encoded {
+			return verifier.Fail, nil
+		}
+		return verifier.OK, nil
+	}}
+	sum := md5.Sum([]byte("key" + tv.Password))
+	encodedKeyed := hex.EncodeToString(sum[:])
+
+	shadowed := NewSwapper(testHasher, md5plain.Verifier, keyedHex).Apply(WithAcceptableVerifiers(keyedHex))
+	if _, err := shadowed.Verify(encodedKeyed, tv.Password); !errors.Is(err, ErrPasswordMismatch) {
+		t.Errorf("Swapper.Verify() error = %v, want %v", err, ErrPasswordMismatch)
+	}
+
+	s := NewSwapper(testHasher, md5plain.Verifier, keyedHex, bcrypt.Verifier).
+		Apply(
+			WithAcceptableVerifiers(keyedHex),
+			WithLastResortVerifiers(md5plain.Algorithm),
+		)
+	wantOrder := []verifier.Verifier{testHasher, keyedHex, bcrypt.Verifier, md5plain.Verifier}
+	if !reflect.DeepEqual(s.verifiers, wantOrder) {
+		t.Errorf("Swapper.verifiers = %v, want %v", s.verifiers, wantOrder)
+	}
+	if want := []bool{false, true, false, false}; !reflect.DeepEqual(s.current, want) {
+		t.Errorf("Swapper.current = %v, want %v", s.current, want)
+	}
+
+	tests := []struct {
+		name        string
+		encoded     string
+		wantUpdated bool
+	}{
+		{"keyed hex", encodedKeyed, false},
+		{"md5plain prefixed", md5plain.Prefix + tv.MD5PlainHex, true},
+		{"bcrypt", tv.EncodedBcrypt2b, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, err := s.Verify(tt.encoded, tv.Password)
+			if err != nil {
+				t.Fatalf("Swapper.Verify() error = %v", err)
+			}
+			if (updated != "") != tt.wantUpdated {
+				t.Errorf("Swapper.Verify() updated = %q, want updated %t", updated, tt.wantUpdated)
+			}
+		})
+	}
+}
+
+func TestWithLegacyVerifiers(t *testing.T) {
+	s := NewSwapper(testHasher, md5crypt.Verifier, bcrypt.Verifier).
+		Apply(
+			WithAcceptableVerifiers(md5crypt.Verifier),
+			WithLegacyVerifiers(md5crypt.Algorithm),
+		)
+	if want := []bool{false, true, false}; !reflect.DeepEqual(s.legacy, want) {
+		t.Errorf("Swapper.legacy = %v, want %v", s.legacy, want)
+	}
+
+	tests := []struct {
+		name          string
+		encoded       string
+		password      string
+		wantUpdated   bool
+		wantAlgorithm string
+		wantWarning   error
+		wantErr       error
+	}{
+		{
+			name:          "hasher",
+			encoded:       tv.Argon2idEncoded,
+			password:      tv.Password,
+			wantAlgorithm: argon2.Algorithm,
+		},
+		{
+			name:          "legacy",
+			encoded:       tv.MD5Encoded,
+			password:      tv.Password,
+			wantUpdated:   true,
+			wantAlgorithm: md5crypt.Algorithm,
+			wantWarning:   ErrLegacyAlgorithm,
+		},
+		{
+			name:          "other verifier",
+			encoded:       tv.EncodedBcrypt2b,
+			password:      tv.Password,
+			wantUpdated:   true,
+			wantAlgorithm: bcrypt.Algorithm,
+		},
+		{
+			name:     "legacy mismatch",
+			encoded:  tv.MD5Encoded,
+			password: "spanac",
+			wantErr:  ErrPasswordMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, err := s.Verify(tt.encoded, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Swapper.Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if (updated != "") != tt.wantUpdated {
+				t.Errorf("Swapper.Verify() updated = %q, want updated %t", updated, tt.wantUpdated)
+			}
+
+			updated, info, err := s.VerifyWithInfo(tt.encoded, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Swapper.VerifyWithInfo() error = %v, want %v", err, tt.wantErr)
+			}
+			if (updated != "") != tt.wantUpdated {
+				t.Errorf("Swapper.VerifyWithInfo() updated = %q, want updated %t", updated, tt.wantUpdated)
+			}
+			if info.Algorithm != tt.wantAlgorithm {
+				t.Errorf("Swapper.VerifyWithInfo() Algorithm = %q, want %q", info.Algorithm, tt.wantAlgorithm)
+			}
+			if !errors.Is(info.Warning, tt.wantWarning) {
+				t.Errorf("Swapper.VerifyWithInfo() Warning = %v, want %v", info.Warning, tt.wantWarning)
+			}
+		})
+	}
+
+	s.Apply(WithRejectOutdated())
+	_, info, err := s.VerifyWithInfo(tv.MD5Encoded, tv.Password)
+	var target *RehashRequiredError
+	if !errors.As(err, &target) || !errors.Is(info.Warning, ErrLegacyAlgorithm) {
+		t.Errorf("Swapper.VerifyWithInfo() = %v, %v, want RehashRequiredError and %v", info, err, ErrLegacyAlgorithm)
+	}
+}
+
+func TestSwapper_SupportedAlgorithms(t *testing.T) {
+	s := NewSwapper(testHasher,
+		argon2.Verifier,
+		bcrypt.Verifier,
+		mockV,
+		bcrypt.NewVerifierBase64Tolerant(),
+		scrypt.Verifier,
+	)
+	want := []string{"argon2", "bcrypt", "scrypt"}
+	if got := s.SupportedAlgorithms(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Swapper.SupportedAlgorithms() = %v, want %v", got, want)
+	}
+}
+
+func TestSwapper_Validate(t *testing.T) {
+	s := NewSwapper(
+		argon2.NewArgon2id(testArgon2Params, argon2.WithValidation(argon2.ValidationOpts{MinMemory: tv.Argon2Memory})),
+		bcrypt.Verifier,
+	)
+	tests := []struct {
+		name       string
+		encoded    string
+		wantErr    error
+		wantBounds bool
+	}{
+		{
+			name:    "within bounds",
+			encoded: tv.Argon2idEncoded,
+		},
+		{
+			name:       "out of bounds",
+			encoded:    strings.Replace(tv.Argon2idEncoded, "m=4096", "m=2048", 1),
+			wantBounds: true,
+		},
+		{
+			name:    "no validator",
+			encoded: tv.EncodedBcrypt2b,
+			wantErr: ErrNoVerifier,
+		},
+		{
+			name:    "unknown",
+			encoded: "foobar",
+			wantErr: ErrNoVerifier,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Validate(tt.encoded)
+			var target *verifier.BoundsError
+			if errors.As(err, &target) != tt.wantBounds {
+				t.Errorf("Swapper.Validate() error = %v, want BoundsError %t", err, tt.wantBounds)
+			}
+			if !tt.wantBounds && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Swapper.Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if err := s.Validate("$argon2id$foo"); err == nil || errors.Is(err, ErrNoVerifier) {
+		t.Errorf("Swapper.Validate() error = %v, want parse error", err)
+	}
+}
+
+func TestWithHashSelfValidation(t *testing.T) {
+	// testArgon2Params are below the recommended bounds.
+	mismatched := argon2.NewArgon2id(testArgon2Params, argon2.WithValidation(argon2.RecommendedValidationOpts))
+
+	encoded, err := NewSwapper(mismatched).Hash(tv.Password)
+	if err != nil || encoded == "" {
+		t.Errorf("Swapper.Hash() = %q, %v, want hash without self validation", encoded, err)
+	}
+
+	s := NewSwapper(mismatched, bcrypt.Verifier).Apply(WithHashSelfValidation())
+	var target *verifier.BoundsError
+	if encoded, err = s.Hash(tv.Password); !errors.As(err, &target) || encoded != "" {
+		t.Errorf("Swapper.Hash() = %q, %v, want BoundsError", encoded, err)
+	}
+	if encoded, err = s.Verify(tv.EncodedBcrypt2b, tv.Password); !errors.As(err, &target) || encoded != "" {
+		t.Errorf("Swapper.Verify() = %q, %v, want BoundsError", encoded, err)
+	}
+
+	s = NewSwapper(testHasher).Apply(WithHashSelfValidation())
+	if _, err = s.Hash(tv.Password); err != nil {
+		t.Errorf("Swapper.Hash() error = %v", err)
+	}
+}
+
+func TestParameters_Strength(t *testing.T) {
+	parse := func(p verifier.Parser, encoded string) verifier.Parameters {
+		t.Helper()
+		params, err := p.Parse(encoded)
+		if err != nil || params == nil {
+			t.Fatalf("Parse(%q) = %v, %v", encoded, params, err)
+		}
+		return params
+	}
+	bcryptH := bcrypt.New(bcrypt.DefaultCost)
+	argon2H := argon2.NewArgon2id(testArgon2Params)
+	scryptH := scrypt.New(scrypt.RecommendedParams)
+
+	// Ordered from weakest to strongest.
+	ordered := []struct {
+		name   string
+		params verifier.Parameters
+		want   int
+	}{
+		{"bcrypt 5", parse(bcryptH, tv.EncodedBcryptCost5), 15},
+		{"argon2id m=4096,t=3", parse(argon2H, tv.Argon2idEncoded), 19},
+		{"scrypt ln=16", parse(scryptH, tv.ScryptEncoded), 19},
+		{"bcrypt 10", parse(bcryptH, tv.EncodedBcryptCost10), 20},
+		{"bcrypt 12", parse(bcryptH, tv.EncodedBcrypt2b), 22},
+	}
+	for i, tt := range ordered {
+		if got := tt.params.Strength(); got != tt.want {
+			t.Errorf("%s: Strength() = %d, want %d", tt.name, got, tt.want)
+		}
+		if i > 0 && tt.params.Strength() < ordered[i-1].params.Strength() {
+			t.Errorf("%s: Strength() below %s", tt.name, ordered[i-1].name)
+		}
+	}
+
+	// OWASP minimum recommendations are about equal.
+	owasp := map[string]int{
+		"argon2id": argon2.Params{Memory: 19 * 1024, Time: 2, Threads: 1}.Strength(),
+		"scrypt":   scrypt.Params{N: 1 << 17, R: 8, P: 1}.Strength(),
+		"bcrypt":   parse(bcryptH, tv.EncodedBcryptCost10).Strength(),
+	}
+	for name, got := range owasp {
+		if got != 20 {
+			t.Errorf("%s OWASP minimum: Strength() = %d, want 20", name, got)
+		}
+	}
+
+	if params, err := bcryptH.Parse(tv.Argon2idEncoded); params != nil || err != nil {
+		t.Errorf("Parse() = %v, %v, want nil", params, err)
+	}
+}
+
+func TestWithValidateOnVerify(t *testing.T) {
+	weak := argon2.NewArgon2id(testArgon2Params, argon2.WithValidation(argon2.ValidationOpts{
+		MinMemory: 2 * tv.Argon2Memory,
+	}))
+	bounded := argon2.NewArgon2id(testArgon2Params, argon2.WithValidation(argon2.ValidationOpts{
+		MinMemory: tv.Argon2Memory,
+	}))
+
+	updated, err := NewSwapper(weak).Verify(tv.Argon2idEncoded, tv.Password)
+	if err != nil || updated != "" {
+		t.Errorf("Swapper.Verify() = %q, %v, want no update without option", updated, err)
+	}
+
+	tests := []struct {
+		name       string
+		s          *Swapper
+		encoded    string
+		password   string
+		wantUpdate bool
+		wantErr    error
+	}{
+		{
+			name:       "below min memory",
+			s:          NewSwapper(weak).Apply(WithValidateOnVerify()),
+			encoded:    tv.Argon2idEncoded,
+			password:   tv.Password,
+			wantUpdate: true,
+		},
+		{
+			name:     "within bounds",
+			s:        NewSwapper(bounded).Apply(WithValidateOnVerify()),
+			encoded:  tv.Argon2idEncoded,
+			password: tv.Password,
+		},
+		{
+			name:     "wrong password",
+			s:        NewSwapper(weak).Apply(WithValidateOnVerify()),
+			encoded:  tv.Argon2idEncoded,
+			password: "foobar",
+			wantErr:  ErrPasswordMismatch,
+		},
+		{
+			name:     "verifier without validation",
+			s:        NewSwapper(weak, bcrypt.Verifier).Apply(WithValidateOnVerify(), WithCurrentVerifiers(func(verifier.Verifier) bool { return true })),
+			encoded:  tv.EncodedBcryptCost5,
+			password: tv.Password,
+		},
+		{
+			name:     "reject outdated",
+			s:        NewSwapper(weak).Apply(WithValidateOnVerify(), WithRejectOutdated()),
+			encoded:  tv.Argon2idEncoded,
+			password: tv.Password,
+			wantErr:  ErrRehashRequired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, err := tt.s.Verify(tt.encoded, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Swapper.Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if (updated != "") != tt.wantUpdate {
+				t.Errorf("Swapper.Verify() = %q, want update %t", updated, tt.wantUpdate)
+			}
+		})
+	}
+}
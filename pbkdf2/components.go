@@ -0,0 +1,80 @@
+package pbkdf2
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zitadel/passwap/verifier"
+)
+
+// HashComponents operates like [Hasher.Hash], but returns the
+// parameters, salt and hash instead of an encoded string.
+// This allows databases to store them in separate fields.
+// The returned Params retain the identifier, so that
+// [Params.Encode] produces the same string as Hash,
+// using the passlib base64 encoding.
+// A creation time, see [WithTimestamp], is not returned.
+func (h *Hasher) HashComponents(password string) (p Params, salt, hash []byte, err error) {
+	salt, hash, err = h.derive(password)
+	if err != nil {
+		return Params{}, nil, nil, err
+	}
+	return h.p, salt, hash, nil
+}
+
+// VerifyComponents operates like [VerifyComponents], and returns
+// NeedUpdate when p differ from the parameters of the Hasher.
+// Like [Hasher.Verify], keys below the MinKeyLen
+// set by [WithValidation] are refused.
+func (h *Hasher) VerifyComponents(p Params, salt, hash []byte, password string) (verifier.Result, error) {
+	c, err := components(p, salt, hash)
+	if err != nil {
+		return verifier.Fail, err
+	}
+	if err = h.vopts.checkKeyLen(c.Params); err != nil {
+		return verifier.Fail, err
+	}
+	if c.verify(password) == verifier.Fail {
+		return verifier.Fail, nil
+	}
+	if h.p != c.Params {
+		return verifier.NeedUpdate, nil
+	}
+	return verifier.OK, nil
+}
+
+// VerifyComponents verifies password against hash and salt,
+// using the parameters p, as returned by [Hasher.HashComponents]
+// or [ParseParams]. The identifier of p defaults to SHA-1.
+// KeyLen and SaltLen are ignored, as they are implied by
+// the length of hash and salt.
+// Either the result of Fail or OK is returned, or Fail
+// and an error when p can't be used for verification.
+func VerifyComponents(p Params, salt, hash []byte, password string) (verifier.Result, error) {
+	c, err := components(p, salt, hash)
+	if err != nil {
+		return verifier.Fail, err
+	}
+	return c.verify(password), nil
+}
+
+// components returns a checker for the parameters, salt and hash.
+func components(p Params, salt, hash []byte) (*checker, error) {
+	c := checker{
+		Params: p,
+		salt:   salt,
+		hash:   hash,
+	}
+	c.id = p.identifier()
+
+	if c.hf = hashFuncForIdentifier(c.id); c.hf == nil {
+		return nil, fmt.Errorf("pbkdf2: unknown identifier %s", c.id)
+	}
+	if len(hash) == 0 {
+		return nil, errors.New("pbkdf2: empty hash")
+	}
+
+	c.KeyLen = uint32(len(hash))
+	c.SaltLen = uint32(len(salt))
+	return &c, nil
+}
@@ -0,0 +1,77 @@
+package pbkdf2
+
+import (
+	"testing"
+
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/verifier"
+)
+
+func TestHasher_HashComponents(t *testing.T) {
+	tests := []*Hasher{
+		NewSHA1(testParamsSha1),
+		NewSHA256(testParamsSha256),
+		NewSHA512(testParamsSha512),
+	}
+	for _, h := range tests {
+		t.Run(h.p.id, func(t *testing.T) {
+			p, salt, hash, err := h.HashComponents(tv.Password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p != h.p {
+				t.Errorf("Hasher.HashComponents() params = %v, want %v", p, h.p)
+			}
+
+			encoded := p.Encode(salt, hash)
+			if res, err := h.Verify(encoded, tv.Password); res != verifier.OK || err != nil {
+				t.Errorf("Hasher.Verify() = %s, %v, want %s", res, err, verifier.OK)
+			}
+			if res, err := h.VerifyComponents(p, salt, hash, tv.Password); res != verifier.OK || err != nil {
+				t.Errorf("Hasher.VerifyComponents() = %s, %v, want %s", res, err, verifier.OK)
+			}
+			if res, err := VerifyComponents(p, salt, hash, "spanac"); res != verifier.Fail || err != nil {
+				t.Errorf("VerifyComponents() = %s, %v, want %s", res, err, verifier.Fail)
+			}
+		})
+	}
+}
+
+func TestVerifyComponents(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"sha1", tv.Pbkdf2Sha1Encoded},
+		{"sha256", tv.Pbkdf2Sha256Encoded},
+		{"sha512", tv.Pbkdf2Sha512Encoded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, salt, hash, err := ParseParams(tt.encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res, err := VerifyComponents(p, salt, hash, tv.Password); res != verifier.OK || err != nil {
+				t.Errorf("VerifyComponents() = %s, %v, want %s", res, err, verifier.OK)
+			}
+		})
+	}
+
+	p := Params{Rounds: tv.Pbkdf2Rounds}
+	if res, err := VerifyComponents(p, []byte(tv.Salt), tv.Pbkdf2Sha1Hash, tv.Password); res != verifier.OK || err != nil {
+		t.Errorf("VerifyComponents() default identifier = %s, %v, want %s", res, err, verifier.OK)
+	}
+	p.id = "pbkdf2-md5"
+	if res, err := VerifyComponents(p, []byte(tv.Salt), tv.Pbkdf2Sha1Hash, tv.Password); res != verifier.Fail || err == nil {
+		t.Errorf("VerifyComponents() unknown identifier = %s, %v, want %s and error", res, err, verifier.Fail)
+	}
+	if _, err := VerifyComponents(testParamsSha1, []byte(tv.Salt), nil, tv.Password); err == nil {
+		t.Error("VerifyComponents() with empty hash, error = nil")
+	}
+
+	h := NewSHA256(testParamsSha256)
+	if res, err := h.VerifyComponents(testParamsSha1, []byte(tv.Salt), tv.Pbkdf2Sha1Hash, tv.Password); res != verifier.NeedUpdate || err != nil {
+		t.Errorf("Hasher.VerifyComponents() = %s, %v, want %s", res, err, verifier.NeedUpdate)
+	}
+}
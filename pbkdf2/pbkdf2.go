@@ -11,10 +11,14 @@ import (
 	"crypto/sha256"
 	"crypto/sha512"
 	"crypto/subtle"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"hash"
 	"io"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/zitadel/passwap/internal/encoding"
 	"github.com/zitadel/passwap/internal/salt"
@@ -34,6 +38,10 @@ const (
 	Prefix = "$" + IdentifierSHA1
 )
 
+// ErrRoundsOverflow is returned when the rounds of an encoded
+// hash don't fit the uint32 of [Params.Rounds].
+var ErrRoundsOverflow = errors.New("pbkdf2: rounds exceed the maximum of 4294967295")
+
 func hashFuncForIdentifier(id string) func() hash.Hash {
 	switch id {
 	case IdentifierSHA1:
@@ -93,35 +101,136 @@ var (
 // See https://passlib.readthedocs.io/en/stable/lib/passlib.hash.pbkdf2_digest.html#format-algorithm
 const Format = "$%s$%d$%s$%s"
 
-var scanFormat = strings.ReplaceAll(Format, "$", " ")
+// formatTimestamp extends Format with the ts parameter,
+// appended to the rounds.
+const formatTimestamp = "$%s$%d,ts=%d$%s$%s"
+
+// Strength implements verifier.Parameters.
+// The work is two hash compressions per HMAC,
+// for each round and each block of the key.
+func (p Params) Strength() int {
+	size := sha1.Size
+	if hf := hashFuncForIdentifier(p.identifier()); hf != nil {
+		size = hf().Size()
+	}
+	blocks := (int(p.KeyLen) + size - 1) / size
+	return verifier.WorkStrength(2 * float64(p.Rounds) * float64(blocks))
+}
+
+// Encode salt and hash with the parameters, using the Modular Crypt Format
+// and the alternative base64 encoding as defined by passlib.
+// The identifier is taken from Params obtained through [ParseParams]
+// or a Hasher constructor. Otherwise the SHA-1 identifier is used.
+// KeyLen and SaltLen are ignored, as they are implied by
+// the length of hash and salt.
+func (p Params) Encode(salt, hash []byte) string {
+	return p.encode(salt, hash, encoding.Pbkdf2B64, time.Time{})
+}
+
+// identifier of the HMAC digest, defaults to SHA-1.
+func (p Params) identifier() string {
+	if p.id == "" {
+		return IdentifierSHA1
+	}
+	return p.id
+}
+
+// encode salt and hash with the parameters, using enc.
+// A non-zero created time is appended to the rounds as ts parameter.
+func (p Params) encode(salt, hash []byte, enc *base64.Encoding, created time.Time) string {
+	id := p.identifier()
+	encSalt := enc.EncodeToString(salt)
+	encHash := enc.EncodeToString(hash)
+
+	if created.IsZero() {
+		return fmt.Sprintf(Format, id, p.Rounds, encSalt, encHash)
+	}
+	return fmt.Sprintf(formatTimestamp, id, p.Rounds, created.Unix(), encSalt, encHash)
+}
+
+// ParseParams parses an encoded pbkdf2 hash string
+// into its parameters, salt and hash.
+// The returned Params retain the identifier for [Params.Encode].
+func ParseParams(encoded string) (p Params, salt, hash []byte, err error) {
+	c, err := parse(encoded)
+	if err != nil {
+		return Params{}, nil, nil, err
+	}
+	if c == nil {
+		return Params{}, nil, nil, fmt.Errorf("pbkdf2 parse: missing %s prefix", Prefix)
+	}
+	return c.Params, c.salt, c.hash, nil
+}
 
 type checker struct {
 	Params
 
-	hash []byte
-	salt []byte
+	hash    []byte
+	salt    []byte
+	created time.Time
 
 	hf func() hash.Hash
 }
 
+// parseRounds parses the rounds field of a pbkdf2 hash.
+// The rounds may be a bare integer, or keyed as `i=` or `rounds=`,
+// as written by some exporters.
+// ErrRoundsOverflow is returned when they don't fit an uint32.
+// The rounds may be followed by a comma and an optional ts parameter,
+// which holds the creation time in unix seconds.
+func (c *checker) parseRounds(field string) error {
+	rounds, params, hasParams := strings.Cut(field, ",")
+	if r, ok := strings.CutPrefix(rounds, "i="); ok {
+		rounds = r
+	} else if r, ok := strings.CutPrefix(rounds, "rounds="); ok {
+		rounds = r
+	}
+
+	r, err := strconv.ParseUint(rounds, 10, 32)
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("%w: %s", ErrRoundsOverflow, rounds)
+	}
+	if err != nil {
+		return fmt.Errorf("pbkdf2 parse rounds: %w", err)
+	}
+	c.Rounds = uint32(r)
+
+	if !hasParams {
+		return nil
+	}
+	ts, ok := strings.CutPrefix(params, "ts=")
+	if !ok {
+		return fmt.Errorf("pbkdf2 parse: unknown parameter %q", params)
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("pbkdf2 parse timestamp: %w", err)
+	}
+	c.created = time.Unix(unix, 0)
+
+	return nil
+}
+
 func parse(encoded string) (*checker, error) {
 	if !strings.HasPrefix(encoded, Prefix) {
 		return nil, nil
 	}
 
-	var (
-		salt string
-		hash string
-		c    checker
-	)
-
-	// scanning needs a space separated string, instead of dollar signs.
-	encoded = strings.ReplaceAll(encoded, "$", " ")
+	// $id$rounds$salt$hash
+	fields := strings.Split(encoded, "$")
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("pbkdf2 parse: expected 5 fields, got %d", len(fields))
+	}
 
-	_, err := fmt.Sscanf(encoded, scanFormat, &c.id, &c.Rounds, &salt, &hash)
+	c := checker{
+		Params: Params{id: fields[1]},
+	}
+	err := c.parseRounds(fields[2])
 	if err != nil {
-		return nil, fmt.Errorf("pbkdf2 parse: %w", err)
+		return nil, err
 	}
+	salt, hash := fields[3], fields[4]
+
 	if c.hf = hashFuncForIdentifier(c.id); c.hf == nil {
 		return nil, fmt.Errorf("pbkdf2: unknown hash identifier %s", c.id)
 	}
@@ -134,6 +243,9 @@ func parse(encoded string) (*checker, error) {
 	if err != nil {
 		return nil, fmt.Errorf("pbkdf2 parse hash: %w", err)
 	}
+	if len(c.hash) == 0 {
+		return nil, errors.New("pbkdf2 parse: empty hash")
+	}
 
 	c.KeyLen = uint32(len(c.hash))
 	c.SaltLen = uint32(len(c.salt))
@@ -141,6 +253,20 @@ func parse(encoded string) (*checker, error) {
 	return &c, nil
 }
 
+// Describe implements verifier.Parameters.
+func (c *checker) Describe() map[string]any {
+	params := map[string]any{
+		"identifier": c.id,
+		"rounds":     c.Rounds,
+		"key_len":    c.KeyLen,
+		"salt_len":   c.SaltLen,
+	}
+	if !c.created.IsZero() {
+		params["created"] = c.created
+	}
+	return params
+}
+
 func (c *checker) verify(pw string) verifier.Result {
 	hash := pbkdf2.Key([]byte(pw), c.salt, int(c.Rounds), int(c.KeyLen), c.hf)
 	res := subtle.ConstantTimeCompare(hash, c.hash)
@@ -148,38 +274,123 @@ func (c *checker) verify(pw string) verifier.Result {
 	return verifier.Result(res)
 }
 
+// Encoding of salt and hash, as produced by the Hasher.
+// The Verifier accepts all of them.
+type Encoding int
+
+const (
+	// EncodingPasslib is the alternative base64 encoding
+	// as defined by passlib. This is standard encoding with
+	// `+` replaced by `.` without padding.
+	// This is the default.
+	EncodingPasslib Encoding = iota
+
+	// EncodingStd is standard base64 encoding without padding.
+	EncodingStd
+
+	// EncodingStdPadding is standard base64 encoding with padding.
+	EncodingStdPadding
+)
+
+func (e Encoding) base64() *base64.Encoding {
+	switch e {
+	case EncodingStd:
+		return base64.RawStdEncoding
+	case EncodingStdPadding:
+		return base64.StdEncoding
+	default:
+		return encoding.Pbkdf2B64
+	}
+}
+
 type Hasher struct {
-	p    Params
-	rand io.Reader
-	hf   func() hash.Hash
+	p Params
+	// rand is shared by concurrent calls to Hash and must be
+	// safe for concurrent use, such as crypto/rand.Reader.
+	rand  io.Reader
+	hf    func() hash.Hash
+	enc   Encoding
+	now   func() time.Time
+	vopts ValidationOpts
+}
+
+// Option configures optional behavior of a Hasher.
+type Option func(*Hasher)
+
+// WithInsecureRand sets the source of salts to r, instead of
+// crypto/rand.Reader, so that the Hasher produces reproducible
+// hashes for known-answer test vectors, see package vectors.
+// Hashes with predictable salts must never be stored,
+// hence the name. This is the only way to replace the
+// source of salts, Hashers otherwise always use crypto/rand.Reader.
+// r is shared by concurrent calls to Hash.
+func WithInsecureRand(r io.Reader) Option {
+	return func(h *Hasher) {
+		h.rand = r
+	}
+}
+
+// WithEncoding sets the encoding of salt and hash
+// produced by the Hasher, to match the target system.
+func WithEncoding(e Encoding) Option {
+	return func(h *Hasher) {
+		h.enc = e
+	}
+}
+
+// WithTimestamp appends the creation time of each hash
+// to the rounds as a `ts=<unix seconds>` parameter, for example
+// `$pbkdf2-sha256$290000,ts=1700000000$...`.
+// The timestamp is not used for key derivation
+// and the Verifier accepts hashes with and without it.
+// Other pbkdf2 implementations, like passlib,
+// will not accept hashes with the parameter.
+func WithTimestamp() Option {
+	return func(h *Hasher) {
+		h.now = time.Now
+	}
 }
 
 // Hash implements passwap.Hasher.
 // Salt and password hashes are encoded using the alternative
-// base64 encoding as defined by passlib.
+// base64 encoding as defined by passlib, unless another
+// Encoding is set using [WithEncoding].
 // This is standard encoding with `+` replaced by `.`
 // without padding.
 func (h *Hasher) Hash(password string) (string, error) {
-	salt, err := salt.New(h.rand, h.p.SaltLen)
+	salt, hash, err := h.derive(password)
 	if err != nil {
-		return "", fmt.Errorf("pbkdf2: %w", err)
+		return "", err
 	}
 
-	hash := pbkdf2.Key([]byte(password), salt, int(h.p.Rounds), int(h.p.KeyLen), h.hf)
+	var created time.Time
+	if h.now != nil {
+		created = h.now()
+	}
+	return h.p.encode(salt, hash, h.enc.base64(), created), nil
+}
 
-	return fmt.Sprintf(Format,
-		h.p.id, h.p.Rounds,
-		encoding.Pbkdf2B64.EncodeToString(salt),
-		encoding.Pbkdf2B64.EncodeToString(hash),
-	), nil
+// derive a hash of password with a new salt,
+// returning salt and hash.
+func (h *Hasher) derive(password string) ([]byte, []byte, error) {
+	s, err := salt.New(h.rand, h.p.SaltLen)
+	if err != nil {
+		return nil, nil, fmt.Errorf("pbkdf2: %w", err)
+	}
+	return s, pbkdf2.Key([]byte(password), s, int(h.p.Rounds), int(h.p.KeyLen), h.hf), nil
 }
 
-// Verify implements passwap.Verifier
+// Verify implements passwap.Verifier.
+// Fail and a [verifier.BoundsError] are returned for hashes
+// with a key below the MinKeyLen set by [WithValidation].
 func (h *Hasher) Verify(encoded, password string) (verifier.Result, error) {
 	c, err := parse(encoded)
 	if err != nil || c == nil {
 		return verifier.Skip, err
 	}
+	if err = h.vopts.checkKeyLen(c.Params); err != nil {
+		return verifier.Fail, err
+	}
 
 	res := c.verify(password)
 	if res == 0 {
@@ -193,38 +404,67 @@ func (h *Hasher) Verify(encoded, password string) (verifier.Result, error) {
 	return verifier.OK, nil
 }
 
-func newHasher(p Params, id string) *Hasher {
+// Identify implements verifier.Identifier.
+func (h *Hasher) Identify(encoded string) (map[string]any, error) {
+	return Identify(encoded)
+}
+
+// HashParameters implements verifier.Configured.
+func (h *Hasher) HashParameters() verifier.Parameters {
+	return &checker{Params: h.p}
+}
+
+// Parse implements verifier.Parser.
+func (h *Hasher) Parse(encoded string) (verifier.Parameters, error) {
+	return Parse(encoded)
+}
+
+// Algorithm implements verifier.Named.
+func (h *Hasher) Algorithm() string {
+	return Algorithm
+}
+
+// HashPrefixes implements verifier.Prefixed.
+func (h *Hasher) HashPrefixes() []string {
+	return prefixes
+}
+
+func newHasher(p Params, id string, opts []Option) *Hasher {
 	p.id = id
-	return &Hasher{
+	h := &Hasher{
 		p:    p,
 		rand: rand.Reader,
 		hf:   hashFuncForIdentifier(id),
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // NewSHA1 returns a pbkdf2 SHA1 Hasher.
-func NewSHA1(p Params) *Hasher {
-	return newHasher(p, IdentifierSHA1)
+func NewSHA1(p Params, opts ...Option) *Hasher {
+	return newHasher(p, IdentifierSHA1, opts)
 }
 
 // NewSHA224 returns a pbkdf2 SHA224 Hasher.
-func NewSHA224(p Params) *Hasher {
-	return newHasher(p, IdentifierSHA224)
+func NewSHA224(p Params, opts ...Option) *Hasher {
+	return newHasher(p, IdentifierSHA224, opts)
 }
 
 // NewSHA256 returns a pbkdf2 SHA256 Hasher.
-func NewSHA256(p Params) *Hasher {
-	return newHasher(p, IdentifierSHA256)
+func NewSHA256(p Params, opts ...Option) *Hasher {
+	return newHasher(p, IdentifierSHA256, opts)
 }
 
 // NewSHA384 returns a pbkdf2 SHA384 Hasher.
-func NewSHA384(p Params) *Hasher {
-	return newHasher(p, IdentifierSHA384)
+func NewSHA384(p Params, opts ...Option) *Hasher {
+	return newHasher(p, IdentifierSHA384, opts)
 }
 
 // NewSHA512 returns a pbkdf2 SHA512 Hasher.
-func NewSHA512(p Params) *Hasher {
-	return newHasher(p, IdentifierSHA512)
+func NewSHA512(p Params, opts ...Option) *Hasher {
+	return newHasher(p, IdentifierSHA512, opts)
 }
 
 // Verify parses encoded and uses its pbkdf2 parameters
@@ -245,4 +485,59 @@ func Verify(encoded, password string) (verifier.Result, error) {
 	return c.verify(password), nil
 }
 
-var Verifier = verifier.VerifyFunc(Verify)
+// Identify parses encoded and returns its identifier
+// and pbkdf2 parameters.
+func Identify(encoded string) (map[string]any, error) {
+	p, err := Parse(encoded)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	return p.Describe(), nil
+}
+
+// Parse parses encoded and returns its pbkdf2 parameters.
+// Nil Parameters and a nil error are returned when
+// encoded is not a pbkdf2 hash.
+func Parse(encoded string) (verifier.Parameters, error) {
+	c, err := parse(encoded)
+	if err != nil || c == nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Canonicalize parses encoded and encodes it again in the form
+// produced by the Hasher with [EncodingPasslib], keeping the
+// optional ts parameter. Salt and hash may use any [Encoding].
+func Canonicalize(encoded string) (string, error) {
+	c, err := parse(encoded)
+	if err != nil {
+		return "", err
+	}
+	if c == nil {
+		return "", fmt.Errorf("pbkdf2 parse: missing %s prefix", Prefix)
+	}
+	return c.encode(c.salt, c.hash, encoding.Pbkdf2B64, c.created), nil
+}
+
+// Algorithm is the name reported by the Verifier and Hasher,
+// see verifier.Named.
+const Algorithm = "pbkdf2"
+
+// prefixes recognized by the Verifier and Hasher.
+var prefixes = []string{Prefix}
+
+var Verifier = &verifier.NamedFunc{Name: Algorithm, VerifyFunc: Verify, Prefixes: prefixes}
+
+// Prefixes returns the prefixes of the hashes handled by this package,
+// as recognized by the Verifier, see verifier.Prefixed.
+func Prefixes() []string {
+	return append([]string(nil), prefixes...)
+}
+
+// SelfTestVector returns a fixed password and its encoded hash,
+// which [Verify] must accept. It guards against regressions
+// of the encoding format, see passwap.SelfTest.
+func SelfTestVector() (password, encoded string) {
+	return "password", `$pbkdf2-sha256$12$cmFuZG9tc2FsdGlzaGFyZA$OFvEcLOIPFd/oq8egf10i.qJLI7A8nDjPLnolCWarQY`
+}
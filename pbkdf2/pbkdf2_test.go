@@ -5,10 +5,13 @@ import (
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
+	"errors"
 	"hash"
+	"io"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/zitadel/passwap/internal/salt"
 	tv "github.com/zitadel/passwap/internal/testvalues"
@@ -155,6 +158,17 @@ func Test_parse(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:    "success URL encoding",
+			encoded: tv.Pbkdf2Sha256URLEncoded,
+			want: &checker{
+				Params: testParamsSha256,
+				hash:   tv.Pbkdf2Sha256Hash,
+				salt:   []byte(tv.Salt),
+				hf:     sha256.New,
+			},
+			wantErr: false,
+		},
 		/*
 			SHA-224 and SHA-384 are not implemented in passlib,
 			therefore there are no encoded strings to compare with.
@@ -178,6 +192,71 @@ func Test_parse(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name:    "rounds error",
+			encoded: strings.Replace(tv.Pbkdf2Sha256Encoded, "$12$", "$foo$", 1),
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "rounds overflow",
+			encoded: strings.Replace(tv.Pbkdf2Sha512Encoded, "$12$", "$5000000000$", 1),
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "unknown parameter",
+			encoded: strings.Replace(tv.Pbkdf2Sha256Encoded, "$12$", "$12,x=1$", 1),
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "keyed rounds i",
+			encoded: strings.Replace(tv.Pbkdf2Sha256Encoded, "$12$", "$i=12$", 1),
+			want: &checker{
+				Params: testParamsSha256,
+				hash:   tv.Pbkdf2Sha256Hash,
+				salt:   []byte(tv.Salt),
+				hf:     sha256.New,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "keyed rounds with timestamp",
+			encoded: strings.Replace(tv.Pbkdf2Sha256Encoded, "$12$", "$rounds=12,ts=1700000000$", 1),
+			want: &checker{
+				Params:  testParamsSha256,
+				hash:    tv.Pbkdf2Sha256Hash,
+				salt:    []byte(tv.Salt),
+				created: time.Unix(1700000000, 0),
+				hf:      sha256.New,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "unknown rounds key",
+			encoded: strings.Replace(tv.Pbkdf2Sha256Encoded, "$12$", "$r=12$", 1),
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "timestamp error",
+			encoded: strings.Replace(tv.Pbkdf2Sha256Encoded, "$12$", "$12,ts=foo$", 1),
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "timestamp",
+			encoded: strings.Replace(tv.Pbkdf2Sha256Encoded, "$12$", "$12,ts=1700000000$", 1),
+			want: &checker{
+				Params:  testParamsSha256,
+				hash:    tv.Pbkdf2Sha256Hash,
+				salt:    []byte(tv.Salt),
+				created: time.Unix(1700000000, 0),
+				hf:      sha256.New,
+			},
+			wantErr: false,
+		},
 		{
 			name:    "salt decode error",
 			encoded: `$pbkdf2$12$~~$mwUqsMixIYMc/0eN4v1.l3SVDpk`,
@@ -208,6 +287,9 @@ func Test_parse(t *testing.T) {
 				if !bytes.Equal(got.salt, tt.want.salt) {
 					t.Errorf("parse() salt =\n%v\nwant\n%v", got.salt, tt.want.salt)
 				}
+				if !got.created.Equal(tt.want.created) {
+					t.Errorf("parse() created =\n%v\nwant\n%v", got.created, tt.want.created)
+				}
 				if !reflect.DeepEqual(got.hf(), tt.want.hf()) {
 					t.Errorf("parse() hf =\n%v\nwant\n%v", got.hf(), tt.want.hf())
 				}
@@ -281,6 +363,25 @@ func TestHasher_Hash(t *testing.T) {
 	}
 }
 
+func TestHasher_Hash_entropy(t *testing.T) {
+	readers := map[string]func() io.Reader{
+		"error":      func() io.Reader { return salt.ErrReader{} },
+		"short read": func() io.Reader { return strings.NewReader("short") },
+	}
+	for name, r := range readers {
+		t.Run(name, func(t *testing.T) {
+			h := Hasher{p: testParamsSha1, rand: r(), hf: sha1.New}
+			got, err := h.Hash(tv.Password)
+			if !errors.Is(err, salt.ErrEntropy) {
+				t.Errorf("Hasher.Hash() error = %v, want %v", err, salt.ErrEntropy)
+			}
+			if got != "" {
+				t.Errorf("Hasher.Hash() = %s, want empty", got)
+			}
+		})
+	}
+}
+
 func TestHasher_Verify(t *testing.T) {
 	type args struct {
 		encoded  string
@@ -494,6 +595,42 @@ func TestVerify(t *testing.T) {
 			want:    verifier.Skip,
 			wantErr: true,
 		},
+		{
+			name: "empty hash",
+			args: args{
+				`$pbkdf2-sha256$1000$c2FsdHNhbHQ$`,
+				"wrong",
+			},
+			want:    verifier.Skip,
+			wantErr: true,
+		},
+		{
+			name: "empty hash, keyed rounds i",
+			args: args{
+				`$pbkdf2-sha256$i=1000$c2FsdHNhbHQ$`,
+				"wrong",
+			},
+			want:    verifier.Skip,
+			wantErr: true,
+		},
+		{
+			name: "empty hash, keyed rounds",
+			args: args{
+				`$pbkdf2-sha256$rounds=1000$c2FsdHNhbHQ$`,
+				"wrong",
+			},
+			want:    verifier.Skip,
+			wantErr: true,
+		},
+		{
+			name: "empty hash, timestamp",
+			args: args{
+				`$pbkdf2-sha256$1000,ts=1700000000$c2FsdHNhbHQ$`,
+				"wrong",
+			},
+			want:    verifier.Skip,
+			wantErr: true,
+		},
 		{
 			name: "sha1, wrong password",
 			args: args{
@@ -518,6 +655,22 @@ func TestVerify(t *testing.T) {
 			},
 			want: verifier.OK,
 		},
+		{
+			name: "sha256, keyed rounds, ok",
+			args: args{
+				strings.Replace(tv.Pbkdf2Sha256Encoded, "$12$", "$i=12$", 1),
+				tv.Password,
+			},
+			want: verifier.OK,
+		},
+		{
+			name: "sha256, URL encoding, ok",
+			args: args{
+				tv.Pbkdf2Sha256URLEncoded,
+				tv.Password,
+			},
+			want: verifier.OK,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -532,3 +685,286 @@ func TestVerify(t *testing.T) {
 		})
 	}
 }
+
+func TestVerify_roundsOverflow(t *testing.T) {
+	encoded := strings.Replace(tv.Pbkdf2Sha512Encoded, "$12$", "$rounds=5000000000$", 1)
+	got, err := Verify(encoded, tv.Password)
+	if !errors.Is(err, ErrRoundsOverflow) {
+		t.Errorf("Verify() error = %v, want %v", err, ErrRoundsOverflow)
+	}
+	if got != verifier.Skip {
+		t.Errorf("Verify() = %v, want %v", got, verifier.Skip)
+	}
+
+	encoded = strings.Replace(tv.Pbkdf2Sha512Encoded, "$12$", "$4294967296$", 1)
+	if _, err = Parse(encoded); !errors.Is(err, ErrRoundsOverflow) {
+		t.Errorf("Parse() error = %v, want %v", err, ErrRoundsOverflow)
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		want    map[string]any
+		wantErr bool
+	}{
+		{
+			name:    "skip",
+			encoded: tv.ScryptEncoded,
+		},
+		{
+			name:    "parse error",
+			encoded: Prefix + "!!!",
+			wantErr: true,
+		},
+		{
+			name:    "success",
+			encoded: tv.Pbkdf2Sha256Encoded,
+			want: map[string]any{
+				"identifier": IdentifierSHA256,
+				"rounds":     uint32(tv.Pbkdf2Rounds),
+				"key_len":    uint32(tv.Pbkdf2Sha256KeyLen),
+				"salt_len":   tv.SaltLen,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSHA256(testParamsSha256).Identify(tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Identify() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Identify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		wantErr bool
+	}{
+		{
+			name:    "missing prefix",
+			encoded: "foobar",
+			wantErr: true,
+		},
+		{
+			name:    "parse error",
+			encoded: Prefix + "!!!",
+			wantErr: true,
+		},
+		{
+			name:    "sha1",
+			encoded: tv.Pbkdf2Sha1Encoded,
+		},
+		{
+			name:    "sha256",
+			encoded: tv.Pbkdf2Sha256Encoded,
+		},
+		{
+			name:    "sha512",
+			encoded: tv.Pbkdf2Sha512Encoded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, salt, hash, err := ParseParams(tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseParams() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := p.Encode(salt, hash); got != tt.encoded {
+				t.Errorf("Params.Encode() =\n%s\nwant\n%s", got, tt.encoded)
+			}
+		})
+	}
+}
+
+func TestParams_Encode(t *testing.T) {
+	p := testParamsSha1
+	p.id = ""
+	if got := p.Encode([]byte(tv.Salt), tv.Pbkdf2Sha1Hash); got != tv.Pbkdf2Sha1Encoded {
+		t.Errorf("Params.Encode() =\n%s\nwant\n%s", got, tv.Pbkdf2Sha1Encoded)
+	}
+}
+
+func TestWithEncoding(t *testing.T) {
+	tests := []struct {
+		enc  Encoding
+		want string
+	}{
+		{
+			enc:  EncodingPasslib,
+			want: tv.Pbkdf2Sha256Encoded,
+		},
+		{
+			enc:  EncodingStd,
+			want: tv.Pbkdf2Sha256StdEncoded,
+		},
+		{
+			enc:  EncodingStdPadding,
+			want: tv.Pbkdf2Sha256StdEncodedPadding,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			h := NewSHA256(testParamsSha256, WithEncoding(tt.enc))
+			h.rand = tv.SaltReader()
+
+			got, err := h.Hash(tv.Password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Hasher.Hash() =\n%s\nwant\n%s", got, tt.want)
+			}
+			res, err := h.Verify(got, tv.Password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res != verifier.OK {
+				t.Errorf("Hasher.Verify() = %s, want %s", res, verifier.OK)
+			}
+		})
+	}
+}
+
+func TestWithTimestamp(t *testing.T) {
+	created := time.Unix(1700000000, 0)
+	withTS := NewSHA256(testParamsSha256, WithTimestamp())
+	withTS.rand = tv.SaltReader()
+	withTS.now = func() time.Time { return created }
+	withoutTS := NewSHA256(testParamsSha256)
+
+	encodedTS, err := withTS.Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(tv.Pbkdf2Sha256Encoded, "$12$", "$12,ts=1700000000$", 1)
+	if encodedTS != want {
+		t.Errorf("Hasher.Hash() =\n%s\nwant\n%s", encodedTS, want)
+	}
+
+	for _, h := range []*Hasher{withTS, withoutTS} {
+		for _, e := range []string{encodedTS, tv.Pbkdf2Sha256Encoded} {
+			res, err := h.Verify(e, tv.Password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res != verifier.OK {
+				t.Errorf("Hasher.Verify(%s) = %s, want %s", e, res, verifier.OK)
+			}
+		}
+	}
+
+	params, err := Identify(encodedTS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := params["created"]; got != created {
+		t.Errorf("Identify() created = %v, want %v", got, created)
+	}
+}
+
+func TestParams_Strength(t *testing.T) {
+	tests := []struct {
+		name string
+		h    *Hasher
+		want int
+	}{
+		// OWASP minimum recommendations.
+		{"sha1", NewSHA1(Params{Rounds: 1300000, KeyLen: 20}), 21},
+		{"sha256", NewSHA256(Params{Rounds: 600000, KeyLen: 32}), 20},
+		{"sha512", NewSHA512(Params{Rounds: 210000, KeyLen: 64}), 19},
+		{"sha256 two blocks", NewSHA256(Params{Rounds: 600000, KeyLen: 64}), 21},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.h.p.Strength(); got != tt.want {
+				t.Errorf("Params.Strength() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	params, err := Parse(tv.Pbkdf2Sha256Encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := params.Describe()["identifier"]; got != IdentifierSHA256 {
+		t.Errorf("Describe() identifier = %v, want %s", got, IdentifierSHA256)
+	}
+	if got := params.Strength(); got != 5 {
+		t.Errorf("Strength() = %d, want 5", got)
+	}
+	if params, err = Parse(tv.Argon2idEncoded); params != nil || err != nil {
+		t.Errorf("Parse() = %v, %v, want nil", params, err)
+	}
+}
+
+func TestWithInsecureRand(t *testing.T) {
+	newTestHasher := func(opts ...Option) *Hasher { return NewSHA256(testParamsSha256, opts...) }
+
+	if err := salt.AssertCryptoRand(newTestHasher().rand); err != nil {
+		t.Errorf("default Hasher: %v", err)
+	}
+
+	newHasher := func() *Hasher {
+		return newTestHasher(WithInsecureRand(strings.NewReader(strings.Repeat(tv.Salt, 4))))
+	}
+	h := newHasher()
+	if err := salt.AssertCryptoRand(h.rand); !errors.Is(err, salt.ErrInsecureRand) {
+		t.Errorf("AssertCryptoRand() = %v, want %v", err, salt.ErrInsecureRand)
+	}
+	first, err := h.Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := newHasher().Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Errorf("Hasher.Hash() = %s, then %s, want identical", first, second)
+	}
+}
+
+func TestHasher_HashParameters(t *testing.T) {
+	hashers := map[string]*Hasher{
+		"sha1":   NewSHA1(testParamsSha1),
+		"sha256": NewSHA256(testParamsSha256, WithTimestamp()),
+		"sha512": NewSHA512(testParamsSha512),
+	}
+	for name, h := range hashers {
+		t.Run(name, func(t *testing.T) {
+			encoded, err := h.Hash(tv.Password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := Parse(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantParams := want.Describe()
+			delete(wantParams, "created")
+
+			got := h.HashParameters()
+			if !reflect.DeepEqual(got.Describe(), wantParams) {
+				t.Errorf("Hasher.HashParameters() = %v, want %v", got.Describe(), wantParams)
+			}
+			if got.Strength() != want.Strength() {
+				t.Errorf("Hasher.HashParameters() strength = %d, want %d", got.Strength(), want.Strength())
+			}
+		})
+	}
+}
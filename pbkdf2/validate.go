@@ -0,0 +1,85 @@
+package pbkdf2
+
+import (
+	"fmt"
+
+	"github.com/zitadel/passwap/verifier"
+)
+
+// ValidationOpts bound the parameters of pbkdf2 hashes,
+// as checked by [Validate] and [Hasher.Validate].
+// Zero values are not checked.
+type ValidationOpts struct {
+	MinRounds uint32
+	MaxRounds uint32
+
+	// MinKeyLen rejects hashes with a truncated key.
+	// A Hasher refuses to verify hashes below it.
+	MinKeyLen  uint32
+	MinSaltLen uint32
+}
+
+// RecommendedValidationOpts reject hashes with truncated
+// keys or short salts. The rounds are not bounded,
+// as their cost depends on the HMAC digest.
+var RecommendedValidationOpts = ValidationOpts{
+	MinKeyLen:  16,
+	MinSaltLen: 8,
+}
+
+// check returns a BoundsError for the first
+// parameter of p which is out of bounds.
+// The error reports the identifier of p as Algorithm,
+// for example "pbkdf2-sha512".
+func (o ValidationOpts) check(p Params) error {
+	bounds := []struct {
+		param    string
+		value    uint32
+		min, max uint32
+	}{
+		{"rounds", p.Rounds, o.MinRounds, o.MaxRounds},
+		{"keylen", p.KeyLen, o.MinKeyLen, 0},
+		{"saltlen", p.SaltLen, o.MinSaltLen, 0},
+	}
+	for _, b := range bounds {
+		if err := verifier.CheckBounds(p.identifier(), b.param, int64(b.value), int64(b.min), int64(b.max)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkKeyLen returns a BoundsError when the
+// key length of p is below MinKeyLen.
+func (o ValidationOpts) checkKeyLen(p Params) error {
+	return verifier.CheckBounds(p.identifier(), "keylen", int64(p.KeyLen), int64(o.MinKeyLen), 0)
+}
+
+// Validate parses encoded and checks its parameters against opts.
+// A [verifier.BoundsError] is returned for the first parameter
+// which is out of bounds.
+func Validate(encoded string, opts ValidationOpts) error {
+	c, err := parse(encoded)
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		return fmt.Errorf("pbkdf2 validate: missing %s prefix", Prefix)
+	}
+	return opts.check(c.Params)
+}
+
+// WithValidation sets the bounds used by [Hasher.Validate].
+// The MinKeyLen bound is also enforced by [Hasher.Verify]
+// and [Hasher.VerifyComponents].
+// By default no bounds are checked.
+func WithValidation(opts ValidationOpts) Option {
+	return func(h *Hasher) {
+		h.vopts = opts
+	}
+}
+
+// Validate implements verifier.Validator.
+func (h *Hasher) Validate(encoded string) error {
+	return Validate(encoded, h.vopts)
+}
@@ -0,0 +1,161 @@
+package pbkdf2
+
+import (
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"testing"
+
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/verifier"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// shortKey returns the params, salt, hash and encoded
+// string of a pbkdf2-sha256 hash with an 8 byte key.
+func shortKey() (p Params, salt, hash []byte, encoded string) {
+	p = testParamsSha256
+	p.KeyLen = 8
+	salt = []byte(tv.Salt)
+	hash = pbkdf2.Key([]byte(tv.Password), salt, int(p.Rounds), int(p.KeyLen), sha256.New)
+	return p, salt, hash, p.Encode(salt, hash)
+}
+
+func TestValidate(t *testing.T) {
+	_, _, _, short := shortKey()
+
+	tests := []struct {
+		name      string
+		encoded   string
+		opts      ValidationOpts
+		wantParam string
+		wantErr   bool
+	}{
+		{
+			name:    "unbounded",
+			encoded: short,
+		},
+		{
+			name:    "within bounds",
+			encoded: tv.Pbkdf2Sha256Encoded,
+			opts:    ValidationOpts{MinRounds: 12, MaxRounds: 12, MinKeyLen: 32, MinSaltLen: 16},
+		},
+		{
+			name:    "recommended",
+			encoded: tv.Pbkdf2Sha256Encoded,
+			opts:    RecommendedValidationOpts,
+		},
+		{
+			name:      "rounds below",
+			encoded:   tv.Pbkdf2Sha256Encoded,
+			opts:      ValidationOpts{MinRounds: 1000},
+			wantParam: "rounds",
+			wantErr:   true,
+		},
+		{
+			name:      "rounds above",
+			encoded:   tv.Pbkdf2Sha256Encoded,
+			opts:      ValidationOpts{MaxRounds: 10},
+			wantParam: "rounds",
+			wantErr:   true,
+		},
+		{
+			name:      "8 byte key",
+			encoded:   short,
+			opts:      RecommendedValidationOpts,
+			wantParam: "keylen",
+			wantErr:   true,
+		},
+		{
+			name:      "salt length below",
+			encoded:   tv.Pbkdf2Sha256Encoded,
+			opts:      ValidationOpts{MinSaltLen: 32},
+			wantParam: "saltlen",
+			wantErr:   true,
+		},
+		{
+			name:    "parse error",
+			encoded: strings.Replace(tv.Pbkdf2Sha256Encoded, "$12$", "$foo$", 1),
+			wantErr: true,
+		},
+		{
+			name:    "not pbkdf2",
+			encoded: tv.ScryptEncoded,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.encoded, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var target *verifier.BoundsError
+			if errors.As(err, &target) != (tt.wantParam != "") {
+				t.Fatalf("Validate() error = %v, want BoundsError %t", err, tt.wantParam != "")
+			}
+			if target != nil && target.Param != tt.wantParam {
+				t.Errorf("Validate() param = %s, want %s", target.Param, tt.wantParam)
+			}
+		})
+	}
+}
+
+func TestValidate_identifier(t *testing.T) {
+	err := Validate(tv.Pbkdf2Sha512Encoded, ValidationOpts{MinRounds: 1000})
+	var target *verifier.BoundsError
+	if !errors.As(err, &target) {
+		t.Fatalf("Validate() error = %v, want BoundsError", err)
+	}
+	if target.Algorithm != IdentifierSHA512 {
+		t.Errorf("BoundsError.Algorithm = %s, want %s", target.Algorithm, IdentifierSHA512)
+	}
+}
+
+func TestHasher_Validate(t *testing.T) {
+	_, _, _, short := shortKey()
+
+	if err := NewSHA256(testParamsSha256).Validate(short); err != nil {
+		t.Errorf("Hasher.Validate() without bounds error = %v", err)
+	}
+	h := NewSHA256(testParamsSha256, WithValidation(RecommendedValidationOpts))
+	var target *verifier.BoundsError
+	if err := h.Validate(short); !errors.As(err, &target) {
+		t.Errorf("Hasher.Validate() error = %v, want BoundsError", err)
+	}
+}
+
+func TestHasher_Verify_minKeyLen(t *testing.T) {
+	short, salt, hash, encoded := shortKey()
+
+	tests := []struct {
+		name      string
+		h         *Hasher
+		want      verifier.Result
+		wantBound bool
+	}{
+		{"unbounded", NewSHA256(testParamsSha256), verifier.NeedUpdate, false},
+		{"below minimum", NewSHA256(testParamsSha256, WithValidation(RecommendedValidationOpts)), verifier.Fail, true},
+		{"at minimum", NewSHA256(testParamsSha256, WithValidation(ValidationOpts{MinKeyLen: 8})), verifier.NeedUpdate, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.h.Verify(encoded, tv.Password)
+			var target *verifier.BoundsError
+			if errors.As(err, &target) != tt.wantBound {
+				t.Errorf("Hasher.Verify() error = %v, want BoundsError %t", err, tt.wantBound)
+			}
+			if tt.wantBound && target.Param != "keylen" {
+				t.Errorf("BoundsError.Param = %s, want keylen", target.Param)
+			}
+			if got != tt.want {
+				t.Errorf("Hasher.Verify() = %s, want %s", got, tt.want)
+			}
+
+			got, _ = tt.h.VerifyComponents(short, salt, hash, tv.Password)
+			if got != tt.want {
+				t.Errorf("Hasher.VerifyComponents() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package passwap
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zitadel/passwap/argon2"
+	"github.com/zitadel/passwap/bcrypt"
+	"github.com/zitadel/passwap/descrypt"
+	"github.com/zitadel/passwap/devise"
+	"github.com/zitadel/passwap/django"
+	"github.com/zitadel/passwap/dovecot"
+	"github.com/zitadel/passwap/iteratedhash"
+	md5crypt "github.com/zitadel/passwap/md5"
+	"github.com/zitadel/passwap/md5plain"
+	"github.com/zitadel/passwap/pbkdf2"
+	"github.com/zitadel/passwap/saltedhex"
+	"github.com/zitadel/passwap/scrypt"
+	"github.com/zitadel/passwap/sha1crypt"
+	"github.com/zitadel/passwap/tomcat"
+	"github.com/zitadel/passwap/verifier"
+	"github.com/zitadel/passwap/wordpress"
+)
+
+func TestPrefixes(t *testing.T) {
+	tests := []struct {
+		pkg      string
+		prefixes func() []string
+		// v is the Verifier of the package, if any.
+		v verifier.Verifier
+		// shares is the package with the same prefixes.
+		shares string
+	}{
+		{pkg: "argon2", prefixes: argon2.Prefixes, v: argon2.Verifier},
+		{pkg: "bcrypt", prefixes: bcrypt.Prefixes, v: bcrypt.Verifier, shares: "devise"},
+		{pkg: "descrypt", prefixes: descrypt.Prefixes, v: descrypt.Verifier},
+		{pkg: "devise", prefixes: devise.Prefixes, v: devise.New("pepper"), shares: "bcrypt"},
+		{pkg: "django", prefixes: django.Prefixes, v: django.Verifier},
+		{pkg: "dovecot", prefixes: dovecot.Prefixes, v: dovecot.Verifier},
+		{pkg: "iteratedhash", prefixes: iteratedhash.Prefixes},
+		{pkg: "md5", prefixes: md5crypt.Prefixes, v: md5crypt.Verifier},
+		{pkg: "md5plain", prefixes: md5plain.Prefixes, v: md5plain.Verifier},
+		{pkg: "pbkdf2", prefixes: pbkdf2.Prefixes, v: pbkdf2.Verifier},
+		{pkg: "saltedhex", prefixes: saltedhex.Prefixes},
+		{pkg: "scrypt", prefixes: scrypt.Prefixes, v: scrypt.Verifier},
+		{pkg: "sha1crypt", prefixes: sha1crypt.Prefixes, v: sha1crypt.Verifier},
+		{pkg: "tomcat", prefixes: tomcat.Prefixes},
+		{pkg: "wordpress", prefixes: wordpress.Prefixes, v: wordpress.Verifier},
+	}
+	unprefixed := map[string]bool{
+		"descrypt":     true,
+		"iteratedhash": true,
+		"md5plain":     true,
+		"saltedhex":    true,
+		"tomcat":       true,
+	}
+
+	owners := make(map[string]string)
+	for _, tt := range tests {
+		t.Run(tt.pkg, func(t *testing.T) {
+			got := tt.prefixes()
+			if (len(got) == 0) != unprefixed[tt.pkg] {
+				t.Fatalf("Prefixes() = %q, want empty %t", got, unprefixed[tt.pkg])
+			}
+			for _, prefix := range got {
+				if prefix == "" {
+					t.Error("Prefixes() contains an empty prefix")
+				}
+				if owner, ok := owners[prefix]; ok && owner != tt.shares {
+					t.Errorf("prefix %q is also returned by %s", prefix, owner)
+				}
+				owners[prefix] = tt.pkg
+			}
+
+			if p, ok := tt.v.(verifier.Prefixed); ok {
+				if hp := p.HashPrefixes(); !reflect.DeepEqual(hp, got) && !(len(hp) == 0 && len(got) == 0) {
+					t.Errorf("Verifier.HashPrefixes() = %q, want %q", hp, got)
+				}
+			}
+
+			if len(got) > 0 {
+				got[0] = "mutated"
+				if tt.prefixes()[0] == "mutated" {
+					t.Error("Prefixes() returned the internal slice")
+				}
+			}
+		})
+	}
+}
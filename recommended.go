@@ -0,0 +1,56 @@
+package passwap
+
+import (
+	"github.com/zitadel/passwap/argon2"
+	"github.com/zitadel/passwap/bcrypt"
+	"github.com/zitadel/passwap/pbkdf2"
+	"github.com/zitadel/passwap/scrypt"
+)
+
+// RecommendedParams returns the recommended parameters of the
+// hashers in passwap as data, for example to generate a starter
+// configuration file. The map is keyed by the name of the algorithm,
+// or the identifier of its variant, such as "argon2id" or "pbkdf2-sha256".
+// Each entry holds the parameters under the names used by [verifier.Parameters].
+// The result is safe to marshal with encoding/json.
+// A new map is returned on every call, so it may be modified.
+func RecommendedParams() map[string]any {
+	params := map[string]any{
+		argon2.Identifier_i:  argon2Params(argon2.RecommendedIParams),
+		argon2.Identifier_id: argon2Params(argon2.RecommendedIDParams),
+		bcrypt.Algorithm: map[string]any{
+			"cost": bcrypt.DefaultCost,
+		},
+		scrypt.Identifier: map[string]any{
+			"n":        scrypt.RecommendedParams.N,
+			"r":        scrypt.RecommendedParams.R,
+			"p":        scrypt.RecommendedParams.P,
+			"key_len":  scrypt.RecommendedParams.KeyLen,
+			"salt_len": scrypt.RecommendedParams.SaltLen,
+		},
+	}
+	for id, p := range map[string]pbkdf2.Params{
+		pbkdf2.IdentifierSHA1:   pbkdf2.RecommendedSHA1Params,
+		pbkdf2.IdentifierSHA224: pbkdf2.RecommendedSHA224Params,
+		pbkdf2.IdentifierSHA256: pbkdf2.RecommendedSHA256Params,
+		pbkdf2.IdentifierSHA384: pbkdf2.RecommendedSHA384Params,
+		pbkdf2.IdentifierSHA512: pbkdf2.RecommendedSHA512Params,
+	} {
+		params[id] = map[string]any{
+			"rounds":   p.Rounds,
+			"key_len":  p.KeyLen,
+			"salt_len": p.SaltLen,
+		}
+	}
+	return params
+}
+
+func argon2Params(p argon2.Params) map[string]any {
+	return map[string]any{
+		"memory":   p.Memory,
+		"time":     p.Time,
+		"threads":  p.Threads,
+		"key_len":  p.KeyLen,
+		"salt_len": p.SaltLen,
+	}
+}
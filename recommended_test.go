@@ -0,0 +1,45 @@
+package passwap
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRecommendedParams(t *testing.T) {
+	got := RecommendedParams()
+	fields := map[string][]string{
+		"argon2id":      {"memory", "time", "threads", "key_len", "salt_len"},
+		"bcrypt":        {"cost"},
+		"scrypt":        {"n", "r", "p", "key_len", "salt_len"},
+		"pbkdf2":        {"rounds", "key_len", "salt_len"},
+		"pbkdf2-sha256": {"rounds", "key_len", "salt_len"},
+	}
+	for name, want := range fields {
+		params, ok := got[name].(map[string]any)
+		if !ok {
+			t.Errorf("RecommendedParams() %s = %v, want params", name, got[name])
+			continue
+		}
+		if len(params) != len(want) {
+			t.Errorf("RecommendedParams() %s = %v, want fields %v", name, params, want)
+		}
+		for _, f := range want {
+			if _, ok := params[f]; !ok {
+				t.Errorf("RecommendedParams() %s is missing %q", name, f)
+			}
+		}
+	}
+
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]any{"cost": float64(10)}; !reflect.DeepEqual(decoded["bcrypt"], want) {
+		t.Errorf("RecommendedParams() bcrypt JSON = %v, want %v", decoded["bcrypt"], want)
+	}
+}
@@ -0,0 +1,83 @@
+package passwap
+
+import (
+	"sort"
+
+	"github.com/zitadel/passwap/verifier"
+)
+
+// prefixIndex routes encoded strings to the verifiers
+// which may recognize them, so that verifiers implementing
+// [verifier.Prefixed] are not called for other formats.
+type prefixIndex struct {
+	// byPrefix maps each prefix to the indices
+	// of the verifiers which recognize it.
+	byPrefix map[string][]int
+	// lengths of the prefixes in byPrefix.
+	lengths []int
+	// fallback are the indices of verifiers
+	// without prefixes, which are always called.
+	fallback []int
+}
+
+func newPrefixIndex(verifiers []verifier.Verifier) prefixIndex {
+	x := prefixIndex{
+		byPrefix: make(map[string][]int),
+	}
+	lengths := make(map[int]bool)
+
+	for i, v := range verifiers {
+		var prefixes []string
+		if p, ok := v.(verifier.Prefixed); ok {
+			prefixes = p.HashPrefixes()
+		}
+		if len(prefixes) == 0 {
+			x.fallback = append(x.fallback, i)
+			continue
+		}
+		for _, prefix := range prefixes {
+			x.byPrefix[prefix] = append(x.byPrefix[prefix], i)
+			if !lengths[len(prefix)] {
+				lengths[len(prefix)] = true
+				x.lengths = append(x.lengths, len(prefix))
+			}
+		}
+	}
+	return x
+}
+
+// candidates returns the indices of the verifiers which
+// may recognize encoded: those with a matching prefix
+// and the fallback verifiers.
+// They are returned in the order the verifiers were registered,
+// so that the Hasher is always tried first.
+func (x prefixIndex) candidates(encoded string) []int {
+	if len(x.lengths) == 0 {
+		return x.fallback
+	}
+	var matched []int
+	for _, n := range x.lengths {
+		if n > len(encoded) {
+			continue
+		}
+		matched = append(matched, x.byPrefix[encoded[:n]]...)
+	}
+	if len(matched) == 0 {
+		return x.fallback
+	}
+
+	matched = append(matched, x.fallback...)
+	sort.Ints(matched)
+	return dedupSorted(matched)
+}
+
+// dedupSorted removes duplicates from sorted ints in place.
+func dedupSorted(ints []int) []int {
+	out := ints[:0]
+	for _, v := range ints {
+		if len(out) == 0 || v != out[len(out)-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
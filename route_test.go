@@ -0,0 +1,177 @@
+package passwap
+
+import (
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zitadel/passwap/argon2"
+	"github.com/zitadel/passwap/bcrypt"
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	md5crypt "github.com/zitadel/passwap/md5"
+	"github.com/zitadel/passwap/pbkdf2"
+	"github.com/zitadel/passwap/scrypt"
+	"github.com/zitadel/passwap/sha1crypt"
+	"github.com/zitadel/passwap/verifier"
+)
+
+// countingPackageVerifiers are wrapped by countingVerifiers.
+var countingPackageVerifiers = []*verifier.NamedFunc{
+	argon2.Verifier,
+	bcrypt.Verifier,
+	scrypt.Verifier,
+	pbkdf2.Verifier,
+	sha1crypt.Verifier,
+	md5crypt.Verifier,
+}
+
+// countingVerifiers wraps countingPackageVerifiers, counting calls
+// to Verify of each Verifier at the same index in calls.
+// When prefixed is false, the wrappers don't implement
+// verifier.Prefixed and are called for every encoded string.
+func countingVerifiers(calls []atomic.Int64, prefixed bool) []verifier.Verifier {
+	verifiers := make([]verifier.Verifier, len(countingPackageVerifiers))
+	for i, v := range countingPackageVerifiers {
+		v, calls := v, &calls[i]
+		counting := &verifier.NamedFunc{
+			Name: v.Name,
+			VerifyFunc: func(encoded, password string) (verifier.Result, error) {
+				calls.Add(1)
+				return v.Verify(encoded, password)
+			},
+		}
+		if prefixed {
+			counting.Prefixes = v.Prefixes
+		}
+		verifiers[i] = counting
+	}
+	return verifiers
+}
+
+func sumCalls(calls []atomic.Int64) (sum int64) {
+	for i := range calls {
+		sum += calls[i].Load()
+	}
+	return sum
+}
+
+func TestSwapper_prefixIndex(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoded  string
+		password string
+		wantErr  error
+		// index of the only Verifier called with a prefix index.
+		wantCalled int
+	}{
+		{"bcrypt", tv.EncodedBcryptCost5, tv.Password, nil, 1},
+		{"scrypt", tv.ScryptEncoded, "foobar", ErrPasswordMismatch, 2},
+		{"pbkdf2", tv.Pbkdf2Sha256Encoded, tv.Password, nil, 3},
+		{"sha1crypt", tv.Sha1CryptEncoded, "foobar", ErrPasswordMismatch, 4},
+		{"md5", tv.MD5Encoded, tv.Password, nil, 5},
+		{"md5 wrong password", tv.MD5Encoded, "foobar", ErrPasswordMismatch, 5},
+		{"no verifier", "foobar", tv.Password, ErrNoVerifier, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, prefixed := range []bool{false, true} {
+				calls := make([]atomic.Int64, len(countingPackageVerifiers))
+				s := NewSwapper(testHasher, countingVerifiers(calls, prefixed)...)
+
+				updated, err := s.Verify(tt.encoded, tt.password)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("prefixed %t: Swapper.Verify() error = %v, want %v", prefixed, err, tt.wantErr)
+				}
+				if err == nil && updated == "" {
+					t.Errorf("prefixed %t: Swapper.Verify() without update", prefixed)
+				}
+				if !prefixed {
+					continue
+				}
+				for i := range calls {
+					want := int64(0)
+					if i == tt.wantCalled {
+						want = 1
+					}
+					if got := calls[i].Load(); got != want {
+						t.Errorf("%s Verify calls = %d, want %d", countingPackageVerifiers[i].Name, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func Test_prefixIndex_candidates(t *testing.T) {
+	x := newPrefixIndex([]verifier.Verifier{
+		testHasher,
+		mockV,
+		bcrypt.Verifier,
+		argon2.Verifier,
+		scrypt.Verifier,
+		&verifier.NamedFunc{VerifyFunc: mockV},
+		md5crypt.Verifier,
+	})
+	tests := []struct {
+		name    string
+		encoded string
+		want    []int
+	}{
+		{"argon2", tv.Argon2idEncoded, []int{0, 1, 3, 5}},
+		{"bcrypt", tv.EncodedBcrypt2b, []int{1, 2, 5}},
+		{"scrypt", tv.ScryptEncoded, []int{1, 4, 5}},
+		{"scrypt sodium", tv.ScryptSodiumEncoded, []int{1, 4, 5}},
+		{"md5", tv.MD5Encoded, []int{1, 5, 6}},
+		{"no prefix", tv.MD5PlainHex, []int{1, 5}},
+		{"short", "$", []int{1, 5}},
+		{"empty", "", []int{1, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := x.candidates(tt.encoded); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("candidates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_prefixIndex_candidates_shared(t *testing.T) {
+	x := newPrefixIndex([]verifier.Verifier{
+		&verifier.NamedFunc{VerifyFunc: mockV, Prefixes: []string{"$2", "$2b$"}},
+		&verifier.NamedFunc{VerifyFunc: mockV, Prefixes: []string{"$2b$"}},
+	})
+	if got, want := x.candidates(tv.EncodedBcrypt2b), []int{0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("candidates() = %v, want %v", got, want)
+	}
+	if got, want := x.candidates(tv.EncodedBcrypt2a), []int{0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("candidates() = %v, want %v", got, want)
+	}
+	if got := x.candidates(tv.MD5PlainHex); len(got) != 0 {
+		t.Errorf("candidates() = %v, want none", got)
+	}
+}
+
+func BenchmarkSwapper_Verify_dispatch(b *testing.B) {
+	for _, bb := range []struct {
+		name     string
+		prefixed bool
+	}{
+		{"linear", false},
+		{"prefix index", true},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			calls := make([]atomic.Int64, len(countingPackageVerifiers))
+			s := NewSwapper(testHasher, countingVerifiers(calls, bb.prefixed)...)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				// md5 is registered last, after all other formats.
+				if _, err := s.Verify(tv.MD5Encoded, "foobar"); !errors.Is(err, ErrPasswordMismatch) {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(sumCalls(calls))/float64(b.N), "verifies/op")
+		})
+	}
+}
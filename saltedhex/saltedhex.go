@@ -0,0 +1,124 @@
+// Package saltedhex provides verification of hex encoded
+// digests of a password and salt, stored together with the salt
+// in a single separated string. This format is common in homegrown
+// applications, for example `md5(password . salt)` stored as
+// `hash:salt` in PHP applications.
+//
+// Note that single iteration digests offer no protection against
+// brute force attacks and should not be used for new applications.
+// This package is only provided for legacy applications
+// that wish to migrate to better methods.
+package saltedhex
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"hash"
+	"strings"
+
+	"github.com/zitadel/passwap/verifier"
+)
+
+// Algorithm is the name reported by the Verifier,
+// see verifier.Named.
+const Algorithm = "saltedhex"
+
+// DefaultSeparator is used when Format.Separator is empty.
+const DefaultSeparator = ":"
+
+// Format describes the layout of the encoded strings
+// accepted by a Verifier.
+type Format struct {
+	// Hash creates the digest, for example md5.New.
+	Hash func() hash.Hash
+
+	// Separator between the hash and salt fields.
+	// DefaultSeparator is used when empty.
+	Separator string
+
+	// SaltFirst indicates the encoded string is `salt:hash`,
+	// instead of `hash:salt`.
+	SaltFirst bool
+
+	// PrependSalt indicates the digest is taken over salt + password,
+	// instead of password + salt.
+	PrependSalt bool
+}
+
+// Verifier for salted hex digests of a Format.
+type Verifier struct {
+	f    Format
+	size int
+}
+
+// New returns a Verifier for encoded strings of f.
+func New(f Format) *Verifier {
+	if f.Separator == "" {
+		f.Separator = DefaultSeparator
+	}
+	return &Verifier{
+		f:    f,
+		size: f.Hash().Size(),
+	}
+}
+
+// parse splits encoded into the decoded digest and salt.
+// ok is false when encoded does not have exactly one separator
+// or the hash field is not a hex encoded digest of the expected size.
+func (v *Verifier) parse(encoded string) (digest []byte, salt string, ok bool) {
+	if strings.Count(encoded, v.f.Separator) != 1 {
+		return nil, "", false
+	}
+	hashField, salt, _ := strings.Cut(encoded, v.f.Separator)
+	if v.f.SaltFirst {
+		hashField, salt = salt, hashField
+	}
+	if len(hashField) != hex.EncodedLen(v.size) {
+		return nil, "", false
+	}
+	digest, err := hex.DecodeString(hashField)
+	if err != nil {
+		return nil, "", false
+	}
+	return digest, salt, true
+}
+
+// Algorithm implements verifier.Named.
+func (v *Verifier) Algorithm() string {
+	return Algorithm
+}
+
+// Prefixes returns nil, as salted hex digests have no prefix,
+// see verifier.Prefixed.
+func Prefixes() []string {
+	return nil
+}
+
+// Verify implements verifier.Verifier.
+// Skip is returned when encoded does not match the shape of the Format.
+// As the format has no identifier, other strings of the same
+// shape might be accepted but fail password verification.
+func (v *Verifier) Verify(encoded, password string) (verifier.Result, error) {
+	digest, salt, ok := v.parse(encoded)
+	if !ok {
+		return verifier.Skip, nil
+	}
+
+	h := v.f.Hash()
+	if v.f.PrependSalt {
+		h.Write([]byte(salt))
+		h.Write([]byte(password))
+	} else {
+		h.Write([]byte(password))
+		h.Write([]byte(salt))
+	}
+
+	return verifier.Result(subtle.ConstantTimeCompare(h.Sum(nil), digest)), nil
+}
+
+// SelfTestVector returns a fixed password and its `md5(password . salt)`
+// digest, which the Verifier of New(Format{Hash: md5.New}) must accept.
+// It guards against regressions of the encoding format, see passwap.SelfTest.
+func SelfTestVector() (password, encoded string) {
+	return "password", `d89eddeec748c49d5add2f8f347b8899:pepper`
+}
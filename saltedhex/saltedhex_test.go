@@ -0,0 +1,118 @@
+package saltedhex
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"testing"
+
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/verifier"
+)
+
+// Digests of "password" and the salt "pepper", generated with Python's hashlib.
+const (
+	md5Appended    = "d89eddeec748c49d5add2f8f347b8899"
+	sha1Prepended  = "73614fd51a90257f32acee922225eb8a815b89c8"
+	sha256Appended = "1c03943fd7783c66d7b5caef930448dd20bb6af87b61aa64ead5fb0183aebecf"
+	testSalt       = "pepper"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   Format
+		encoded  string
+		password string
+		want     verifier.Result
+	}{
+		{
+			name:     "md5 hash:salt",
+			format:   Format{Hash: md5.New},
+			encoded:  md5Appended + ":" + testSalt,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "md5 hash:salt wrong password",
+			format:   Format{Hash: md5.New},
+			encoded:  md5Appended + ":" + testSalt,
+			password: "foobar",
+			want:     verifier.Fail,
+		},
+		{
+			name:     "md5 wrong order",
+			format:   Format{Hash: md5.New, SaltFirst: true},
+			encoded:  md5Appended + ":" + testSalt,
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "md5 no separator",
+			format:   Format{Hash: md5.New},
+			encoded:  tv.MD5PlainHex,
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "md5 multiple separators",
+			format:   Format{Hash: md5.New},
+			encoded:  md5Appended + ":" + testSalt + ":",
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "md5 not hex",
+			format:   Format{Hash: md5.New},
+			encoded:  "zz9eddeec748c49d5add2f8f347b8899:" + testSalt,
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "md5 digest of wrong size",
+			format:   Format{Hash: md5.New},
+			encoded:  sha1Prepended + ":" + testSalt,
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "sha1 salt:hash prepended",
+			format:   Format{Hash: sha1.New, SaltFirst: true, PrependSalt: true},
+			encoded:  testSalt + ":" + sha1Prepended,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "sha1 salt:hash appended",
+			format:   Format{Hash: sha1.New, SaltFirst: true},
+			encoded:  testSalt + ":" + sha1Prepended,
+			password: tv.Password,
+			want:     verifier.Fail,
+		},
+		{
+			name:     "sha256 custom separator",
+			format:   Format{Hash: sha256.New, Separator: "$"},
+			encoded:  sha256Appended + "$" + testSalt,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "sha256 default separator",
+			format:   Format{Hash: sha256.New},
+			encoded:  sha256Appended + "$" + testSalt,
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.format).Verify(tt.encoded, tt.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Verifier.Verify() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package passwap
+
+import "reflect"
+
+// identify returns the parameters of encoded without
+// the creation time, by the first algorithm which recognizes it.
+// ErrNoVerifier is returned when none does.
+func identify(encoded string) (map[string]any, error) {
+	for _, a := range algorithms {
+		if a.identify == nil {
+			continue
+		}
+		params, err := a.identify(encoded)
+		if err != nil {
+			return nil, err
+		}
+		if params != nil {
+			return auditParams(params), nil
+		}
+	}
+	return nil, ErrNoVerifier
+}
+
+// SameParameters reports whether a and b are hashed with the same
+// algorithm and parameters, ignoring their salt, hash and creation time.
+// For example to confirm that a batch re-hash did change the parameters.
+//
+// The hashes are compared by their identifier and parameters, as
+// returned by the Identify function of the argon2, bcrypt, md5, pbkdf2,
+// scrypt and sha1crypt packages. Therefore different variants of an
+// algorithm, such as bcrypt `2a` and `2b`, are not the same.
+// A parse error is returned for malformed hashes,
+// and ErrNoVerifier for hashes of other algorithms.
+func SameParameters(a, b string) (bool, error) {
+	pa, err := identify(a)
+	if err != nil {
+		return false, err
+	}
+	pb, err := identify(b)
+	if err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(pa, pb), nil
+}
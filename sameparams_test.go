@@ -0,0 +1,86 @@
+package passwap
+
+import (
+	"errors"
+	"testing"
+
+	tv "github.com/zitadel/passwap/internal/testvalues"
+)
+
+func TestSameParameters(t *testing.T) {
+	first, err := testHasher.Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := testHasher.Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		a, b    string
+		want    bool
+		wantErr bool
+		// errIs is checked with errors.Is, when set.
+		errIs error
+	}{
+		{
+			name: "same params",
+			a:    first,
+			b:    second,
+			want: true,
+		},
+		{
+			name: "same params, different encoding",
+			a:    tv.EncodedBcryptCost5,
+			b:    tv.EncodedBcryptCost5SingleDigit,
+			want: true,
+		},
+		{
+			name: "different cost",
+			a:    tv.EncodedBcrypt2a,
+			b:    tv.EncodedBcryptCost10,
+			want: false,
+		},
+		{
+			name: "different variant",
+			a:    tv.EncodedBcrypt2a,
+			b:    tv.EncodedBcrypt2b,
+			want: false,
+		},
+		{
+			name: "different algorithm",
+			a:    tv.Argon2idEncoded,
+			b:    tv.EncodedBcrypt2a,
+			want: false,
+		},
+		{
+			name:    "unknown algorithm",
+			a:       tv.Argon2idEncoded,
+			b:       tv.MD5PlainHex,
+			wantErr: true,
+			errIs:   ErrNoVerifier,
+		},
+		{
+			name:    "malformed",
+			a:       "$argon2id$v=19$foo",
+			b:       tv.Argon2idEncoded,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SameParameters(tt.a, tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SameParameters() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if tt.errIs != nil && !errors.Is(err, tt.errIs) {
+				t.Errorf("SameParameters() error = %v, want %v", err, tt.errIs)
+			}
+			if got != tt.want {
+				t.Errorf("SameParameters() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
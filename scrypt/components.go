@@ -0,0 +1,81 @@
+package scrypt
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zitadel/passwap/verifier"
+)
+
+// HashComponents operates like [Hasher.Hash], but returns the
+// parameters, salt and hash instead of an encoded string.
+// This allows databases to store them in separate fields.
+// [Params.Encode] produces the same string as Hash.
+// A creation time, see [WithTimestamp], is not returned.
+func (h *Hasher) HashComponents(password string) (p Params, salt, hash []byte, err error) {
+	salt, hash, err = h.derive(context.Background(), password)
+	if err != nil {
+		return Params{}, nil, nil, err
+	}
+	return h.p, salt, hash, nil
+}
+
+// VerifyComponents operates like [VerifyComponents], and returns
+// NeedUpdate when p differ from the parameters of the Hasher.
+// Fail and a BoundsError are returned without derivation when
+// p would use more memory than the MaxMemory set [WithValidation].
+func (h *Hasher) VerifyComponents(p Params, salt, hash []byte, password string) (verifier.Result, error) {
+	c, err := components(p, salt, hash)
+	if err != nil {
+		return verifier.Fail, err
+	}
+	if err = h.vopts.checkVerifyMemory(c.Params); err != nil {
+		return verifier.Fail, err
+	}
+	res, err := c.verify(context.Background(), password)
+	if err != nil || res == verifier.Fail {
+		return verifier.Fail, err
+	}
+	if h.p != c.Params {
+		return verifier.NeedUpdate, nil
+	}
+	return verifier.OK, nil
+}
+
+// VerifyComponents verifies password against hash and salt,
+// using the parameters p, as returned by [Hasher.HashComponents]
+// or [ParseParams].
+// KeyLen and SaltLen are ignored, as they are implied by
+// the length of hash and salt.
+// Either the result of Fail or OK is returned, or Fail
+// and an error when p can't be used for verification,
+// including when it would use more than [DefaultMaxMemory].
+func VerifyComponents(p Params, salt, hash []byte, password string) (verifier.Result, error) {
+	c, err := components(p, salt, hash)
+	if err != nil {
+		return verifier.Fail, err
+	}
+	if err = (ValidationOpts{}).checkVerifyMemory(c.Params); err != nil {
+		return verifier.Fail, err
+	}
+	return c.verify(context.Background(), password)
+}
+
+// components returns a checker for the parameters, salt and hash.
+func components(p Params, salt, hash []byte) (*checker, error) {
+	if err := checkRP(p.R, p.P); err != nil {
+		return nil, err
+	}
+	if len(hash) == 0 {
+		return nil, errors.New("scrypt: empty hash")
+	}
+
+	c := checker{
+		Params: p,
+		salt:   salt,
+		hash:   hash,
+	}
+	c.KeyLen = len(hash)
+	c.SaltLen = uint32(len(salt))
+	return &c, nil
+}
@@ -0,0 +1,83 @@
+package scrypt
+
+import (
+	"errors"
+	"testing"
+
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/verifier"
+)
+
+func TestHasher_HashComponents(t *testing.T) {
+	h := New(testParams)
+	p, salt, hash, err := h.HashComponents(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != testParams {
+		t.Errorf("Hasher.HashComponents() params = %v, want %v", p, testParams)
+	}
+
+	encoded := p.Encode(salt, hash)
+	if res, err := h.Verify(encoded, tv.Password); res != verifier.OK || err != nil {
+		t.Errorf("Hasher.Verify() = %s, %v, want %s", res, err, verifier.OK)
+	}
+	if res, err := h.VerifyComponents(p, salt, hash, tv.Password); res != verifier.OK || err != nil {
+		t.Errorf("Hasher.VerifyComponents() = %s, %v, want %s", res, err, verifier.OK)
+	}
+	if res, err := VerifyComponents(p, salt, hash, "spanac"); res != verifier.Fail || err != nil {
+		t.Errorf("VerifyComponents() = %s, %v, want %s", res, err, verifier.Fail)
+	}
+
+	p, salt, hash, err = ParseParams(tv.ScryptEncoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, err := VerifyComponents(p, salt, hash, tv.Password); res != verifier.OK || err != nil {
+		t.Errorf("VerifyComponents() = %s, %v, want %s", res, err, verifier.OK)
+	}
+}
+
+func TestVerifyComponents(t *testing.T) {
+	salt := []byte(tv.Salt)
+	wrongR := testParams
+	wrongR.R = 4
+	noP := testParams
+	noP.P = 0
+
+	tests := []struct {
+		name    string
+		p       Params
+		hash    []byte
+		want    verifier.Result
+		wantErr error
+	}{
+		{"ok", testParams, tv.ScryptHash, verifier.OK, nil},
+		{"other params", wrongR, tv.ScryptHash, verifier.Fail, nil},
+		{"invalid p", noP, tv.ScryptHash, verifier.Fail, ErrRP},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyComponents(tt.p, salt, tt.hash, tv.Password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyComponents() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("VerifyComponents() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+	if _, err := VerifyComponents(testParams, salt, nil, tv.Password); err == nil {
+		t.Error("VerifyComponents() with empty hash, error = nil")
+	}
+
+	h := New(wrongR, WithValidation(ValidationOpts{MaxMemory: 1 << 20}))
+	var target *verifier.BoundsError
+	if got, err := h.VerifyComponents(testParams, salt, tv.ScryptHash, tv.Password); got != verifier.Fail || !errors.As(err, &target) {
+		t.Errorf("Hasher.VerifyComponents() = %s, %v, want %s and BoundsError", got, err, verifier.Fail)
+	}
+	h = New(wrongR)
+	if got, err := h.VerifyComponents(testParams, salt, tv.ScryptHash, tv.Password); got != verifier.NeedUpdate || err != nil {
+		t.Errorf("Hasher.VerifyComponents() = %s, %v, want %s", got, err, verifier.NeedUpdate)
+	}
+}
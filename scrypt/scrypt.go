@@ -3,15 +3,22 @@
 package scrypt
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/subtle"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"math"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/zitadel/passwap/internal/encoding"
 	"github.com/zitadel/passwap/internal/salt"
+	"github.com/zitadel/passwap/internal/semaphore"
 	"github.com/zitadel/passwap/verifier"
 	"golang.org/x/crypto/scrypt"
 )
@@ -48,17 +55,199 @@ var (
 	}
 )
 
+// ErrRP is returned when r or p are not positive,
+// or when r * p is not below 2^30, as required by scrypt.
+var ErrRP = errors.New("scrypt: parameters r and p must be positive with r * p < 2^30")
+
+// checkRP checks the scrypt requirement of r * p < 2^30.
+// The product is never computed, as it may overflow
+// for large values of r and p, bypassing the check.
+func checkRP(r, p int) error {
+	if r < 1 || p < 1 || uint64(r) > (1<<30-1)/uint64(p) {
+		return fmt.Errorf("%w: r=%d, p=%d", ErrRP, r, p)
+	}
+	return nil
+}
+
+var memoryBudget atomic.Pointer[semaphore.Weighted]
+
+// SetMemoryBudget bounds the total memory in bytes used by
+// concurrent scrypt derivations in this package, for both hashing
+// and verification. Derivations that would exceed the budget block
+// until enough memory is released by other derivations.
+// Waiting derivations are run in order of arrival.
+// A single derivation that requires more memory than the budget
+// is only run when no other derivations are in progress.
+// A budget of 0 removes the bound, which is the default.
+// The wait can be cancelled with the context passed to
+// [Hasher.HashContext] or [Hasher.VerifyContext].
+//
+// This protects against excessive memory use under a high rate
+// of concurrent logins, at the cost of latency.
+func SetMemoryBudget(bytes uint64) {
+	if bytes == 0 {
+		memoryBudget.Store(nil)
+		return
+	}
+	memoryBudget.Store(semaphore.NewWeighted(bytes))
+}
+
+// deriveKey is scrypt.Key, which tests may replace
+// to observe concurrent derivations.
+var deriveKey = scrypt.Key
+
+// key calls scrypt.Key, within the memory budget if one is set.
+// Scrypt uses about 128 * N * r bytes of memory.
+// An error is returned when ctx is done while waiting for the budget.
+func key(ctx context.Context, password, salt []byte, N, r, p, keyLen int) ([]byte, error) {
+	if budget := memoryBudget.Load(); budget != nil && N > 0 && r > 0 {
+		n := uint64(memory(N, r))
+		if err := budget.Acquire(ctx, n); err != nil {
+			return nil, fmt.Errorf("scrypt memory budget: %w", err)
+		}
+		defer budget.Release(n)
+	}
+	return deriveKey(password, salt, N, r, p, keyLen)
+}
+
 // Format of the Modular Crypt Format, as used by passlib.
 // See https://passlib.readthedocs.io/en/stable/lib/passlib.hash.scrypt.html#format-algorithm
 const Format = "$%s$ln=%d,r=%d,p=%d$%s$%s"
 
-var scanFormat = strings.ReplaceAll(Format, "$", " ")
+// formatTimestamp extends Format with the ts parameter.
+const formatTimestamp = "$%s$ln=%d,r=%d,p=%d,ts=%d$%s$%s"
+
+// Strength implements verifier.Parameters.
+// The work is N times r times p, which is proportional to the
+// number of Salsa20/8 core calls.
+func (p Params) Strength() int {
+	return verifier.WorkStrength(float64(p.N) * float64(p.R) * float64(p.P))
+}
+
+// Encode salt and hash with the parameters, using the Modular Crypt Format.
+// The scrypt Identifier is always used.
+// KeyLen and SaltLen are ignored, as they are implied by
+// the length of hash and salt.
+func (p Params) Encode(salt, hash []byte) string {
+	return p.encode(salt, hash, time.Time{})
+}
+
+// encode salt and hash with the parameters.
+// A non-zero created time is appended as ts parameter.
+func (p Params) encode(salt, hash []byte, created time.Time) string {
+	ln := int(math.Log2(float64(p.N)))
+	encSalt := base64.RawStdEncoding.EncodeToString(salt)
+	encHash := base64.RawStdEncoding.EncodeToString(hash)
+
+	if created.IsZero() {
+		return fmt.Sprintf(Format, Identifier, ln, p.R, p.P, encSalt, encHash)
+	}
+	return fmt.Sprintf(formatTimestamp, Identifier, ln, p.R, p.P, created.Unix(), encSalt, encHash)
+}
+
+// ParseParams parses an encoded scrypt hash string
+// into its parameters, salt and hash.
+func ParseParams(encoded string) (p Params, salt, hash []byte, err error) {
+	c, err := parse(encoded)
+	if err != nil {
+		return Params{}, nil, nil, err
+	}
+	if c == nil {
+		return Params{}, nil, nil, fmt.Errorf("scrypt parse: missing %s or %s prefix", Prefix, Prefix_Linux)
+	}
+	return c.Params, c.salt, c.hash, nil
+}
 
 type checker struct {
 	Params
 
-	hash []byte
-	salt []byte
+	id      string
+	hash    []byte
+	salt    []byte
+	created time.Time
+
+	// rawN is set when N was encoded as is,
+	// instead of its logarithm.
+	rawN bool
+}
+
+// parseParams parses the comma separated key=value parameters
+// of a scrypt hash. Keys may appear in any order,
+// but each of ln, r and p is required exactly once.
+// Some encoders write N instead of ln, which is accepted
+// when it is a power of 2.
+// The optional ts parameter holds the creation time
+// in unix seconds, and is not used for key derivation.
+func (c *checker) parseParams(params string) error {
+	var ln, n, r, p, ts *string
+
+	for _, kv := range strings.Split(params, ",") {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			return fmt.Errorf("scrypt parse: malformed parameter %q", kv)
+		}
+
+		var dst **string
+		switch key {
+		case "ln":
+			dst = &ln
+		case "N":
+			dst = &n
+		case "r":
+			dst = &r
+		case "p":
+			dst = &p
+		case "ts":
+			dst = &ts
+		default:
+			return fmt.Errorf("scrypt parse: unknown parameter %q", key)
+		}
+		if *dst != nil {
+			return fmt.Errorf("scrypt parse: duplicate parameter %q", key)
+		}
+		*dst = &value
+	}
+	if (ln == nil) == (n == nil) || r == nil || p == nil {
+		return fmt.Errorf("scrypt parse: ln or N, r and p parameters required in %q", params)
+	}
+
+	// N is a power of 2 greater than 1.
+	if n != nil {
+		N, err := strconv.ParseUint(*n, 10, 64)
+		if err != nil || N < 2 || N > 1<<62 || N&(N-1) != 0 {
+			return fmt.Errorf("scrypt parse: N %q is not a power of 2", *n)
+		}
+		c.N = int(N)
+		c.rawN = true
+	} else {
+		logN, err := strconv.Atoi(*ln)
+		if err != nil || logN < 1 || logN > 62 {
+			return fmt.Errorf("scrypt parse: invalid ln %q", *ln)
+		}
+		c.N = 1 << logN
+	}
+
+	var err error
+
+	if c.R, err = strconv.Atoi(*r); err != nil {
+		return fmt.Errorf("scrypt parse r: %w", err)
+	}
+	if c.P, err = strconv.Atoi(*p); err != nil {
+		return fmt.Errorf("scrypt parse p: %w", err)
+	}
+	if err = checkRP(c.R, c.P); err != nil {
+		return err
+	}
+
+	if ts != nil {
+		unix, err := strconv.ParseInt(*ts, 10, 64)
+		if err != nil {
+			return fmt.Errorf("scrypt parse timestamp: %w", err)
+		}
+		c.created = time.Unix(unix, 0)
+	}
+
+	return nil
 }
 
 func parse(encoded string) (*checker, error) {
@@ -66,23 +255,18 @@ func parse(encoded string) (*checker, error) {
 		return nil, nil
 	}
 
-	var (
-		id   string
-		ln   int
-		salt string
-		hash string
-		c    checker
-	)
-
-	// scanning needs a space separated string, instead of dollar signs.
-	encoded = strings.ReplaceAll(encoded, "$", " ")
+	// $id$params$salt$hash
+	fields := strings.Split(encoded, "$")
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("scrypt parse: expected 5 fields, got %d", len(fields))
+	}
 
-	_, err := fmt.Sscanf(encoded, scanFormat, &id, &ln, &c.R, &c.P, &salt, &hash)
+	c := checker{id: fields[1]}
+	err := c.parseParams(fields[2])
 	if err != nil {
-		return nil, fmt.Errorf("scrypt parse: %w", err)
+		return nil, err
 	}
-
-	c.N = 1 << ln
+	salt, hash := fields[3], fields[4]
 
 	c.salt, err = base64.RawStdEncoding.Strict().DecodeString(salt)
 	if err != nil {
@@ -93,6 +277,9 @@ func parse(encoded string) (*checker, error) {
 	if err != nil {
 		return nil, fmt.Errorf("scrypt parse hash: %w", err)
 	}
+	if len(c.hash) == 0 {
+		return nil, errors.New("scrypt parse: empty hash")
+	}
 
 	c.KeyLen = len(c.hash)
 	c.SaltLen = uint32(len(c.salt))
@@ -100,8 +287,24 @@ func parse(encoded string) (*checker, error) {
 	return &c, nil
 }
 
-func (c *checker) verify(pw string) (verifier.Result, error) {
-	hash, err := scrypt.Key([]byte(pw), c.salt, c.N, c.R, c.P, c.KeyLen)
+// Describe implements verifier.Parameters.
+func (c *checker) Describe() map[string]any {
+	params := map[string]any{
+		"identifier": c.id,
+		"n":          c.N,
+		"r":          c.R,
+		"p":          c.P,
+		"key_len":    c.KeyLen,
+		"salt_len":   c.SaltLen,
+	}
+	if !c.created.IsZero() {
+		params["created"] = c.created
+	}
+	return params
+}
+
+func (c *checker) verify(ctx context.Context, pw string) (verifier.Result, error) {
+	hash, err := key(ctx, []byte(pw), c.salt, c.N, c.R, c.P, c.KeyLen)
 	if err != nil {
 		return verifier.Fail, err
 	}
@@ -111,69 +314,263 @@ func (c *checker) verify(pw string) (verifier.Result, error) {
 }
 
 type Hasher struct {
-	p    Params
-	rand io.Reader
+	p Params
+	// rand is shared by concurrent calls to Hash and must be
+	// safe for concurrent use, such as crypto/rand.Reader.
+	rand  io.Reader
+	now   func() time.Time
+	vopts ValidationOpts
+}
+
+// Option configures optional behavior of a Hasher.
+type Option func(*Hasher)
+
+// WithInsecureRand sets the source of salts to r, instead of
+// crypto/rand.Reader, so that the Hasher produces reproducible
+// hashes for known-answer test vectors, see package vectors.
+// Hashes with predictable salts must never be stored,
+// hence the name. This is the only way to replace the
+// source of salts, Hashers otherwise always use crypto/rand.Reader.
+// r is shared by concurrent calls to Hash.
+func WithInsecureRand(r io.Reader) Option {
+	return func(h *Hasher) {
+		h.rand = r
+	}
+}
+
+// WithTimestamp appends the creation time of each hash
+// as a `ts=<unix seconds>` parameter, for example
+// `$scrypt$ln=15,r=8,p=1,ts=1700000000$...`.
+// The timestamp is not used for key derivation
+// and the Verifier accepts hashes with and without it.
+// Other scrypt implementations may not accept the parameter.
+func WithTimestamp() Option {
+	return func(h *Hasher) {
+		h.now = time.Now
+	}
 }
 
 // Hash implements passwap.Hasher.
 func (h *Hasher) Hash(password string) (string, error) {
-	salt, err := salt.New(h.rand, h.p.SaltLen)
-	if err != nil {
-		return "", fmt.Errorf("scrypt: %w", err)
-	}
+	return h.HashContext(context.Background(), password)
+}
 
-	hash, err := scrypt.Key([]byte(password), salt, h.p.N, h.p.R, h.p.P, h.p.KeyLen)
+// HashContext operates like [Hasher.Hash]. When a memory budget
+// is set, see [SetMemoryBudget], an error wrapping ctx.Err() is
+// returned when ctx is done before the derivation could start.
+func (h *Hasher) HashContext(ctx context.Context, password string) (string, error) {
+	salt, hash, err := h.derive(ctx, password)
 	if err != nil {
 		return "", err
 	}
 
-	ln := int(math.Log2(float64(h.p.N)))
+	var created time.Time
+	if h.now != nil {
+		created = h.now()
+	}
+	return h.p.encode(salt, hash, created), nil
+}
 
-	return fmt.Sprintf(Format,
-		Identifier, ln, h.p.R, h.p.P,
-		base64.RawStdEncoding.EncodeToString(salt),
-		base64.RawStdEncoding.EncodeToString(hash),
-	), nil
+// derive a hash of password with a new salt,
+// returning salt and hash.
+func (h *Hasher) derive(ctx context.Context, password string) ([]byte, []byte, error) {
+	if err := checkRP(h.p.R, h.p.P); err != nil {
+		return nil, nil, err
+	}
+	s, err := salt.New(h.rand, h.p.SaltLen)
+	if err != nil {
+		return nil, nil, fmt.Errorf("scrypt: %w", err)
+	}
+	hash, err := key(ctx, []byte(password), s, h.p.N, h.p.R, h.p.P, h.p.KeyLen)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, hash, nil
 }
 
-// Verify implements passwap.Verifier
+// Verify implements passwap.Verifier.
+// Hashes in the libsodium layout always need an update
+// to the Modular Crypt Format when the password is correct.
+// Fail and a BoundsError are returned without derivation when
+// encoded would use more memory than the MaxMemory set
+// [WithValidation], or [DefaultMaxMemory].
 func (h *Hasher) Verify(encoded, password string) (verifier.Result, error) {
+	return h.VerifyContext(context.Background(), encoded, password)
+}
+
+// VerifyContext operates like [Hasher.Verify]. When a memory budget
+// is set, see [SetMemoryBudget], Fail and an error wrapping ctx.Err()
+// are returned when ctx is done before the derivation could start.
+func (h *Hasher) VerifyContext(ctx context.Context, encoded, password string) (verifier.Result, error) {
+	if isSodium(encoded) {
+		c, err := parseSodium(encoded)
+		if err != nil {
+			return verifier.Skip, err
+		}
+		if err = h.vopts.checkVerifyMemory(c.Params); err != nil {
+			return verifier.Fail, err
+		}
+		res, err := c.verify(ctx, password)
+		if res == verifier.OK {
+			res = verifier.NeedUpdate
+		}
+		return res, err
+	}
+
 	c, err := parse(encoded)
 	if err != nil || c == nil {
 		return verifier.Skip, err
 	}
+	if err = h.vopts.checkVerifyMemory(c.Params); err != nil {
+		return verifier.Fail, err
+	}
 
-	res, err := c.verify(password)
+	res, err := c.verify(ctx, password)
 	if err != nil || res == 0 {
 		return verifier.Fail, err
 	}
 
-	if h.p != c.Params {
+	if h.p != c.Params || c.rawN {
 		return verifier.NeedUpdate, nil
 	}
 
 	return verifier.OK, nil
 }
 
-func New(p Params) *Hasher {
-	return &Hasher{
+// Identify implements verifier.Identifier.
+func (h *Hasher) Identify(encoded string) (map[string]any, error) {
+	return Identify(encoded)
+}
+
+// HashParameters implements verifier.Configured.
+func (h *Hasher) HashParameters() verifier.Parameters {
+	return &checker{Params: h.p, id: Identifier}
+}
+
+// Parse implements verifier.Parser.
+func (h *Hasher) Parse(encoded string) (verifier.Parameters, error) {
+	return Parse(encoded)
+}
+
+// Algorithm implements verifier.Named.
+func (h *Hasher) Algorithm() string {
+	return Algorithm
+}
+
+// HashPrefixes implements verifier.Prefixed.
+func (h *Hasher) HashPrefixes() []string {
+	return prefixes
+}
+
+func New(p Params, opts ...Option) *Hasher {
+	h := &Hasher{
 		p:    p,
 		rand: rand.Reader,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // Verify parses encoded and uses its scrypt parameters
 // to verify password against its hash.
 // Either the result of Fail or OK is returned,
 // or an error if parsing fails.
+// Fail and a BoundsError are returned without derivation
+// when encoded would use more than [DefaultMaxMemory].
+//
+// Besides the Modular Crypt Format, the `$7$` layout of libsodium's
+// crypto_pwhash_scryptsalsa208sha256_str is supported, for example
+// `$7$C6..../....SodiumChloride$kBGj9fHznVYFQMEn/qDCfrDevf9YDtcDdKvEqHJLV8D`.
 func Verify(encoded, password string) (verifier.Result, error) {
+	if isSodium(encoded) {
+		return verifySodium(encoded, password)
+	}
+
 	c, err := parse(encoded)
 	if err != nil || c == nil {
 		return verifier.Skip, err
 	}
+	if err = (ValidationOpts{}).checkVerifyMemory(c.Params); err != nil {
+		return verifier.Fail, err
+	}
+
+	return c.verify(context.Background(), password)
+}
+
+// Identify parses encoded and returns its identifier
+// and scrypt parameters.
+func Identify(encoded string) (map[string]any, error) {
+	p, err := Parse(encoded)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	return p.Describe(), nil
+}
+
+// Parse parses encoded, including the libsodium format,
+// and returns its scrypt parameters.
+// Nil Parameters and a nil error are returned when
+// encoded is not a scrypt hash.
+func Parse(encoded string) (verifier.Parameters, error) {
+	if isSodium(encoded) {
+		c, err := parseSodium(encoded)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
 
-	return c.verify(password)
+	c, err := parse(encoded)
+	if err != nil || c == nil {
+		return nil, err
+	}
+	return c, nil
 }
 
+// Canonicalize parses encoded and encodes it again in the form
+// produced by the Hasher: the scrypt identifier, parameters in
+// ln, r, p order, followed by the optional ts parameter, and salt
+// and hash in base64 without padding. Padded salt and hash are accepted.
+// Hashes in the libsodium format are returned unchanged.
+func Canonicalize(encoded string) (string, error) {
+	if isSodium(encoded) {
+		if _, err := parseSodium(encoded); err != nil {
+			return "", err
+		}
+		return encoded, nil
+	}
+
+	c, err := parse(encoding.TrimPadding(encoded, 2))
+	if err != nil {
+		return "", err
+	}
+	if c == nil {
+		return "", fmt.Errorf("scrypt parse: missing %s or %s prefix", Prefix, Prefix_Linux)
+	}
+	return c.encode(c.salt, c.hash, c.created), nil
+}
+
+// Algorithm is the name reported by the Verifier and Hasher,
+// see verifier.Named.
+const Algorithm = "scrypt"
+
+// prefixes recognized by the Verifier and Hasher.
+var prefixes = []string{Prefix, Prefix_Linux}
+
 // Verifier for Scrypt.
-var Verifier = verifier.VerifyFunc(Verify)
+var Verifier = &verifier.NamedFunc{Name: Algorithm, VerifyFunc: Verify, Prefixes: prefixes}
+
+// Prefixes returns the prefixes of the hashes handled by this package,
+// as recognized by the Verifier, see verifier.Prefixed.
+func Prefixes() []string {
+	return append([]string(nil), prefixes...)
+}
+
+// SelfTestVector returns a fixed password and its encoded hash,
+// which [Verify] must accept. It guards against regressions
+// of the encoding format, see passwap.SelfTest.
+func SelfTestVector() (password, encoded string) {
+	return "password", `$scrypt$ln=10,r=8,p=1$cmFuZG9tc2FsdGlzaGFyZA$lC6JZQ7kpuqKghyrA7I+9l0/8DQ2xgz1veuSUWKB97M`
+}
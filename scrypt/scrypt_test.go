@@ -1,14 +1,20 @@
 package scrypt
 
 import (
+	"context"
+	"errors"
 	"io"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/zitadel/passwap/internal/salt"
 	tv "github.com/zitadel/passwap/internal/testvalues"
 	"github.com/zitadel/passwap/verifier"
+	"golang.org/x/crypto/scrypt"
 )
 
 var (
@@ -37,6 +43,66 @@ func Test_parse(t *testing.T) {
 			encoded: "$scrypt$!!!!",
 			wantErr: true,
 		},
+		{
+			name:    "r * p overflow",
+			encoded: strings.Replace(tv.ScryptEncoded, "r=8,p=1", "r=4294967296,p=4294967296", 1),
+			wantErr: true,
+		},
+		{
+			name:    "r * p too large",
+			encoded: strings.Replace(tv.ScryptEncoded, "r=8,p=1", "r=1073741824,p=1", 1),
+			wantErr: true,
+		},
+		{
+			name:    "p zero",
+			encoded: strings.Replace(tv.ScryptEncoded, "r=8,p=1", "r=8,p=0", 1),
+			wantErr: true,
+		},
+		{
+			name:    "ln zero",
+			encoded: strings.Replace(tv.ScryptEncoded, "ln=16", "ln=0", 1),
+			wantErr: true,
+		},
+		{
+			name:    "ln negative",
+			encoded: strings.Replace(tv.ScryptEncoded, "ln=", "ln=-", 1),
+			wantErr: true,
+		},
+		{
+			name:    "N not a power of 2",
+			encoded: strings.Replace(tv.ScryptEncoded, "ln=16", "N=65537", 1),
+			wantErr: true,
+		},
+		{
+			name:    "N one",
+			encoded: strings.Replace(tv.ScryptEncoded, "ln=16", "N=1", 1),
+			wantErr: true,
+		},
+		{
+			name:    "N and ln",
+			encoded: strings.Replace(tv.ScryptEncoded, "ln=16", "ln=16,N=65536", 1),
+			wantErr: true,
+		},
+		{
+			name:    "missing param",
+			encoded: strings.Replace(tv.ScryptEncoded, "r=8,", "", 1),
+			wantErr: true,
+		},
+		{
+			name:    "duplicate param",
+			encoded: strings.Replace(tv.ScryptEncoded, "r=8,", "r=8,r=8,", 1),
+			wantErr: true,
+		},
+		{
+			name:    "unknown param",
+			encoded: strings.Replace(tv.ScryptEncoded, "r=8,", "r=8,x=1,", 1),
+			wantErr: true,
+		},
+		{
+			name:    "timestamp error",
+			encoded: strings.Replace(tv.ScryptEncoded, "p=1", "p=1,ts=foo", 1),
+			wantErr: true,
+		},
 		{
 			name:    "salt error",
 			encoded: strings.ReplaceAll(tv.ScryptEncoded, "cmFuZG9tc2FsdGlzaGFyZA", "!!!"),
@@ -52,15 +118,39 @@ func Test_parse(t *testing.T) {
 			encoded: tv.ScryptEncoded,
 			want: &checker{
 				Params: testParams,
+				id:     Identifier,
 				hash:   tv.ScryptHash,
 				salt:   []byte(tv.Salt),
 			},
 		},
+		{
+			name:    "timestamp",
+			encoded: strings.Replace(tv.ScryptEncoded, "p=1", "p=1,ts=1700000000", 1),
+			want: &checker{
+				Params:  testParams,
+				id:      Identifier,
+				hash:    tv.ScryptHash,
+				salt:    []byte(tv.Salt),
+				created: time.Unix(1700000000, 0),
+			},
+		},
+		{
+			name:    "raw N",
+			encoded: strings.Replace(tv.ScryptEncoded, "ln=16", "N=65536", 1),
+			want: &checker{
+				Params: testParams,
+				id:     Identifier,
+				hash:   tv.ScryptHash,
+				salt:   []byte(tv.Salt),
+				rawN:   true,
+			},
+		},
 		{
 			name:    "linux",
 			encoded: strings.ReplaceAll(tv.ScryptEncoded, "scrypt", "7"),
 			want: &checker{
 				Params: testParams,
+				id:     Identifier_Linux,
 				hash:   tv.ScryptHash,
 				salt:   []byte(tv.Salt),
 			},
@@ -116,7 +206,7 @@ func Test_checker_verify(t *testing.T) {
 				c.Params.N = tt.N
 			}
 
-			got, err := c.verify(tt.pw)
+			got, err := c.verify(context.Background(), tt.pw)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("checker.verify() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -128,10 +218,39 @@ func Test_checker_verify(t *testing.T) {
 	}
 }
 
+func Test_checkRP(t *testing.T) {
+	tests := []struct {
+		name    string
+		r, p    int
+		wantErr bool
+	}{
+		{"ok", 8, 1, false},
+		{"limit", 1<<30 - 1, 1, false},
+		{"product limit", 1 << 15, 1<<15 - 1, false},
+		{"product too large", 1 << 15, 1 << 15, true},
+		{"32-bit overflow", 1 << 16, 1 << 16, true},
+		{"64-bit overflow", 1 << 32, 1 << 32, true},
+		{"r zero", 0, 1, true},
+		{"p negative", 8, -1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRP(tt.r, tt.p)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkRP() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrRP) {
+				t.Errorf("checkRP() error = %v, want %v", err, ErrRP)
+			}
+		})
+	}
+}
+
 func TestHasher_Hash(t *testing.T) {
 	tests := []struct {
 		name    string
 		N       int
+		P       int
 		rand    io.Reader
 		want    string
 		wantErr bool
@@ -147,6 +266,12 @@ func TestHasher_Hash(t *testing.T) {
 			rand:    tv.SaltReader(),
 			wantErr: true,
 		},
+		{
+			name:    "r * p overflow",
+			P:       1 << 62,
+			rand:    tv.SaltReader(),
+			wantErr: true,
+		},
 		{
 			name: "succes",
 			rand: tv.SaltReader(),
@@ -162,6 +287,9 @@ func TestHasher_Hash(t *testing.T) {
 			if tt.N != 0 {
 				h.p.N = tt.N
 			}
+			if tt.P != 0 {
+				h.p.P = tt.P
+			}
 
 			got, err := h.Hash(tv.Password)
 			if (err != nil) != tt.wantErr {
@@ -175,6 +303,25 @@ func TestHasher_Hash(t *testing.T) {
 	}
 }
 
+func TestHasher_Hash_entropy(t *testing.T) {
+	readers := map[string]func() io.Reader{
+		"error":      func() io.Reader { return salt.ErrReader{} },
+		"short read": func() io.Reader { return strings.NewReader("short") },
+	}
+	for name, r := range readers {
+		t.Run(name, func(t *testing.T) {
+			h := &Hasher{p: testParams, rand: r()}
+			got, err := h.Hash(tv.Password)
+			if !errors.Is(err, salt.ErrEntropy) {
+				t.Errorf("Hasher.Hash() error = %v, want %v", err, salt.ErrEntropy)
+			}
+			if got != "" {
+				t.Errorf("Hasher.Hash() = %s, want empty", got)
+			}
+		})
+	}
+}
+
 func TestHasher_Verify(t *testing.T) {
 	type args struct {
 		encoded  string
@@ -212,6 +359,12 @@ func TestHasher_Verify(t *testing.T) {
 			args: args{tv.ScryptEncoded, tv.Password},
 			want: verifier.NeedUpdate,
 		},
+		{
+			name: "raw N need update",
+			p:    testParams,
+			args: args{strings.Replace(tv.ScryptEncoded, "ln=16", "N=65536", 1), tv.Password},
+			want: verifier.NeedUpdate,
+		},
 		{
 			name: "succes",
 			p:    testParams,
@@ -253,6 +406,48 @@ func TestHasher(t *testing.T) {
 	}
 }
 
+func TestWithTimestamp(t *testing.T) {
+	created := time.Unix(1700000000, 0)
+	withTS := New(testParams, WithTimestamp())
+	withTS.now = func() time.Time { return created }
+	withoutTS := New(testParams)
+
+	encodedTS, err := withTS.Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(encodedTS, ",ts=1700000000$") {
+		t.Errorf("Hasher.Hash() = %s, want ts parameter", encodedTS)
+	}
+	encoded, err := withoutTS.Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(encoded, "ts=") {
+		t.Errorf("Hasher.Hash() = %s, want no ts parameter", encoded)
+	}
+
+	for _, h := range []*Hasher{withTS, withoutTS} {
+		for _, e := range []string{encodedTS, encoded} {
+			res, err := h.Verify(e, tv.Password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res != verifier.OK {
+				t.Errorf("Hasher.Verify(%s) = %s, want %s", e, res, verifier.OK)
+			}
+		}
+	}
+
+	params, err := Identify(encodedTS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := params["created"]; got != created {
+		t.Errorf("Identify() created = %v, want %v", got, created)
+	}
+}
+
 func TestVerify(t *testing.T) {
 	type args struct {
 		encoded  string
@@ -270,6 +465,24 @@ func TestVerify(t *testing.T) {
 			want:    verifier.Skip,
 			wantErr: true,
 		},
+		{
+			name:    "empty hash",
+			args:    args{"$scrypt$ln=4,r=8,p=1$c2FsdHNhbHQ$", "wrong"},
+			want:    verifier.Skip,
+			wantErr: true,
+		},
+		{
+			name:    "empty hash, N",
+			args:    args{"$scrypt$N=16,r=8,p=1$c2FsdHNhbHQ$", "wrong"},
+			want:    verifier.Skip,
+			wantErr: true,
+		},
+		{
+			name:    "empty hash, timestamp",
+			args:    args{"$scrypt$ln=4,r=8,p=1,ts=1700000000$c2FsdHNhbHQ$", "wrong"},
+			want:    verifier.Skip,
+			wantErr: true,
+		},
 		{
 			name: "wrong password",
 			args: args{tv.ScryptEncoded, "foo"},
@@ -294,3 +507,212 @@ func TestVerify(t *testing.T) {
 		})
 	}
 }
+
+func TestIdentify(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		want    map[string]any
+		wantErr bool
+	}{
+		{
+			name:    "skip",
+			encoded: "foobar",
+		},
+		{
+			name:    "parse error",
+			encoded: "$scrypt$!!!!",
+			wantErr: true,
+		},
+		{
+			name:    "success",
+			encoded: tv.ScryptEncoded,
+			want: map[string]any{
+				"identifier": Identifier,
+				"n":          tv.ScryptN,
+				"r":          tv.ScryptR,
+				"p":          tv.ScryptP,
+				"key_len":    tv.KeyLen,
+				"salt_len":   tv.SaltLen,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(testParams).Identify(tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Identify() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Identify() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetMemoryBudget(t *testing.T) {
+	const (
+		maxConcurrent = 2
+		calls         = 10
+	)
+	p := Params{N: 1024, R: 8, P: 1, KeyLen: tv.KeyLen, SaltLen: tv.SaltLen}
+	SetMemoryBudget(maxConcurrent * uint64(memory(p.N, p.R)))
+	defer SetMemoryBudget(0)
+
+	var current, max atomic.Int32
+	defer func(old func(password, salt []byte, N, r, p, keyLen int) ([]byte, error)) { deriveKey = old }(deriveKey)
+	deriveKey = func(password, salt []byte, N, r, p, keyLen int) ([]byte, error) {
+		c := current.Add(1)
+		for old := max.Load(); c > old && !max.CompareAndSwap(old, c); old = max.Load() {
+		}
+		time.Sleep(5 * time.Millisecond)
+		current.Add(-1)
+		return scrypt.Key(password, salt, N, r, p, keyLen)
+	}
+
+	h := New(p)
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			encoded, err := h.Hash(tv.Password)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if res, err := h.Verify(encoded, tv.Password); err != nil || res != verifier.OK {
+				t.Errorf("Hasher.Verify() = %s, %v, want %s", res, err, verifier.OK)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := max.Load(); got < 1 || got > maxConcurrent {
+		t.Errorf("concurrent derivations = %d, want between 1 and %d", got, maxConcurrent)
+	}
+
+	SetMemoryBudget(0)
+	if budget := memoryBudget.Load(); budget != nil {
+		t.Error("memory budget not removed")
+	}
+}
+
+func TestSetMemoryBudget_context(t *testing.T) {
+	n := uint64(memory(testParams.N, testParams.R))
+	SetMemoryBudget(n)
+	defer SetMemoryBudget(0)
+
+	// Exhaust the budget, so that derivations have to wait.
+	budget := memoryBudget.Load()
+	if err := budget.Acquire(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+	defer budget.Release(n)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	h := New(testParams)
+	if _, err := h.HashContext(ctx, tv.Password); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Hasher.HashContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	res, err := h.VerifyContext(ctx, tv.ScryptEncoded, tv.Password)
+	if res != verifier.Fail || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Hasher.VerifyContext() = %s, %v, want %s, %v", res, err, verifier.Fail, context.DeadlineExceeded)
+	}
+}
+
+func TestParseParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		wantErr bool
+	}{
+		{
+			name:    "missing prefix",
+			encoded: "foobar",
+			wantErr: true,
+		},
+		{
+			name:    "parse error",
+			encoded: "$scrypt$!!!!",
+			wantErr: true,
+		},
+		{
+			name:    "scrypt",
+			encoded: tv.ScryptEncoded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, salt, hash, err := ParseParams(tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseParams() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := p.Encode(salt, hash); got != tt.encoded {
+				t.Errorf("Params.Encode() =\n%s\nwant\n%s", got, tt.encoded)
+			}
+		})
+	}
+}
+
+func TestWithInsecureRand(t *testing.T) {
+	newTestHasher := func(opts ...Option) *Hasher { return New(testParams, opts...) }
+
+	if err := salt.AssertCryptoRand(newTestHasher().rand); err != nil {
+		t.Errorf("default Hasher: %v", err)
+	}
+
+	newHasher := func() *Hasher {
+		return newTestHasher(WithInsecureRand(strings.NewReader(strings.Repeat(tv.Salt, 4))))
+	}
+	h := newHasher()
+	if err := salt.AssertCryptoRand(h.rand); !errors.Is(err, salt.ErrInsecureRand) {
+		t.Errorf("AssertCryptoRand() = %v, want %v", err, salt.ErrInsecureRand)
+	}
+	first, err := h.Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := newHasher().Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Errorf("Hasher.Hash() = %s, then %s, want identical", first, second)
+	}
+}
+
+func TestHasher_HashParameters(t *testing.T) {
+	hashers := map[string]*Hasher{
+		"scrypt":    New(testParams),
+		"timestamp": New(testParams, WithTimestamp()),
+	}
+	for name, h := range hashers {
+		t.Run(name, func(t *testing.T) {
+			encoded, err := h.Hash(tv.Password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := Parse(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantParams := want.Describe()
+			delete(wantParams, "created")
+
+			got := h.HashParameters()
+			if !reflect.DeepEqual(got.Describe(), wantParams) {
+				t.Errorf("Hasher.HashParameters() = %v, want %v", got.Describe(), wantParams)
+			}
+			if got.Strength() != want.Strength() {
+				t.Errorf("Hasher.HashParameters() strength = %d, want %d", got.Strength(), want.Strength())
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package scrypt
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"github.com/zitadel/passwap/internal/encoding"
+	"github.com/zitadel/passwap/verifier"
+)
+
+// libsodium's crypto_pwhash_scryptsalsa208sha256_str
+// uses the Prefix_Linux identifier with a packed layout:
+// `$7$<N><r><p><salt>$<hash>`.
+// N is encoded as a single character holding log2(N),
+// r and p are encoded as 5 characters of 30 bit little-endian
+// integers, using the crypt(3) character set.
+// The salt is used as-is, and the 32 byte hash
+// is encoded using the crypt(3) character set.
+// See https://github.com/jedisct1/libsodium/blob/master/src/libsodium/crypto_pwhash/scryptsalsa208sha256/crypto_scrypt-common.c
+const (
+	sodiumKeyLen     = 32
+	sodiumHashLen    = 43
+	sodiumUintLen    = 5
+	sodiumSettingLen = 1 + 2*sodiumUintLen
+)
+
+// isSodium reports if encoded uses the libsodium layout.
+// Unlike the Modular Crypt Format, it has no `$`
+// between the parameters and salt.
+func isSodium(encoded string) bool {
+	return strings.HasPrefix(encoded, Prefix_Linux) && strings.Count(encoded, "$") == 3
+}
+
+type sodiumChecker struct {
+	Params
+
+	salt []byte
+	hash []byte
+}
+
+// decodeSodiumUint decodes a 30 bit little-endian integer.
+func decodeSodiumUint(s string) (int, error) {
+	var v int
+	for i := 0; i < sodiumUintLen; i++ {
+		d := strings.IndexByte(encoding.Crypt3, s[i])
+		if d < 0 {
+			return 0, fmt.Errorf("invalid character %q", s[i])
+		}
+		v |= d << (6 * i)
+	}
+	return v, nil
+}
+
+func parseSodium(encoded string) (*sodiumChecker, error) {
+	setting, hash, _ := strings.Cut(encoded[len(Prefix_Linux):], "$")
+	if len(setting) < sodiumSettingLen {
+		return nil, fmt.Errorf("scrypt parse: libsodium setting %q too short", setting)
+	}
+
+	logN := strings.IndexByte(encoding.Crypt3, setting[0])
+	if logN < 1 || logN > 62 {
+		return nil, fmt.Errorf("scrypt parse: invalid libsodium N %q", setting[0])
+	}
+
+	var (
+		c   = sodiumChecker{salt: []byte(setting[sodiumSettingLen:])}
+		err error
+	)
+	c.N = 1 << logN
+	if c.R, err = decodeSodiumUint(setting[1:]); err != nil {
+		return nil, fmt.Errorf("scrypt parse libsodium r: %w", err)
+	}
+	if c.P, err = decodeSodiumUint(setting[1+sodiumUintLen:]); err != nil {
+		return nil, fmt.Errorf("scrypt parse libsodium p: %w", err)
+	}
+	if err = checkRP(c.R, c.P); err != nil {
+		return nil, err
+	}
+
+	if len(hash) != sodiumHashLen {
+		return nil, fmt.Errorf("scrypt parse: libsodium hash length %d, want %d", len(hash), sodiumHashLen)
+	}
+	if c.hash, err = encoding.DecodeCrypt3([]byte(hash)); err != nil {
+		return nil, fmt.Errorf("scrypt parse libsodium hash: %w", err)
+	}
+
+	c.KeyLen = sodiumKeyLen
+	c.SaltLen = uint32(len(c.salt))
+
+	return &c, nil
+}
+
+// Describe implements verifier.Parameters.
+func (c *sodiumChecker) Describe() map[string]any {
+	return map[string]any{
+		"identifier": Identifier_Linux,
+		"n":          c.N,
+		"r":          c.R,
+		"p":          c.P,
+		"key_len":    c.KeyLen,
+		"salt_len":   c.SaltLen,
+	}
+}
+
+func (c *sodiumChecker) verify(ctx context.Context, pw string) (verifier.Result, error) {
+	hash, err := key(ctx, []byte(pw), c.salt, c.N, c.R, c.P, c.KeyLen)
+	if err != nil {
+		return verifier.Fail, err
+	}
+	res := subtle.ConstantTimeCompare(hash, c.hash)
+
+	return verifier.Result(res), nil
+}
+
+// verifySodium parses and verifies encoded in the libsodium layout.
+func verifySodium(encoded, password string) (verifier.Result, error) {
+	c, err := parseSodium(encoded)
+	if err != nil {
+		return verifier.Skip, err
+	}
+	if err = (ValidationOpts{}).checkVerifyMemory(c.Params); err != nil {
+		return verifier.Fail, err
+	}
+	return c.verify(context.Background(), password)
+}
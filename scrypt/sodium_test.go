@@ -0,0 +1,146 @@
+package scrypt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zitadel/passwap/internal/encoding"
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/verifier"
+)
+
+func Test_isSodium(t *testing.T) {
+	tests := []struct {
+		encoded string
+		want    bool
+	}{
+		{tv.ScryptSodiumEncoded, true},
+		{tv.ScryptEncoded, false},
+		{strings.Replace(tv.ScryptEncoded, Prefix, Prefix_Linux, 1), false},
+		{tv.Argon2idEncoded, false},
+	}
+	for _, tt := range tests {
+		if got := isSodium(tt.encoded); got != tt.want {
+			t.Errorf("isSodium(%s) = %t, want %t", tt.encoded, got, tt.want)
+		}
+	}
+}
+
+func mustDecodeCrypt3(t *testing.T, s string) []byte {
+	b, err := encoding.DecodeCrypt3([]byte(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func Test_parseSodium(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		want    *sodiumChecker
+		wantErr bool
+	}{
+		{
+			name:    "short setting",
+			encoded: "$7$C6....$" + strings.Repeat(".", sodiumHashLen),
+			wantErr: true,
+		},
+		{
+			name:    "invalid N",
+			encoded: "$7$.6..../....SodiumChloride$kBGj9fHznVYFQMEn/qDCfrDevf9YDtcDdKvEqHJLV8D",
+			wantErr: true,
+		},
+		{
+			name:    "invalid r",
+			encoded: "$7$C6.!../....SodiumChloride$kBGj9fHznVYFQMEn/qDCfrDevf9YDtcDdKvEqHJLV8D",
+			wantErr: true,
+		},
+		{
+			name:    "zero p",
+			encoded: "$7$C6.........SodiumChloride$kBGj9fHznVYFQMEn/qDCfrDevf9YDtcDdKvEqHJLV8D",
+			wantErr: true,
+		},
+		{
+			name:    "short hash",
+			encoded: "$7$C6..../....SodiumChloride$kBGj9fHznVYFQMEn",
+			wantErr: true,
+		},
+		{
+			name:    "invalid hash",
+			encoded: "$7$C6..../....SodiumChloride$kBGj9fHznVYFQMEn/qDCfrDevf9YDtcDdKvEqHJLV8+",
+			wantErr: true,
+		},
+		{
+			name:    "success",
+			encoded: tv.ScryptSodiumEncoded,
+			want: &sodiumChecker{
+				Params: Params{
+					N:       16384,
+					R:       8,
+					P:       1,
+					KeyLen:  32,
+					SaltLen: 14,
+				},
+				salt: []byte("SodiumChloride"),
+				hash: mustDecodeCrypt3(t, "kBGj9fHznVYFQMEn/qDCfrDevf9YDtcDdKvEqHJLV8D"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSodium(tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseSodium() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSodium() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerify_sodium(t *testing.T) {
+	tests := []struct {
+		name     string
+		v        verifier.Verifier
+		password string
+		want     verifier.Result
+	}{
+		{"Verify", verifier.VerifyFunc(Verify), tv.ScryptSodiumPassword, verifier.OK},
+		{"Verify wrong password", verifier.VerifyFunc(Verify), tv.Password, verifier.Fail},
+		{"Hasher", New(RecommendedParams), tv.ScryptSodiumPassword, verifier.NeedUpdate},
+		{"Hasher wrong password", New(RecommendedParams), tv.Password, verifier.Fail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.v.Verify(tv.ScryptSodiumEncoded, tt.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentify_sodium(t *testing.T) {
+	want := map[string]any{
+		"identifier": Identifier_Linux,
+		"n":          16384,
+		"r":          8,
+		"p":          1,
+		"key_len":    32,
+		"salt_len":   uint32(14),
+	}
+	got, err := Identify(tv.ScryptSodiumEncoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Identify() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,143 @@
+package scrypt
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/zitadel/passwap/verifier"
+)
+
+// ValidationOpts bound the parameters of scrypt hashes,
+// as checked by [Validate] and [Hasher.Validate].
+// Zero values are not checked.
+type ValidationOpts struct {
+	MinN int
+	MaxN int
+	MinR int
+	MaxR int
+	MinP int
+	MaxP int
+
+	// MaxMemory bounds the memory in bytes used by
+	// a derivation, which is 128 * N * r.
+	// A Hasher refuses to verify hashes above it,
+	// or above DefaultMaxMemory when it is zero.
+	MaxMemory  int64
+	MinKeyLen  int
+	MinSaltLen uint32
+}
+
+// RecommendedValidationOpts reject parameters below the
+// OWASP minimum of N=2^13 and parameters which would
+// use more than 1 GiB of memory on verification.
+var RecommendedValidationOpts = ValidationOpts{
+	MinN:       1 << 13,
+	MinR:       1,
+	MinP:       1,
+	MaxMemory:  1 << 30,
+	MinKeyLen:  16,
+	MinSaltLen: 8,
+}
+
+// DefaultMaxMemory bounds the memory in bytes used to verify
+// a single hash, unless a MaxMemory is set [WithValidation].
+// It also applies to [Verify] and [VerifyComponents], so that
+// crafted hashes can't exhaust memory.
+const DefaultMaxMemory = 1 << 30
+
+// memory returns the bytes used by a derivation
+// with N and r, saturated at math.MaxInt64.
+func memory(N, r int) int64 {
+	if N < 1 || r < 1 {
+		return 0
+	}
+	if uint64(N) > math.MaxInt64/128/uint64(r) {
+		return math.MaxInt64
+	}
+	return 128 * int64(N) * int64(r)
+}
+
+// checkMemory returns a BoundsError when
+// p would use more than MaxMemory.
+func (o ValidationOpts) checkMemory(p Params) error {
+	return verifier.CheckBounds(Algorithm, "memory", memory(p.N, p.R), 0, o.MaxMemory)
+}
+
+// checkVerifyMemory returns a BoundsError when verifying p would
+// use more than MaxMemory, or DefaultMaxMemory when it is zero.
+func (o ValidationOpts) checkVerifyMemory(p Params) error {
+	max := o.MaxMemory
+	if max == 0 {
+		max = DefaultMaxMemory
+	}
+	return verifier.CheckBounds(Algorithm, "memory", memory(p.N, p.R), 0, max)
+}
+
+// check returns a BoundsError for the first
+// parameter of p which is out of bounds.
+func (o ValidationOpts) check(p Params) error {
+	bounds := []struct {
+		param    string
+		value    int64
+		min, max int64
+	}{
+		{"N", int64(p.N), int64(o.MinN), int64(o.MaxN)},
+		{"r", int64(p.R), int64(o.MinR), int64(o.MaxR)},
+		{"p", int64(p.P), int64(o.MinP), int64(o.MaxP)},
+		{"keylen", int64(p.KeyLen), int64(o.MinKeyLen), 0},
+		{"saltlen", int64(p.SaltLen), int64(o.MinSaltLen), 0},
+	}
+	for _, b := range bounds {
+		if err := verifier.CheckBounds(Algorithm, b.param, b.value, b.min, b.max); err != nil {
+			return err
+		}
+	}
+	return o.checkMemory(p)
+}
+
+// Validate parses encoded, including the libsodium format,
+// and checks its parameters against opts.
+// A [verifier.BoundsError] is returned for the first parameter
+// which is out of bounds.
+func Validate(encoded string, opts ValidationOpts) error {
+	params, err := parseParams(encoded)
+	if err != nil {
+		return err
+	}
+	return opts.check(params)
+}
+
+// parseParams parses encoded, including the libsodium format,
+// and returns its Params.
+func parseParams(encoded string) (Params, error) {
+	if isSodium(encoded) {
+		c, err := parseSodium(encoded)
+		if err != nil {
+			return Params{}, err
+		}
+		return c.Params, nil
+	}
+	c, err := parse(encoded)
+	if err != nil {
+		return Params{}, err
+	}
+	if c == nil {
+		return Params{}, fmt.Errorf("scrypt validate: missing %s or %s prefix", Prefix, Prefix_Linux)
+	}
+	return c.Params, nil
+}
+
+// WithValidation sets the bounds used by [Hasher.Validate].
+// The MaxMemory bound is also enforced by [Hasher.Verify],
+// before any memory is allocated, see [DefaultMaxMemory].
+// By default no bounds are checked.
+func WithValidation(opts ValidationOpts) Option {
+	return func(h *Hasher) {
+		h.vopts = opts
+	}
+}
+
+// Validate implements verifier.Validator.
+func (h *Hasher) Validate(encoded string) error {
+	return Validate(encoded, h.vopts)
+}
@@ -0,0 +1,137 @@
+package scrypt
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/verifier"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		encoded   string
+		opts      ValidationOpts
+		wantParam string
+		wantErr   bool
+	}{
+		{
+			name:    "unbounded",
+			encoded: tv.ScryptEncoded,
+		},
+		{
+			name:    "recommended",
+			encoded: tv.ScryptEncoded,
+			opts:    RecommendedValidationOpts,
+		},
+		{
+			name:      "N below",
+			encoded:   tv.ScryptEncoded,
+			opts:      ValidationOpts{MinN: 1 << 17},
+			wantParam: "N",
+			wantErr:   true,
+		},
+		{
+			name:      "r above",
+			encoded:   tv.ScryptEncoded,
+			opts:      ValidationOpts{MaxR: 4},
+			wantParam: "r",
+			wantErr:   true,
+		},
+		{
+			name:      "memory above",
+			encoded:   tv.ScryptEncoded,
+			opts:      ValidationOpts{MaxMemory: 32 << 20},
+			wantParam: "memory",
+			wantErr:   true,
+		},
+		{
+			name:      "sodium",
+			encoded:   tv.ScryptSodiumEncoded,
+			opts:      ValidationOpts{MinN: 1 << 15},
+			wantParam: "N",
+			wantErr:   true,
+		},
+		{
+			name:    "parse error",
+			encoded: "$scrypt$!!!!",
+			wantErr: true,
+		},
+		{
+			name:    "not scrypt",
+			encoded: tv.Argon2idEncoded,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.encoded, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var target *verifier.BoundsError
+			if errors.As(err, &target) != (tt.wantParam != "") {
+				t.Fatalf("Validate() error = %v, want BoundsError %t", err, tt.wantParam != "")
+			}
+			if target != nil && target.Param != tt.wantParam {
+				t.Errorf("Validate() param = %s, want %s", target.Param, tt.wantParam)
+			}
+		})
+	}
+}
+
+func TestHasher_Verify_maxMemory(t *testing.T) {
+	verifiers := map[string]verifier.VerifyFunc{
+		"validation": New(testParams, WithValidation(RecommendedValidationOpts)).Verify,
+		"default":    New(testParams).Verify,
+		"Verify":     Verify,
+		"Verifier":   Verifier.Verify,
+	}
+
+	// 128 * 2^30 * 32 bytes would be 4 TiB.
+	tests := []string{
+		strings.Replace(tv.ScryptEncoded, "ln=16,r=8", "ln=30,r=32", 1),
+		strings.Replace(tv.ScryptSodiumEncoded, "$7$C6", "$7$Ua", 1),
+	}
+	for name, verify := range verifiers {
+		t.Run(name, func(t *testing.T) {
+			for _, encoded := range tests {
+				start := time.Now()
+				got, err := verify(encoded, tv.Password)
+				if elapsed := time.Since(start); elapsed > time.Second {
+					t.Errorf("Verify() took %v", elapsed)
+				}
+				var target *verifier.BoundsError
+				if got != verifier.Fail || !errors.As(err, &target) || target.Param != "memory" {
+					t.Errorf("Verify(%q) = %v, %v, want Fail and memory BoundsError", encoded, got, err)
+				}
+			}
+
+			got, err := verify(tv.ScryptEncoded, tv.Password)
+			if got != verifier.OK || err != nil {
+				t.Errorf("Verify() = %v, %v, want OK", got, err)
+			}
+		})
+	}
+}
+
+func Test_memory(t *testing.T) {
+	tests := []struct {
+		N, r int
+		want int64
+	}{
+		{0, 8, 0},
+		{1 << 15, 8, 32 << 20},
+		{1 << 30, 32, 4 << 40},
+		{1 << 62, 1 << 20, math.MaxInt64},
+	}
+	for _, tt := range tests {
+		if got := memory(tt.N, tt.r); got != tt.want {
+			t.Errorf("memory(%d, %d) = %d, want %d", tt.N, tt.r, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package passwap
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zitadel/passwap/verifier"
+)
+
+// SelfTest verifies a fixed password and encoded hash,
+// known as golden vector, with the Verifier of each
+// algorithm in passwap. An error is returned for every
+// vector that does not verify OK, which means that the
+// encoding format or the implementation of the algorithm
+// changed in an incompatible way.
+//
+// The golden vectors use cheap parameters, so SelfTest
+// can run at startup and as a gate in CI.
+// Algorithms which require configuration, like tomcat, saltedhex
+// and iteratedhash, are tested with the configuration documented
+// by the SelfTestVector function of their package.
+// Devise is not tested, as its pepper is secret, and neither
+// is django, which wraps the formats of other algorithms.
+func SelfTest() error {
+	var errs []error
+	for _, a := range algorithms {
+		if a.selfTest == nil {
+			continue
+		}
+		password, encoded := a.selfTest()
+		result, err := a.verifier.Verify(encoded, password)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", a.name, err))
+			continue
+		}
+		if result != verifier.OK {
+			errs = append(errs, fmt.Errorf("%s: result %s", a.name, result))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("passwap: self test: %w", err)
+	}
+	return nil
+}
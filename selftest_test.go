@@ -0,0 +1,34 @@
+package passwap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zitadel/passwap/bcrypt"
+)
+
+func TestSelfTest(t *testing.T) {
+	if err := SelfTest(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSelfTest_broken(t *testing.T) {
+	saved := algorithms
+	t.Cleanup(func() { algorithms = saved })
+
+	algorithms = append(algorithms[:0:0], algorithms...)
+	algorithms = append(algorithms, algorithm{
+		name:     bcrypt.Algorithm,
+		verifier: bcrypt.Verifier,
+		selfTest: func() (string, string) {
+			password, encoded := bcrypt.SelfTestVector()
+			return password + "!", encoded
+		},
+	})
+
+	err := SelfTest()
+	if err == nil || !strings.Contains(err.Error(), bcrypt.Algorithm+": result Fail") {
+		t.Errorf("SelfTest() error = %v, want bcrypt failure", err)
+	}
+}
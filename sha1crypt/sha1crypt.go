@@ -0,0 +1,311 @@
+// Package sha1crypt provides hashing and verification of
+// SHA-1 crypt encoded passwords, as defined by
+// [passlib](https://passlib.readthedocs.io/en/stable/lib/passlib.hash.sha1_crypt.html).
+// The checksum is obtained by iterating HMAC-SHA1 over the
+// salt and rounds, keyed with the password.
+//
+// Note that SHA-1 crypt is a NetBSD specific format which
+// offers no protection against GPU based attacks.
+// This package is only provided for legacy applications
+// that wish to migrate away from SHA-1 crypt to newer hashing methods.
+package sha1crypt
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/zitadel/passwap/internal/encoding"
+	"github.com/zitadel/passwap/internal/salt"
+	"github.com/zitadel/passwap/verifier"
+)
+
+const (
+	Identifier = "sha1"
+	Prefix     = "$" + Identifier + "$"
+
+	// Format of the Modular Crypt Format, as used by passlib.
+	// See https://passlib.readthedocs.io/en/stable/lib/passlib.hash.sha1_crypt.html#format
+	Format = Prefix + "%d$%s$%s"
+
+	// MaxSaltLen is the maximum amount of encoded salt characters.
+	MaxSaltLen = 64
+)
+
+// Params for the SHA-1 crypt Hasher.
+type Params struct {
+	Rounds uint32
+
+	// Length of the salt in encoded characters,
+	// up to MaxSaltLen.
+	SaltLen uint32
+}
+
+// RecommendedParams are based on passlib's defaults.
+var RecommendedParams = Params{
+	Rounds:  480000,
+	SaltLen: 8,
+}
+
+// offsets of the checksum bytes used for encoding.
+var offsets = [...]int{2, 1, 0, 5, 4, 3, 8, 7, 6, 11, 10, 9, 14, 13, 12, 17, 16, 15, 0, 19, 18}
+
+// Progress is called during hashing and verification
+// after every interval of rounds, as set by [WithProgress].
+// done is the amount of rounds completed out of total.
+// Returning an error aborts the computation,
+// for example to enforce a deadline.
+type Progress func(done, total uint32) error
+
+type progress struct {
+	interval uint32
+	fn       Progress
+}
+
+// report calls the Progress function every interval rounds.
+// A nil progress is a no-op.
+func (p *progress) report(done, total uint32) error {
+	if p == nil || p.interval == 0 || p.fn == nil || done%p.interval != 0 {
+		return nil
+	}
+	return p.fn(done, total)
+}
+
+// checksum implements https://passlib.readthedocs.io/en/stable/lib/passlib.hash.sha1_crypt.html#algorithm
+func checksum(password, salt []byte, rounds uint32, p *progress) ([]byte, error) {
+	mac := hmac.New(sha1.New, password)
+	result := []byte(fmt.Sprintf("%s$"+Identifier+"$%d", salt, rounds))
+
+	for i := uint32(0); i < rounds; i++ {
+		mac.Reset()
+		mac.Write(result)
+		result = mac.Sum(result[:0])
+
+		if err := p.report(i+1, rounds); err != nil {
+			return nil, fmt.Errorf("sha1crypt: %w", err)
+		}
+	}
+
+	transposed := make([]byte, len(offsets))
+	for i, o := range offsets {
+		transposed[i] = result[o]
+	}
+	return encoding.EncodeCrypt3(transposed), nil
+}
+
+func hash(r io.Reader, password string, p Params, pr *progress) (string, error) {
+	if p.Rounds < 1 {
+		return "", errors.New("sha1crypt: rounds must be at least 1")
+	}
+	if p.SaltLen < 1 || p.SaltLen > MaxSaltLen {
+		return "", fmt.Errorf("sha1crypt: salt length must be between 1 and %d, got %d", MaxSaltLen, p.SaltLen)
+	}
+	// 3 salt bytes result in 4 encoded characters.
+	salt, err := salt.New(r, (p.SaltLen*3+3)/4)
+	if err != nil {
+		return "", fmt.Errorf("sha1crypt: %w", err)
+	}
+	encSalt := encoding.EncodeCrypt3(salt)[:p.SaltLen]
+
+	checksum, err := checksum([]byte(password), encSalt, p.Rounds, pr)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(Format, p.Rounds, encSalt, checksum), nil
+}
+
+type checker struct {
+	Params
+
+	checksum []byte
+	salt     []byte
+}
+
+func parse(encoded string) (*checker, error) {
+	if !strings.HasPrefix(encoded, Prefix) {
+		return nil, nil
+	}
+
+	// $sha1$rounds$salt$checksum
+	fields := strings.Split(encoded, "$")
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("sha1crypt parse: expected 5 fields, got %d", len(fields))
+	}
+	rounds, err := strconv.ParseUint(fields[2], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("sha1crypt parse rounds: %w", err)
+	}
+	if rounds < 1 {
+		return nil, errors.New("sha1crypt parse: rounds must be at least 1")
+	}
+	if len(fields[3]) > MaxSaltLen {
+		return nil, fmt.Errorf("sha1crypt parse: salt longer than %d characters", MaxSaltLen)
+	}
+
+	c := &checker{
+		Params: Params{
+			Rounds:  uint32(rounds),
+			SaltLen: uint32(len(fields[3])),
+		},
+		salt:     []byte(fields[3]),
+		checksum: []byte(fields[4]),
+	}
+	return c, nil
+}
+
+func (c *checker) verify(password string, p *progress) (verifier.Result, error) {
+	checksum, err := checksum([]byte(password), c.salt, c.Rounds, p)
+	if err != nil {
+		return verifier.Fail, err
+	}
+
+	return verifier.Result(
+		subtle.ConstantTimeCompare(checksum, c.checksum),
+	), nil
+}
+
+// Identify parses encoded and returns its identifier,
+// rounds and salt length.
+func Identify(encoded string) (map[string]any, error) {
+	c, err := parse(encoded)
+	if err != nil || c == nil {
+		return nil, err
+	}
+	return map[string]any{
+		"identifier": Identifier,
+		"rounds":     c.Rounds,
+		"salt_len":   c.SaltLen,
+	}, nil
+}
+
+// Verify parses encoded and verifies password against the checksum.
+func Verify(encoded, password string) (verifier.Result, error) {
+	c, err := parse(encoded)
+	if err != nil || c == nil {
+		return verifier.Skip, err
+	}
+
+	return c.verify(password, nil)
+}
+
+// Hasher provides a SHA-1 crypt hasher.
+// SHA-1 crypt should not be used in new applications.
+// It is only provided for legacy applications that really
+// depend on it.
+type Hasher struct {
+	p Params
+	// rand is shared by concurrent calls to Hash and must be
+	// safe for concurrent use, such as crypto/rand.Reader.
+	rand     io.Reader
+	progress *progress
+}
+
+// Option configures optional behavior of a Hasher.
+type Option func(*Hasher)
+
+// WithInsecureRand sets the source of salts to r, instead of
+// crypto/rand.Reader, so that the Hasher produces reproducible
+// hashes for known-answer test vectors, see package vectors.
+// Hashes with predictable salts must never be stored,
+// hence the name. This is the only way to replace the
+// source of salts, Hashers otherwise always use crypto/rand.Reader.
+// r is shared by concurrent calls to Hash.
+func WithInsecureRand(r io.Reader) Option {
+	return func(h *Hasher) {
+		h.rand = r
+	}
+}
+
+// WithProgress calls fn after every interval of rounds
+// during hashing and verification by the Hasher.
+// This allows showing progress for high round counts,
+// or aborting when fn returns an error.
+// An interval of 0 or a nil fn disables progress reporting.
+func WithProgress(interval uint32, fn Progress) Option {
+	return func(h *Hasher) {
+		h.progress = &progress{
+			interval: interval,
+			fn:       fn,
+		}
+	}
+}
+
+// New returns a SHA-1 crypt Hasher with p.
+func New(p Params, opts ...Option) *Hasher {
+	h := &Hasher{
+		p:    p,
+		rand: rand.Reader,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Hash implements passwap.Hasher.
+func (h *Hasher) Hash(password string) (string, error) {
+	return hash(h.rand, password, h.p, h.progress)
+}
+
+// Verify implements passwap.Verifier.
+// NeedUpdate is returned when the rounds or salt length
+// of encoded differ from the Hasher's parameters.
+func (h *Hasher) Verify(encoded, password string) (verifier.Result, error) {
+	c, err := parse(encoded)
+	if err != nil || c == nil {
+		return verifier.Skip, err
+	}
+
+	res, err := c.verify(password, h.progress)
+	if err != nil {
+		return res, err
+	}
+	if res == verifier.OK && c.Params != h.p {
+		return verifier.NeedUpdate, nil
+	}
+	return res, nil
+}
+
+// Identify implements verifier.Identifier.
+func (h *Hasher) Identify(encoded string) (map[string]any, error) {
+	return Identify(encoded)
+}
+
+// Algorithm implements verifier.Named.
+func (h *Hasher) Algorithm() string {
+	return Algorithm
+}
+
+// HashPrefixes implements verifier.Prefixed.
+func (h *Hasher) HashPrefixes() []string {
+	return prefixes
+}
+
+// Algorithm is the name reported by the Verifier and Hasher,
+// see verifier.Named.
+const Algorithm = "sha1crypt"
+
+// prefixes recognized by the Verifier and Hasher.
+var prefixes = []string{Prefix}
+
+// Verifier for SHA-1 crypt.
+var Verifier = &verifier.NamedFunc{Name: Algorithm, VerifyFunc: Verify, Prefixes: prefixes}
+
+// Prefixes returns the prefixes of the hashes handled by this package,
+// as recognized by the Verifier, see verifier.Prefixed.
+func Prefixes() []string {
+	return append([]string(nil), prefixes...)
+}
+
+// SelfTestVector returns a fixed password and its encoded hash,
+// which [Verify] must accept. It guards against regressions
+// of the encoding format, see passwap.SelfTest.
+func SelfTestVector() (password, encoded string) {
+	return "password", `$sha1$19703$iVdJqfSE$v4qYKl1zqYThwpjJAoKX6UvlHq/a`
+}
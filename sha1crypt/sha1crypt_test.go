@@ -0,0 +1,344 @@
+package sha1crypt
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zitadel/passwap/internal/salt"
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/verifier"
+)
+
+func Test_checksum(t *testing.T) {
+	got, err := checksum([]byte(tv.Password), []byte(tv.Sha1CryptSalt), tv.Sha1CryptRounds, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := tv.Sha1CryptEncoded[strings.LastIndexByte(tv.Sha1CryptEncoded, '$')+1:]
+
+	if string(got) != want {
+		t.Errorf("checksum() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func Test_hash(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       Params
+		wantErr bool
+	}{
+		{
+			name:    "salt error",
+			p:       Params{Rounds: 10, SaltLen: 8},
+			wantErr: true,
+		},
+		{
+			name:    "rounds zero",
+			p:       Params{Rounds: 0, SaltLen: 8},
+			wantErr: true,
+		},
+		{
+			name:    "salt length zero",
+			p:       Params{Rounds: 10, SaltLen: 0},
+			wantErr: true,
+		},
+		{
+			name:    "salt length too long",
+			p:       Params{Rounds: 10, SaltLen: MaxSaltLen + 1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := hash(salt.ErrReader{}, tv.Password, tt.p, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("hash() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_hash_entropy(t *testing.T) {
+	readers := map[string]func() io.Reader{
+		"error":      func() io.Reader { return salt.ErrReader{} },
+		"short read": func() io.Reader { return strings.NewReader("short") },
+	}
+	for name, r := range readers {
+		t.Run(name, func(t *testing.T) {
+			got, err := hash(r(), tv.Password, Params{Rounds: 10, SaltLen: 8}, nil)
+			if !errors.Is(err, salt.ErrEntropy) {
+				t.Errorf("hash() error = %v, want %v", err, salt.ErrEntropy)
+			}
+			if got != "" {
+				t.Errorf("hash() = %s, want empty", got)
+			}
+		})
+	}
+}
+
+func Test_parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		want    *checker
+		wantErr bool
+	}{
+		{
+			name:    "not sha1",
+			encoded: tv.MD5Encoded,
+		},
+		{
+			name:    "fields error",
+			encoded: "$sha1$foo",
+			wantErr: true,
+		},
+		{
+			name:    "rounds error",
+			encoded: strings.Replace(tv.Sha1CryptEncoded, "19703", "foo", 1),
+			wantErr: true,
+		},
+		{
+			name:    "rounds zero",
+			encoded: strings.Replace(tv.Sha1CryptEncoded, "19703", "0", 1),
+			wantErr: true,
+		},
+		{
+			name:    "salt too long",
+			encoded: strings.Replace(tv.Sha1CryptEncoded, tv.Sha1CryptSalt, strings.Repeat("a", MaxSaltLen+1), 1),
+			wantErr: true,
+		},
+		{
+			name:    "success",
+			encoded: tv.Sha1CryptEncoded,
+			want: &checker{
+				Params: Params{
+					Rounds:  tv.Sha1CryptRounds,
+					SaltLen: uint32(len(tv.Sha1CryptSalt)),
+				},
+				salt:     []byte(tv.Sha1CryptSalt),
+				checksum: []byte("v4qYKl1zqYThwpjJAoKX6UvlHq/a"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parse(tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasher_Hash_zeroRounds(t *testing.T) {
+	got, err := New(Params{Rounds: 0, SaltLen: 8}).Hash(tv.Password)
+	if err == nil || got != "" {
+		t.Errorf("Hasher.Hash() = %q, %v, want error", got, err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoded  string
+		password string
+		want     verifier.Result
+		wantErr  bool
+	}{
+		{
+			name:     "parse error",
+			encoded:  "$sha1$foo",
+			password: tv.Password,
+			want:     verifier.Skip,
+			wantErr:  true,
+		},
+		{
+			name:     "rounds zero",
+			encoded:  "$sha1$0$ab$xxxxxxxxxxxxxxxxxxxxxxxxxxxx",
+			password: tv.Password,
+			want:     verifier.Skip,
+			wantErr:  true,
+		},
+		{
+			name:     "wrong prefix",
+			encoded:  tv.MD5Encoded,
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "wrong password",
+			encoded:  tv.Sha1CryptEncoded,
+			password: "foobar",
+			want:     verifier.Fail,
+		},
+		{
+			name:     "success",
+			encoded:  tv.Sha1CryptEncoded,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "success 2",
+			encoded:  tv.Sha1CryptEncoded2,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "unicode password",
+			encoded:  tv.Sha1CryptEncodedUnicode,
+			password: tv.Sha1CryptUnicodePass,
+			want:     verifier.OK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Verify(tt.encoded, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasher(t *testing.T) {
+	h := New(Params{Rounds: 100, SaltLen: 8})
+
+	encoded, err := h.Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encoded, "$sha1$100$") || len(encoded) != len("$sha1$100$")+8+1+28 {
+		t.Errorf("Hasher.Hash() = %s, unexpected format", encoded)
+	}
+
+	tests := []struct {
+		name     string
+		encoded  string
+		password string
+		want     verifier.Result
+	}{
+		{"ok", encoded, tv.Password, verifier.OK},
+		{"fail", encoded, "foobar", verifier.Fail},
+		{"need update", tv.Sha1CryptEncoded, tv.Password, verifier.NeedUpdate},
+		{"skip", tv.MD5Encoded, tv.Password, verifier.Skip},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := h.Verify(tt.encoded, tt.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Hasher.Verify() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	got, err := New(RecommendedParams).Identify(tv.Sha1CryptEncoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"identifier": Identifier,
+		"rounds":     uint32(tv.Sha1CryptRounds),
+		"salt_len":   uint32(len(tv.Sha1CryptSalt)),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Identify() = %v, want %v", got, want)
+	}
+}
+
+func TestWithProgress(t *testing.T) {
+	var calls []uint32
+	h := New(Params{Rounds: 100, SaltLen: 8}, WithProgress(10, func(done, total uint32) error {
+		if total != 100 {
+			t.Errorf("Progress total = %d, want 100", total)
+		}
+		calls = append(calls, done)
+		return nil
+	}))
+
+	encoded, err := h.Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []uint32{10, 20, 30, 40, 50, 60, 70, 80, 90, 100}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("Progress calls = %v, want %v", calls, want)
+	}
+
+	calls = nil
+	if _, err = h.Verify(encoded, tv.Password); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("Progress calls = %v, want %v", calls, want)
+	}
+
+	errAbort := errors.New("abort")
+	h = New(Params{Rounds: 100, SaltLen: 8}, WithProgress(10, func(done, total uint32) error {
+		if done == 50 {
+			return errAbort
+		}
+		return nil
+	}))
+	if _, err = h.Hash(tv.Password); !errors.Is(err, errAbort) {
+		t.Errorf("Hasher.Hash() error = %v, want %v", err, errAbort)
+	}
+	res, err := h.Verify(encoded, tv.Password)
+	if res != verifier.Fail || !errors.Is(err, errAbort) {
+		t.Errorf("Hasher.Verify() = %s, %v, want %s, %v", res, err, verifier.Fail, errAbort)
+	}
+
+	h = New(Params{Rounds: 100, SaltLen: 8}, WithProgress(0, func(done, total uint32) error {
+		t.Errorf("Progress called with interval 0")
+		return nil
+	}))
+	if _, err = h.Hash(tv.Password); err != nil {
+		t.Fatal(err)
+	}
+	h = New(Params{Rounds: 100, SaltLen: 8}, WithProgress(10, nil))
+	if _, err = h.Hash(tv.Password); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithInsecureRand(t *testing.T) {
+	newTestHasher := func(opts ...Option) *Hasher { return New(Params{Rounds: 100, SaltLen: 8}, opts...) }
+
+	if err := salt.AssertCryptoRand(newTestHasher().rand); err != nil {
+		t.Errorf("default Hasher: %v", err)
+	}
+
+	newHasher := func() *Hasher {
+		return newTestHasher(WithInsecureRand(strings.NewReader(strings.Repeat(tv.Salt, 4))))
+	}
+	h := newHasher()
+	if err := salt.AssertCryptoRand(h.rand); !errors.Is(err, salt.ErrInsecureRand) {
+		t.Errorf("AssertCryptoRand() = %v, want %v", err, salt.ErrInsecureRand)
+	}
+	first, err := h.Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := newHasher().Hash(tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Errorf("Hasher.Hash() = %s, then %s, want identical", first, second)
+	}
+}
@@ -0,0 +1,211 @@
+// Package tomcat provides verification of credentials stored
+// by the credential handlers of Apache Tomcat realms.
+// See https://tomcat.apache.org/tomcat-10.1-doc/config/credentialhandler.html.
+//
+// Both the MessageDigestCredentialHandler and SecretKeyCredentialHandler
+// store credentials as `salt$iterations$digest`, with hex encoded salt
+// and digest. The digest algorithm is not part of the stored value
+// and must be configured with [NewMessageDigest] or [NewSecretKey].
+//
+// The MessageDigestCredentialHandler also accepts hex digests without
+// salt and the `{MD5}`, `{SHA}` and `{SSHA}` base64 formats,
+// which are verified by the dovecot package.
+//
+// Many of these formats are cryptographically weak.
+// This package is only provided to allow migration of
+// Java applications to better methods.
+package tomcat
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"hash"
+	"strconv"
+	"strings"
+
+	"github.com/zitadel/passwap/dovecot"
+	"github.com/zitadel/passwap/verifier"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Algorithm is the name reported by the Verifier,
+// see verifier.Named.
+const Algorithm = "tomcat"
+
+// DefaultMaxIterations bounds the iterations of credentials,
+// unless set by [WithMaxIterations].
+const DefaultMaxIterations = 1_000_000
+
+// Verifier for Tomcat credentials.
+type Verifier struct {
+	hf func() hash.Hash
+	// secretKey is set for the SecretKeyCredentialHandler.
+	secretKey     bool
+	maxIterations int
+}
+
+// Option configures optional behavior of a Verifier.
+type Option func(*Verifier)
+
+// WithMaxIterations bounds the iterations of credentials to max,
+// instead of DefaultMaxIterations, so that crafted credentials
+// can't exhaust the CPU.
+func WithMaxIterations(max int) Option {
+	return func(v *Verifier) {
+		v.maxIterations = max
+	}
+}
+
+func newVerifier(hf func() hash.Hash, secretKey bool, opts []Option) *Verifier {
+	v := &Verifier{
+		hf:            hf,
+		secretKey:     secretKey,
+		maxIterations: DefaultMaxIterations,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// NewMessageDigest returns a Verifier for credentials stored by the
+// MessageDigestCredentialHandler, using hf as digest algorithm.
+// For example sha512.New for the `SHA-512` algorithm.
+func NewMessageDigest(hf func() hash.Hash, opts ...Option) *Verifier {
+	return newVerifier(hf, false, opts)
+}
+
+// NewSecretKey returns a Verifier for credentials stored by the
+// SecretKeyCredentialHandler, using PBKDF2 with the HMAC of hf.
+// For example sha512.New for the `PBKDF2WithHmacSHA512` algorithm.
+// The key length is taken from the stored credential.
+func NewSecretKey(hf func() hash.Hash, opts ...Option) *Verifier {
+	return newVerifier(hf, true, opts)
+}
+
+// Algorithm implements verifier.Named.
+func (v *Verifier) Algorithm() string {
+	return Algorithm
+}
+
+// Prefixes returns nil, as Tomcat credentials have no prefix,
+// see verifier.Prefixed.
+func Prefixes() []string {
+	return nil
+}
+
+// Verify implements verifier.Verifier.
+// As Tomcat credentials don't have an identifier, Skip is returned
+// without an error for any encoded string which can't be parsed.
+// Skip and a [verifier.BoundsError] are returned without derivation
+// for credentials with more iterations than allowed, see [WithMaxIterations].
+func (v *Verifier) Verify(encoded, password string) (verifier.Result, error) {
+	if !v.secretKey && strings.HasPrefix(encoded, "{") {
+		return verifyPrefixed(encoded, password)
+	}
+	c, ok := parse(encoded)
+	if !ok {
+		return verifier.Skip, nil
+	}
+	if err := verifier.CheckBounds(Algorithm, "iterations", int64(c.iterations), 0, int64(v.maxIterations)); err != nil {
+		return verifier.Skip, err
+	}
+
+	var derived []byte
+	if v.secretKey {
+		// The SecretKeyCredentialHandler only stores salted credentials.
+		if c.unsalted {
+			return verifier.Skip, nil
+		}
+		derived = pbkdf2.Key([]byte(password), c.salt, c.iterations, len(c.digest), v.hf)
+	} else {
+		derived = v.digest(c.salt, c.iterations, []byte(password))
+	}
+	if len(derived) != len(c.digest) {
+		return verifier.Skip, nil
+	}
+	return verifier.Result(subtle.ConstantTimeCompare(derived, c.digest)), nil
+}
+
+// digest applies the digest algorithm iterations times, as done by
+// Tomcat's ConcurrentMessageDigest. The first iteration digests
+// salt followed by password, every next iteration the previous digest.
+func (v *Verifier) digest(salt []byte, iterations int, password []byte) []byte {
+	h := v.hf()
+	h.Write(salt)
+	h.Write(password)
+	sum := h.Sum(nil)
+
+	for i := 1; i < iterations; i++ {
+		h.Reset()
+		h.Write(sum)
+		sum = h.Sum(sum[:0])
+	}
+	return sum
+}
+
+type credential struct {
+	salt       []byte
+	iterations int
+	digest     []byte
+	// unsalted is set for a plain hex digest without separators.
+	unsalted bool
+}
+
+// parse encoded as `salt$iterations$digest` or a plain hex digest.
+// Hex is decoded case-insensitively and the salt may be empty.
+func parse(encoded string) (c credential, ok bool) {
+	fields := strings.Split(encoded, "$")
+	switch len(fields) {
+	case 1:
+		c.iterations = 1
+		c.unsalted = true
+	case 3:
+		var err error
+		if c.salt, err = hex.DecodeString(fields[0]); err != nil {
+			return credential{}, false
+		}
+		if c.iterations, err = strconv.Atoi(fields[1]); err != nil || c.iterations < 1 {
+			return credential{}, false
+		}
+	default:
+		return credential{}, false
+	}
+
+	digest, err := hex.DecodeString(fields[len(fields)-1])
+	if err != nil || len(digest) == 0 {
+		return credential{}, false
+	}
+	c.digest = digest
+	return c, true
+}
+
+// prefixed maps the base64 formats supported by the
+// MessageDigestCredentialHandler to dovecot schemes.
+var prefixed = map[string]string{
+	"{MD5}":  "{LDAP-MD5}",
+	"{SHA}":  "{SHA}",
+	"{SSHA}": "{SSHA}",
+}
+
+// verifyPrefixed verifies the `{MD5}`, `{SHA}` and `{SSHA}` formats,
+// using the dovecot package. Skip is returned for other prefixes.
+func verifyPrefixed(encoded, password string) (verifier.Result, error) {
+	prefix, value, found := strings.Cut(encoded, "}")
+	if !found {
+		return verifier.Skip, nil
+	}
+	scheme, ok := prefixed[prefix+"}"]
+	if !ok {
+		return verifier.Skip, nil
+	}
+	return dovecot.Verify(scheme+value, password)
+}
+
+// SelfTestVector returns a fixed password and its salted
+// SHA-512 credential, which the Verifier of
+// NewMessageDigest(sha512.New) must accept. It guards against
+// regressions of the encoding format, see passwap.SelfTest.
+func SelfTestVector() (password, encoded string) {
+	return "password", `72616e646f6d73616c74697368617264$1000$382a04a37af9964b6479daefa254fc87d9d7668c1396b8a522132677a1ea7b61d5ff5d710fe60cb6614e263df598c72265486ccd11d208bb2cee4dd1042ab708`
+}
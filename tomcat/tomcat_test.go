@@ -0,0 +1,217 @@
+package tomcat
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"strings"
+	"testing"
+
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/verifier"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	sha512Digest := NewMessageDigest(sha512.New)
+	sha256Digest := NewMessageDigest(sha256.New)
+	secretKey := NewSecretKey(sha512.New)
+
+	tests := []struct {
+		name     string
+		v        *Verifier
+		encoded  string
+		password string
+		want     verifier.Result
+		wantErr  bool
+	}{
+		{
+			name:     "salted digest",
+			v:        sha512Digest,
+			encoded:  tv.TomcatSHA512Salted,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "salted digest wrong password",
+			v:        sha512Digest,
+			encoded:  tv.TomcatSHA512Salted,
+			password: "foobar",
+			want:     verifier.Fail,
+		},
+		{
+			name:     "salted digest other algorithm",
+			v:        sha256Digest,
+			encoded:  tv.TomcatSHA512Salted,
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "salted digest wrong iterations",
+			v:        sha512Digest,
+			encoded:  strings.Replace(tv.TomcatSHA512Salted, "$1000$", "$999$", 1),
+			password: tv.Password,
+			want:     verifier.Fail,
+		},
+		{
+			name:     "hex digest",
+			v:        sha256Digest,
+			encoded:  tv.TomcatSHA256Hex,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "hex digest lower case",
+			v:        sha256Digest,
+			encoded:  strings.ToLower(tv.TomcatSHA256Hex),
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "hex digest wrong password",
+			v:        sha256Digest,
+			encoded:  tv.TomcatSHA256Hex,
+			password: "foobar",
+			want:     verifier.Fail,
+		},
+		{
+			name:     "secret key",
+			v:        secretKey,
+			encoded:  tv.TomcatPBKDF2SHA512,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "secret key wrong password",
+			v:        secretKey,
+			encoded:  tv.TomcatPBKDF2SHA512,
+			password: "foobar",
+			want:     verifier.Fail,
+		},
+		{
+			name:     "secret key hex digest",
+			v:        NewSecretKey(sha256.New),
+			encoded:  tv.TomcatSHA256Hex,
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "secret key prefixed",
+			v:        secretKey,
+			encoded:  tv.TomcatSHA,
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "md5",
+			v:        sha512Digest,
+			encoded:  tv.TomcatMD5,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "sha",
+			v:        sha512Digest,
+			encoded:  tv.TomcatSHA,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "ssha",
+			v:        sha512Digest,
+			encoded:  tv.TomcatSSHA,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "ssha wrong password",
+			v:        sha512Digest,
+			encoded:  tv.TomcatSSHA,
+			password: "foobar",
+			want:     verifier.Fail,
+		},
+		{
+			name:     "prefixed base64 error",
+			v:        sha512Digest,
+			encoded:  "{SHA}!!!",
+			password: tv.Password,
+			want:     verifier.Skip,
+			wantErr:  true,
+		},
+		{
+			name:     "unknown prefix",
+			v:        sha512Digest,
+			encoded:  "{SSHA512}foo",
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "md5 crypt",
+			v:        sha512Digest,
+			encoded:  tv.MD5Encoded,
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "bcrypt",
+			v:        sha512Digest,
+			encoded:  tv.EncodedBcrypt2b,
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "iterations zero",
+			v:        sha512Digest,
+			encoded:  strings.Replace(tv.TomcatSHA512Salted, "$1000$", "$0$", 1),
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "not hex",
+			v:        sha512Digest,
+			encoded:  "foobar",
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "iterations above default",
+			v:        sha512Digest,
+			encoded:  strings.Replace(tv.TomcatSHA512Salted, "$1000$", "$2147483647$", 1),
+			password: tv.Password,
+			want:     verifier.Skip,
+			wantErr:  true,
+		},
+		{
+			name:     "secret key iterations above default",
+			v:        secretKey,
+			encoded:  strings.Replace(tv.TomcatPBKDF2SHA512, "$1000$", "$2147483647$", 1),
+			password: tv.Password,
+			want:     verifier.Skip,
+			wantErr:  true,
+		},
+		{
+			name:     "iterations above max",
+			v:        NewMessageDigest(sha512.New, WithMaxIterations(999)),
+			encoded:  tv.TomcatSHA512Salted,
+			password: tv.Password,
+			want:     verifier.Skip,
+			wantErr:  true,
+		},
+		{
+			name:     "iterations at max",
+			v:        NewSecretKey(sha512.New, WithMaxIterations(1000)),
+			encoded:  tv.TomcatPBKDF2SHA512,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.v.Verify(tt.encoded, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+#!/usr/bin/env python3
+#
+# Generates or extends passlib_argon2.json with hashes produced
+# by passlib, which passwap must verify.
+# The checked in vectors are taken from the passlib documentation
+# and the argon2 test vectors used by the passlib test suite.
+# Requires passlib and argon2-cffi.
+
+import json
+
+from passlib.hash import argon2
+
+password = "password"
+vectors = []
+
+for type in ["i", "id"]:
+    for settings in [dict(memory_cost=256, rounds=2, parallelism=1), dict(memory_cost=65536, rounds=2, parallelism=4)]:
+        encoded = argon2.using(type=type, salt=b"somesalt", **settings).hash(password)
+        vectors.append({"password": password, "encoded": encoded})
+
+print(json.dumps(vectors, indent=2))
@@ -0,0 +1,98 @@
+// Package vectors generates and checks known-answer test vectors
+// for passwap Hashers and Verifiers.
+//
+// Vectors document the expected output of an algorithm and
+// guard against accidental changes of encoding formats across versions.
+// Typically they are generated once, stored as golden data and
+// checked in CI against newer versions of passwap or other
+// implementations of the same algorithms.
+//
+// Hashers obtain random salts, so generated vectors are only
+// reproducible when the Hasher reads its salts from a fixed seed.
+// The Hashers of the argon2, bcrypt, md5, pbkdf2, scrypt and sha1crypt
+// packages accept a WithInsecureRand option for this purpose:
+//
+//	h := bcrypt.New(bcrypt.MinCost, bcrypt.WithInsecureRand(vectors.NewSeedReader(seed)))
+//	vecs, err := vectors.Generate(h, passwords...)
+//
+// Such Hashers must only be used to generate vectors.
+package vectors
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/zitadel/passwap"
+	"github.com/zitadel/passwap/verifier"
+)
+
+// Vector is a known-answer pair of a password
+// and its encoded hash.
+type Vector struct {
+	Password string `json:"password"`
+	Encoded  string `json:"encoded"`
+}
+
+// seedReader repeats a seed endlessly.
+type seedReader struct {
+	mu   sync.Mutex
+	seed []byte
+	pos  int
+}
+
+// NewSeedReader returns a reader which repeats seed endlessly,
+// as a deterministic salt source for Hashers generating vectors.
+// It is safe for concurrent use, but concurrent reads make the
+// order of the salts, and therefore the vectors, unpredictable.
+// seed must not be empty.
+func NewSeedReader(seed []byte) io.Reader {
+	if len(seed) == 0 {
+		panic("vectors: empty seed")
+	}
+	return &seedReader{seed: append([]byte(nil), seed...)}
+}
+
+func (r *seedReader) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range p {
+		p[i] = r.seed[r.pos%len(r.seed)]
+		r.pos++
+	}
+	return len(p), nil
+}
+
+// Generate a Vector for each password using h.
+// The vectors are only reproducible when h reads its salts
+// from a fixed seed, see [NewSeedReader].
+func Generate(h passwap.Hasher, passwords ...string) ([]Vector, error) {
+	vectors := make([]Vector, len(passwords))
+	for i, pw := range passwords {
+		encoded, err := h.Hash(pw)
+		if err != nil {
+			return nil, fmt.Errorf("vectors: generate %d: %w", i, err)
+		}
+		vectors[i] = Vector{
+			Password: pw,
+			Encoded:  encoded,
+		}
+	}
+	return vectors, nil
+}
+
+// Check all vectors using v.
+// An error is returned for the first vector that
+// does not verify with an OK result.
+func Check(v verifier.Verifier, vectors []Vector) error {
+	for i, vec := range vectors {
+		res, err := v.Verify(vec.Encoded, vec.Password)
+		if err != nil {
+			return fmt.Errorf("vectors: check %d %q: %w", i, vec.Encoded, err)
+		}
+		if res != verifier.OK {
+			return fmt.Errorf("vectors: check %d %q: result %s", i, vec.Encoded, res)
+		}
+	}
+	return nil
+}
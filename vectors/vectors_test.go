@@ -0,0 +1,233 @@
+package vectors
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/zitadel/passwap"
+	"github.com/zitadel/passwap/argon2"
+	"github.com/zitadel/passwap/bcrypt"
+	"github.com/zitadel/passwap/internal/salt"
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/md5"
+	"github.com/zitadel/passwap/pbkdf2"
+	"github.com/zitadel/passwap/scrypt"
+	"github.com/zitadel/passwap/sha1crypt"
+	"github.com/zitadel/passwap/verifier"
+)
+
+// seed returns a new salt source which repeats tv.Salt.
+func seed() io.Reader {
+	return NewSeedReader([]byte(tv.Salt))
+}
+
+var passwords = []string{tv.Password, "", "pässwörd", "correct horse battery staple"}
+
+// builtinHashers returns Hashers which read their salts from a new seed.
+func builtinHashers() []struct {
+	name string
+	h    passwap.Hasher
+	want string
+} {
+	md5Hasher, err := md5.New(md5.DefaultSaltLen, md5.WithInsecureRand(NewSeedReader([]byte(tv.MD5SaltRaw))))
+	if err != nil {
+		panic(err)
+	}
+	return []struct {
+		name string
+		h    passwap.Hasher
+		want string
+	}{
+		{
+			name: "argon2id",
+			h: argon2.NewArgon2id(argon2.Params{
+				Time:    tv.Argon2Time,
+				Memory:  tv.Argon2Memory,
+				Threads: tv.Argon2Threads,
+				KeyLen:  tv.KeyLen,
+				SaltLen: tv.SaltLen,
+			}, argon2.WithInsecureRand(seed())),
+			want: tv.Argon2idEncoded,
+		},
+		{
+			name: "argon2i",
+			h: argon2.NewArgon2i(argon2.Params{
+				Time:    tv.Argon2Time,
+				Memory:  tv.Argon2Memory,
+				Threads: tv.Argon2Threads,
+				KeyLen:  tv.KeyLen,
+				SaltLen: tv.SaltLen,
+			}, argon2.WithInsecureRand(seed())),
+			want: tv.Argon2iEncoded,
+		},
+		{
+			name: "bcrypt",
+			h:    bcrypt.New(bcrypt.MinCost, bcrypt.WithInsecureRand(seed())),
+		},
+		{
+			name: "md5",
+			h:    md5Hasher,
+			want: tv.MD5Encoded,
+		},
+		{
+			name: "pbkdf2",
+			h: pbkdf2.NewSHA256(pbkdf2.Params{
+				Rounds:  tv.Pbkdf2Rounds,
+				KeyLen:  tv.Pbkdf2Sha256KeyLen,
+				SaltLen: tv.SaltLen,
+			}, pbkdf2.WithInsecureRand(seed())),
+			want: tv.Pbkdf2Sha256Encoded,
+		},
+		{
+			name: "scrypt",
+			h: scrypt.New(scrypt.Params{
+				N:       tv.ScryptN,
+				R:       tv.ScryptR,
+				P:       tv.ScryptP,
+				KeyLen:  tv.KeyLen,
+				SaltLen: tv.SaltLen,
+			}, scrypt.WithInsecureRand(seed())),
+			want: tv.ScryptEncoded,
+		},
+		{
+			name: "sha1crypt",
+			h:    sha1crypt.New(sha1crypt.Params{Rounds: 1000, SaltLen: 8}, sha1crypt.WithInsecureRand(seed())),
+		},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	for _, tt := range builtinHashers() {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Generate(tt.h, passwords...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(passwords) {
+				t.Fatalf("Generate() returned %d vectors, want %d", len(got), len(passwords))
+			}
+			if tt.want != "" && got[0].Encoded != tt.want {
+				t.Errorf("Generate() =\n%s\nwant\n%s", got[0].Encoded, tt.want)
+			}
+			if err = Check(tt.h, got); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestGenerate_reproducible(t *testing.T) {
+	first, second := builtinHashers(), builtinHashers()
+	for i := range first {
+		t.Run(first[i].name, func(t *testing.T) {
+			var results [2][]Vector
+			for j, h := range []passwap.Hasher{first[i].h, second[i].h} {
+				var err error
+				if results[j], err = Generate(h, passwords...); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !reflect.DeepEqual(results[0], results[1]) {
+				t.Errorf("Generate() not reproducible:\n%v\n%v", results[0], results[1])
+			}
+		})
+	}
+}
+
+func TestGenerate_error(t *testing.T) {
+	h := bcrypt.New(bcrypt.MinCost, bcrypt.WithInsecureRand(salt.ErrReader{}))
+	if _, err := Generate(h, passwords...); err == nil {
+		t.Error("Generate() did not return error")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       verifier.Verifier
+		vectors []Vector
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			v:    argon2.Verifier,
+			vectors: []Vector{
+				{tv.Password, tv.Argon2idEncoded},
+				{tv.Password, tv.Argon2iEncoded},
+			},
+		},
+		{
+			name: "wrong password",
+			v:    argon2.Verifier,
+			vectors: []Vector{
+				{tv.Password, tv.Argon2idEncoded},
+				{"foobar", tv.Argon2iEncoded},
+			},
+			wantErr: true,
+		},
+		{
+			name: "verifier error",
+			v:    argon2.Verifier,
+			vectors: []Vector{
+				{tv.Password, tv.Argon2dEncoded},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Check(tt.v, tt.vectors); (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// passlibArgon2Hash matches the argon2 hashes accepted by passlib,
+// adapted from passlib.handlers.argon2._hash_regex.
+// passlib does not support the keyid and data parameters,
+// even though they are recognized.
+var passlibArgon2Hash = regexp.MustCompile(`^\$argon2(i|id)\$v=19\$m=\d+,t=\d+,p=\d+\$[A-Za-z0-9+/]+\$[A-Za-z0-9+/]+$`)
+
+func TestCheck_passlibArgon2(t *testing.T) {
+	data, err := os.ReadFile("testdata/passlib_argon2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors []Vector
+	if err = json.Unmarshal(data, &vectors); err != nil {
+		t.Fatal(err)
+	}
+	if err = Check(argon2.Verifier, vectors); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGenerate_passlibArgon2(t *testing.T) {
+	params := argon2.Params{
+		Time:    tv.Argon2Time,
+		Memory:  tv.Argon2Memory,
+		Threads: tv.Argon2Threads,
+		KeyLen:  tv.KeyLen,
+		SaltLen: tv.SaltLen,
+	}
+	for _, h := range []passwap.Hasher{
+		argon2.NewArgon2i(params),
+		argon2.NewArgon2id(params),
+		argon2.NewArgon2id(argon2.RecommendedIDParams),
+	} {
+		vectors, err := Generate(h, passwords...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, vec := range vectors {
+			if !passlibArgon2Hash.MatchString(vec.Encoded) {
+				t.Errorf("Generate() = %s, not accepted by passlib", vec.Encoded)
+			}
+		}
+	}
+}
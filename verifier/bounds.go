@@ -0,0 +1,77 @@
+package verifier
+
+import "fmt"
+
+// BoundsError is returned by Validators when a
+// parameter of an encoded hash is out of bounds.
+// A Min or Max of 0 means the parameter is not
+// bounded in that direction.
+type BoundsError struct {
+	// Algorithm of the encoded hash, including the
+	// variant when it has several, like "argon2id".
+	Algorithm string
+	// Param is the name of the parameter, like "m".
+	Param string
+
+	Value int64
+	Min   int64
+	Max   int64
+}
+
+func (e *BoundsError) Error() string {
+	switch {
+	case e.Min != 0 && e.Value < e.Min:
+		return fmt.Sprintf("%s: parameter %s=%d below minimum %d", e.Algorithm, e.Param, e.Value, e.Min)
+	case e.Max != 0 && e.Value > e.Max:
+		return fmt.Sprintf("%s: parameter %s=%d above maximum %d", e.Algorithm, e.Param, e.Value, e.Max)
+	default:
+		return fmt.Sprintf("%s: parameter %s=%d out of bounds", e.Algorithm, e.Param, e.Value)
+	}
+}
+
+// CheckBounds returns a BoundsError when value is
+// below min or above max. A min or max of 0
+// is not checked.
+func CheckBounds(algorithm, param string, value, min, max int64) error {
+	if (min != 0 && value < min) || (max != 0 && value > max) {
+		return &BoundsError{
+			Algorithm: algorithm,
+			Param:     param,
+			Value:     value,
+			Min:       min,
+			Max:       max,
+		}
+	}
+	return nil
+}
+
+// DisallowedError is returned by Validators when a
+// parameter of an encoded hash has a value which is
+// explicitly disallowed, even though it may be within bounds.
+type DisallowedError struct {
+	// Algorithm of the encoded hash, like "bcrypt".
+	Algorithm string
+	// Param is the name of the parameter, like "cost".
+	Param string
+
+	Value int64
+}
+
+func (e *DisallowedError) Error() string {
+	return fmt.Sprintf("%s: parameter %s=%d is disallowed", e.Algorithm, e.Param, e.Value)
+}
+
+// CheckDisallowed returns a DisallowedError
+// when value is one of disallowed.
+func CheckDisallowed(algorithm, param string, value int64, disallowed ...int64) error {
+	for _, d := range disallowed {
+		if value == d {
+			return &DisallowedError{
+				Algorithm: algorithm,
+				Param:     param,
+				Value:     value,
+			}
+		}
+	}
+	return nil
+}
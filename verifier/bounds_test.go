@@ -0,0 +1,60 @@
+package verifier
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckBounds(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   int64
+		min     int64
+		max     int64
+		wantErr string
+	}{
+		{"unbounded", 1, 0, 0, ""},
+		{"within", 5, 1, 10, ""},
+		{"at min", 1, 1, 10, ""},
+		{"at max", 10, 1, 10, ""},
+		{"below", 0, 1, 10, "argon2: parameter m=0 below minimum 1"},
+		{"above", 11, 1, 10, "argon2: parameter m=11 above maximum 10"},
+		{"above without min", 11, 0, 10, "argon2: parameter m=11 above maximum 10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckBounds("argon2", "m", tt.value, tt.min, tt.max)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("CheckBounds() error = %v", err)
+				}
+				return
+			}
+			var target *BoundsError
+			if !errors.As(err, &target) {
+				t.Fatalf("CheckBounds() error = %v, want BoundsError", err)
+			}
+			if err.Error() != tt.wantErr {
+				t.Errorf("CheckBounds() error = %q, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckDisallowed(t *testing.T) {
+	if err := CheckDisallowed("bcrypt", "cost", 11, 4, 10); err != nil {
+		t.Errorf("CheckDisallowed() error = %v", err)
+	}
+	if err := CheckDisallowed("bcrypt", "cost", 11); err != nil {
+		t.Errorf("CheckDisallowed() error = %v", err)
+	}
+
+	err := CheckDisallowed("bcrypt", "cost", 10, 4, 10)
+	var target *DisallowedError
+	if !errors.As(err, &target) {
+		t.Fatalf("CheckDisallowed() error = %v, want DisallowedError", err)
+	}
+	if want := "bcrypt: parameter cost=10 is disallowed"; err.Error() != want {
+		t.Errorf("CheckDisallowed() error = %q, want %q", err, want)
+	}
+}
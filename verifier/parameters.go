@@ -0,0 +1,48 @@
+package verifier
+
+import "math"
+
+// Parameters of a parsed encoded hash.
+type Parameters interface {
+	// Strength estimates the work of a single verification,
+	// as log2 of the number of hash compression function calls
+	// or equivalent operations. It allows to rank parameters
+	// across algorithms and is only meaningful for comparison.
+	//
+	// Strength is calibrated so that the OWASP minimum
+	// recommendations for argon2id, scrypt, bcrypt and pbkdf2
+	// each have a Strength of about 20.
+	Strength() int
+
+	// Describe returns the algorithm identifier
+	// and cost parameters, like [Identifier].
+	Describe() map[string]any
+}
+
+// Parser is an optional interface for Verifiers
+// which can parse the parameters of an encoded hash,
+// without verifying a password.
+//
+// Parse returns nil Parameters and a nil error when
+// encoded is not in a format handled by the Parser.
+type Parser interface {
+	Parse(encoded string) (Parameters, error)
+}
+
+// Configured is an optional interface for Hashers
+// which report the Parameters of the hashes they create,
+// without hashing a password.
+// The Parameters match the result of Parse for a new hash,
+// except for its creation time.
+type Configured interface {
+	HashParameters() Parameters
+}
+
+// WorkStrength returns the Strength of work
+// hash compression function calls.
+func WorkStrength(work float64) int {
+	if work < 1 {
+		return 0
+	}
+	return int(math.Round(math.Log2(work)))
+}
@@ -0,0 +1,21 @@
+package verifier
+
+import "testing"
+
+func TestWorkStrength(t *testing.T) {
+	tests := []struct {
+		work float64
+		want int
+	}{
+		{0, 0},
+		{0.5, 0},
+		{1, 0},
+		{1 << 20, 20},
+		{1.2e6, 20},
+	}
+	for _, tt := range tests {
+		if got := WorkStrength(tt.work); got != tt.want {
+			t.Errorf("WorkStrength(%v) = %d, want %d", tt.work, got, tt.want)
+		}
+	}
+}
@@ -40,8 +40,71 @@ type Verifier interface {
 	// Verify the hashed password against the encoded hash.
 	Verify(encoded, password string) (Result, error)
 }
+
+// Identifier is an optional interface for Verifiers
+// which can describe the parameters of an encoded hash,
+// without verifying a password.
+//
+// Identify returns the algorithm identifier and cost parameters
+// of encoded. Nil params and a nil error are returned when
+// encoded is not in a format handled by the Identifier.
+type Identifier interface {
+	Identify(encoded string) (params map[string]any, err error)
+}
+
+// Validator is an optional interface for Verifiers
+// which can check the parameters of an encoded hash
+// against configured bounds, without verifying a password.
+//
+// Validate returns an error when the parameters of encoded
+// are out of bounds or encoded can't be parsed.
+type Validator interface {
+	Validate(encoded string) error
+}
+
+// Named is an optional interface for Verifiers
+// which report the name of the algorithm they verify,
+// such as "argon2" or "bcrypt".
+type Named interface {
+	Algorithm() string
+}
+
+// Prefixed is an optional interface for Verifiers
+// which only recognize encoded hashes that start with
+// one of the returned prefixes, such as "$argon2".
+// A Swapper does not call Verify of such a Verifier
+// for encoded strings with other prefixes.
+// An empty list means any encoded string may be recognized.
+//
+// Verifiers must only implement Prefixed when Verify returns
+// Skip without error for all encoded strings which
+// don't start with one of the prefixes.
+type Prefixed interface {
+	HashPrefixes() []string
+}
+
 type VerifyFunc func(encoded, password string) (Result, error)
 
 func (v VerifyFunc) Verify(encoded, password string) (Result, error) {
 	return v(encoded, password)
 }
+
+// NamedFunc is a VerifyFunc which implements Named
+// and Prefixed.
+type NamedFunc struct {
+	Name string
+	VerifyFunc
+
+	// Prefixes recognized by VerifyFunc, see Prefixed.
+	Prefixes []string
+}
+
+// Algorithm implements Named.
+func (f *NamedFunc) Algorithm() string {
+	return f.Name
+}
+
+// HashPrefixes implements Prefixed.
+func (f *NamedFunc) HashPrefixes() []string {
+	return f.Prefixes
+}
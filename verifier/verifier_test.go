@@ -18,3 +18,17 @@ func TestVerifyFunc_Verify(t *testing.T) {
 		t.Errorf("VerifyFunc = %s, want %s", result, verifier.OK)
 	}
 }
+
+func TestNamedFunc(t *testing.T) {
+	var v verifier.Verifier = &verifier.NamedFunc{Name: "argon2", VerifyFunc: argon2.Verify}
+	result, err := v.Verify(tv.Argon2idEncoded, tv.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != verifier.OK {
+		t.Errorf("NamedFunc = %s, want %s", result, verifier.OK)
+	}
+	if got := v.(verifier.Named).Algorithm(); got != "argon2" {
+		t.Errorf("NamedFunc.Algorithm() = %s, want %s", got, "argon2")
+	}
+}
@@ -0,0 +1,73 @@
+// Package wordpress provides verification of password hashes
+// created by WordPress 6.8 and later.
+//
+// WordPress stores `$wp$2y$...`, which is bcrypt of the
+// base64 encoded HMAC-SHA384 of the password, keyed with
+// the fixed string `wp-sha384`, prefixed by `$wp`.
+// The prehash allows passwords longer than the 72 bytes of bcrypt.
+//
+// Hashes of older WordPress versions, using the `$P$` phpass
+// format, are not supported and result in Skip.
+// Plain bcrypt hashes, as created by some plugins,
+// can be verified with the bcrypt package.
+package wordpress
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/base64"
+	"strings"
+
+	"github.com/zitadel/passwap/bcrypt"
+	"github.com/zitadel/passwap/verifier"
+)
+
+const (
+	// Prefix of WordPress hashes, in front of the bcrypt hash.
+	Prefix = "$wp"
+
+	// Key of the HMAC-SHA384 prehash.
+	Key = "wp-sha384"
+)
+
+// Algorithm is the name reported by the Verifier,
+// see verifier.Named.
+const Algorithm = "wordpress"
+
+// prehash returns the base64 encoded
+// HMAC-SHA384 of password, keyed with Key.
+func prehash(password string) string {
+	mac := hmac.New(sha512.New384, []byte(Key))
+	mac.Write([]byte(password))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify parses encoded and verifies password against the
+// wrapped bcrypt hash. Skip is returned for encoded strings
+// without the `$wp$` prefix.
+func Verify(encoded, password string) (verifier.Result, error) {
+	wrapped, ok := strings.CutPrefix(encoded, Prefix)
+	if !ok || !strings.HasPrefix(wrapped, bcrypt.Prefix) {
+		return verifier.Skip, nil
+	}
+	return bcrypt.Verify(wrapped, prehash(password))
+}
+
+// prefixes recognized by the Verifier.
+var prefixes = []string{Prefix + bcrypt.Prefix}
+
+// Verifier for WordPress hashes.
+var Verifier = &verifier.NamedFunc{Name: Algorithm, VerifyFunc: Verify, Prefixes: prefixes}
+
+// Prefixes returns the prefixes of the hashes handled by this package,
+// as recognized by the Verifier, see verifier.Prefixed.
+func Prefixes() []string {
+	return append([]string(nil), prefixes...)
+}
+
+// SelfTestVector returns a fixed password and its encoded hash,
+// which [Verify] must accept. It guards against regressions
+// of the encoding format, see passwap.SelfTest.
+func SelfTestVector() (password, encoded string) {
+	return "password", `$wp$2y$04$zu9wNJ9.gwEaBprCfBa6vemvLJgnH5v.Hlcbh4B4SzRctFSCtvFCq`
+}
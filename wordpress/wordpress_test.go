@@ -0,0 +1,80 @@
+package wordpress
+
+import (
+	"testing"
+
+	tv "github.com/zitadel/passwap/internal/testvalues"
+	"github.com/zitadel/passwap/verifier"
+)
+
+func Test_prehash(t *testing.T) {
+	const want = `ZEIBxmCIjkyF8MNbMmsrJOPmp2/ioyAvd90BaIOY1lr6z3gfcWZusfjOv0kWasvS`
+	if got := prehash(tv.Password); got != want {
+		t.Errorf("prehash() = %s, want %s", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoded  string
+		password string
+		want     verifier.Result
+		wantErr  bool
+	}{
+		{
+			name:     "success",
+			encoded:  tv.WordPressEncoded,
+			password: tv.Password,
+			want:     verifier.OK,
+		},
+		{
+			name:     "wrong password",
+			encoded:  tv.WordPressEncoded,
+			password: "foobar",
+			want:     verifier.Fail,
+		},
+		{
+			name:     "without prehash",
+			encoded:  Prefix + tv.EncodedBcrypt2b,
+			password: tv.Password,
+			want:     verifier.Fail,
+		},
+		{
+			name:     "unwrapped bcrypt",
+			encoded:  tv.EncodedBcrypt2b,
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "phpass",
+			encoded:  `$P$B55D6LjfHDkINU5wF.v2BuuzO0/XPk/`,
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "wrapped argon2",
+			encoded:  Prefix + tv.Argon2idEncoded,
+			password: tv.Password,
+			want:     verifier.Skip,
+		},
+		{
+			name:     "bcrypt parse error",
+			encoded:  Prefix + "$2y$foo",
+			password: tv.Password,
+			want:     verifier.Skip,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Verify(tt.encoded, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}